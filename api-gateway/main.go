@@ -20,13 +20,22 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Initialize JWT manager for auth middleware
-	jwtManager := jwt.NewManager(
-		cfg.JWTSecret,
-		cfg.JWTAccessExpiry,
-		cfg.JWTRefreshExpiry,
-	)
+	jwtManager, err := jwt.New(jwt.Config{
+		Secret:            cfg.JWTSecret,
+		Keys:              cfg.JWTKeys,
+		ActiveKeyID:       cfg.JWTActiveKeyID,
+		AcceptLegacyHS256: cfg.JWTAcceptHS256,
+		AccessExpiry:      cfg.JWTAccessExpiry,
+		RefreshExpiry:     cfg.JWTRefreshExpiry,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize JWT manager: %v", err)
+	}
 
 	// Initialize service proxy
 	serviceProxy := proxy.NewServiceProxy(
@@ -36,8 +45,9 @@ func main() {
 		cfg.AIServiceURL,
 	)
 
-	// Initialize rate limiter
+	// Initialize rate limiters (stricter per-IP limit on /auth against credential stuffing)
 	rateLimiter := middleware.NewRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	authRateLimiter := middleware.NewRateLimiter(cfg.AuthRateLimitRequests, cfg.AuthRateLimitWindow)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
@@ -59,11 +69,15 @@ func main() {
 		})
 	})
 
+	// Token verification keys (public)
+	app.Get("/.well-known/jwks.json", serviceProxy.ProxyToAuth)
+
 	// API routes
 	api := app.Group("/api/v1")
 
 	// Auth routes (public)
 	auth := api.Group("/auth")
+	auth.Use(authRateLimiter.RateLimitMiddleware())
 	auth.All("/*", serviceProxy.ProxyToAuth)
 
 	// User routes (protected)
@@ -78,6 +92,11 @@ func main() {
 	friends.Use(rateLimiter.RateLimitMiddleware())
 	friends.All("/*", serviceProxy.ProxyToAuth)
 
+	// Zodiac routes (public)
+	zodiac := api.Group("/zodiac")
+	zodiac.Use(rateLimiter.RateLimitMiddleware())
+	zodiac.All("/*", serviceProxy.ProxyToAuth)
+
 	// Chat routes (protected)
 	chat := api.Group("/chat")
 	chat.Use(middleware.AuthMiddleware(jwtManager))
@@ -90,6 +109,12 @@ func main() {
 	rooms.Use(rateLimiter.RateLimitMiddleware())
 	rooms.All("/*", serviceProxy.ProxyToChat)
 
+	// Direct message routes (protected)
+	conversations := api.Group("/conversations")
+	conversations.Use(middleware.AuthMiddleware(jwtManager))
+	conversations.Use(rateLimiter.RateLimitMiddleware())
+	conversations.All("/*", serviceProxy.ProxyToChat)
+
 	// Post routes (mixed: public read, protected write)
 	posts := api.Group("/posts")
 	
@@ -107,6 +132,17 @@ func main() {
 	postsProtected.Delete("/:id/like", serviceProxy.ProxyToSocial)
 	postsProtected.Post("/:id/comments", serviceProxy.ProxyToSocial)
 
+	// Admin routes (admins and moderators; each service checks roles again)
+	admin := api.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(jwtManager))
+	admin.Use(middleware.RequireRole(jwt.RoleAdmin, jwt.RoleModerator))
+	admin.Use(rateLimiter.RateLimitMiddleware())
+	admin.All("/users", serviceProxy.ProxyToAuth)
+	admin.All("/users/*", serviceProxy.ProxyToAuth)
+	admin.All("/posts/*", serviceProxy.ProxyToSocial)
+	admin.All("/comments/*", serviceProxy.ProxyToSocial)
+	admin.All("/rooms/*", serviceProxy.ProxyToChat)
+
 	// AI routes (internal - no rate limit for service-to-service)
 	ai := api.Group("/ai")
 	ai.All("/*", serviceProxy.ProxyToAI)
@@ -1,11 +1,14 @@
 package proxy
 
 import (
+	"bufio"
 	"io"
 	"net/http"
 	"strings"
 	"time"
 
+	"zodiac-ai-backend/pkg/sse"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -16,6 +19,7 @@ type ServiceProxy struct {
 	socialServiceURL string
 	aiServiceURL     string
 	client           *http.Client
+	streamClient     *http.Client // No overall timeout, see ProxyRequest
 }
 
 // NewServiceProxy creates a new service proxy
@@ -28,9 +32,23 @@ func NewServiceProxy(authURL, chatURL, socialURL, aiURL string) *ServiceProxy {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		streamClient: newStreamClient(),
 	}
 }
 
+// newStreamClient creates the HTTP client for streaming requests
+// Only waiting for the response headers is timed; the body may stream for longer
+func newStreamClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	return &http.Client{Transport: transport}
+}
+
+// isStreamRequest reports whether the client expects a streamed (SSE) response
+func isStreamRequest(c *fiber.Ctx) bool {
+	return strings.HasSuffix(c.Path(), "/stream") || strings.Contains(c.Get("Accept"), "text/event-stream")
+}
+
 // ProxyRequest proxies a request to the target service
 func (p *ServiceProxy) ProxyRequest(c *fiber.Ctx, targetURL string) error {
 	// Build target URL
@@ -61,15 +79,26 @@ func (p *ServiceProxy) ProxyRequest(c *fiber.Ctx, targetURL string) error {
 		req.Header.Set(string(key), string(value))
 	})
 
+	// Pass the client address on (e.g. session IP addresses in the auth service)
+	forwardedFor := c.IP()
+	if prior := c.Get(fiber.HeaderXForwardedFor); prior != "" {
+		forwardedFor = prior + ", " + forwardedFor
+	}
+	req.Header.Set(fiber.HeaderXForwardedFor, forwardedFor)
+
 	// Execute request
-	resp, err := p.client.Do(req)
+	client := p.client
+	if isStreamRequest(c) {
+		client = p.streamClient
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
 			"success": false,
 			"message": "Service temporarily unavailable",
 		})
 	}
-	defer resp.Body.Close()
 
 	// Copy response headers
 	for key, values := range resp.Header {
@@ -78,6 +107,33 @@ func (p *ServiceProxy) ProxyRequest(c *fiber.Ctx, targetURL string) error {
 		}
 	}
 
+	// Pass event streams through chunk by chunk instead of buffering them
+	if sse.IsEventStream(resp.Header.Get("Content-Type")) {
+		c.Status(resp.StatusCode)
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			// Closing the upstream body on client disconnect cancels the stream at the service
+			defer resp.Body.Close()
+
+			buf := make([]byte, 4096)
+			for {
+				n, readErr := resp.Body.Read(buf)
+				if n > 0 {
+					if _, err := w.Write(buf[:n]); err != nil {
+						return
+					}
+					if err := w.Flush(); err != nil {
+						return
+					}
+				}
+				if readErr != nil {
+					return
+				}
+			}
+		})
+		return nil
+	}
+	defer resp.Body.Close()
+
 	// Copy response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
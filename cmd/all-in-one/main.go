@@ -12,7 +12,9 @@ import (
 	"zodiac-ai-backend/pkg/config"
 	"zodiac-ai-backend/pkg/database"
 	"zodiac-ai-backend/pkg/jwt"
+	"zodiac-ai-backend/pkg/mailer"
 	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/oidc"
 	"zodiac-ai-backend/pkg/queue"
 
 	// Auth
@@ -42,6 +44,9 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Determine port (needed for internal service communication)
 	port := os.Getenv("PORT")
@@ -62,32 +67,128 @@ func main() {
 	db := database.GetDatabase(cfg.MongoDatabase)
 
 	// Initialize JWT manager
-	jwtManager := jwt.NewManager(
-		cfg.JWTSecret,
-		cfg.JWTAccessExpiry,
-		cfg.JWTRefreshExpiry,
-	)
+	jwtManager, err := jwt.New(jwt.Config{
+		Secret:            cfg.JWTSecret,
+		Keys:              cfg.JWTKeys,
+		ActiveKeyID:       cfg.JWTActiveKeyID,
+		AcceptLegacyHS256: cfg.JWTAcceptHS256,
+		AccessExpiry:      cfg.JWTAccessExpiry,
+		RefreshExpiry:     cfg.JWTRefreshExpiry,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize JWT manager: %v", err)
+	}
+	if !jwtManager.CanSign() {
+		log.Fatalf("JWT manager can't sign tokens: the active key in JWT_KEYS needs its private key")
+	}
 
-	// Initialize rate limiter
+	// Initialize rate limiters (stricter per-IP limit on /auth against credential stuffing)
 	rateLimiter := middleware.NewRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	authRateLimiter := middleware.NewRateLimiter(cfg.AuthRateLimitRequests, cfg.AuthRateLimitWindow)
 
 	// ========== AUTH SERVICE ==========
 	userRepo := authRepos.NewUserRepository(db)
 	refreshTokenRepo := authRepos.NewRefreshTokenRepository(db)
 	friendshipRepo := authRepos.NewFriendshipRepository(db)
+	blockRepo := authRepos.NewBlockRepository(db)
+	userTokenRepo := authRepos.NewUserTokenRepository(db)
+	loginAttemptRepo := authRepos.NewLoginAttemptRepository(db)
+	auditLogRepo := authRepos.NewAuditLogRepository(db)
+	oidcStateRepo := authRepos.NewOIDCStateRepository(db)
+	accountDeletionRepo := authRepos.NewAccountDeletionRepository(db)
+	userDataRepo := authRepos.NewUserDataRepository(db)
+
+	// Access tokens of suspended users and logged out sessions are rejected (cached briefly)
+	suspensionCache := middleware.NewSuspensionCache(userRepo, cfg.SessionCacheTTL)
+	sessionCache := middleware.NewRevocationCache(refreshTokenRepo, cfg.SessionCacheTTL)
+	authMiddleware := middleware.AuthMiddleware(jwtManager, suspensionCache.Validate, sessionCache.Validate)
+	optionalAuth := middleware.OptionalAuthMiddleware(jwtManager, suspensionCache.Validate, sessionCache.Validate)
+
+	loginGuard := authServices.NewLoginGuard(loginAttemptRepo, auditLogRepo, authServices.LockoutConfig{
+		MaxAttempts:   cfg.LoginMaxAttempts,
+		IPMaxAttempts: cfg.LoginIPMaxAttempts,
+		Window:        cfg.LoginAttemptWindow,
+		BaseLockout:   cfg.LoginLockoutBase,
+		MaxLockout:    cfg.LoginLockoutMax,
+	})
+	authService := authServices.NewAuthService(userRepo, refreshTokenRepo, jwtManager, loginGuard)
+	mfaService := authServices.NewMFAService(userRepo, loginGuard, cfg.MFAIssuer)
+
+	// Social login providers (OIDC_PROVIDERS)
+	oidcProviders := make([]*oidc.Provider, 0, len(cfg.OIDCProviders))
+	for _, providerCfg := range cfg.OIDCProviders {
+		provider, err := oidc.NewProvider(oidc.Config{
+			Name:         providerCfg.Name,
+			Issuer:       providerCfg.Issuer,
+			ClientID:     providerCfg.ClientID,
+			ClientSecret: providerCfg.ClientSecret,
+			RedirectURL:  providerCfg.RedirectURL,
+			Scopes:       providerCfg.Scopes,
+			ResponseMode: providerCfg.ResponseMode,
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize login provider: %v", err)
+		}
+		oidcProviders = append(oidcProviders, provider)
+	}
+	oidcService := authServices.NewOIDCService(oidcProviders, oidcStateRepo, userRepo, authService)
+	// Friendship and block changes invalidate the suggestions of the users involved
+	suggestionCache := authServices.NewSuggestionCache(cfg.SuggestionCacheTTL)
+	friendshipService := authServices.NewFriendshipService(friendshipRepo, userRepo, blockRepo, suggestionCache)
+	suggestionService := authServices.NewSuggestionService(friendshipRepo, userRepo, blockRepo, suggestionCache, authServices.SuggestionWeights{
+		MutualFriends: cfg.SuggestionMutualWeight,
+		Zodiac:        cfg.SuggestionZodiacWeight,
+	})
 
-	authService := authServices.NewAuthService(userRepo, refreshTokenRepo, jwtManager)
-	friendshipService := authServices.NewFriendshipService(friendshipRepo, userRepo)
-
-	authHandler := authHandlers.NewAuthHandler(authService)
+	mail, err := mailer.New(mailer.Config{
+		Backend:  cfg.Mailer,
+		From:     cfg.MailFrom,
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		Dir:      cfg.MailDir,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+	accountService := authServices.NewAccountService(userRepo, userTokenRepo, refreshTokenRepo, mail, cfg.AppURL)
+
+	// Account deletions run in the background and resume after a restart
+	deletionWorker := authServices.NewDeletionWorker(accountDeletionRepo, userDataRepo, cfg.AccountDeletionPollInterval)
+	deletionWorker.Start()
+	defer deletionWorker.Stop()
+	privacyService := authServices.NewPrivacyService(userRepo, refreshTokenRepo, userDataRepo, accountDeletionRepo, loginGuard, deletionWorker)
+	adminService := authServices.NewAdminService(userRepo, refreshTokenRepo, auditLogRepo)
+	profileService := authServices.NewProfileService(userRepo, friendshipRepo)
+	blockService := authServices.NewBlockService(blockRepo, friendshipRepo, userRepo, suggestionCache)
+
+	authHandler := authHandlers.NewAuthHandler(authService, accountService)
+	accountHandler := authHandlers.NewAccountHandler(accountService)
+	mfaHandler := authHandlers.NewMFAHandler(authService, mfaService)
+	oidcHandler := authHandlers.NewOIDCHandler(oidcService)
+	privacyHandler := authHandlers.NewPrivacyHandler(privacyService)
 	friendHandler := authHandlers.NewFriendHandler(friendshipService)
+	suggestionHandler := authHandlers.NewSuggestionHandler(suggestionService)
+	zodiacHandler := authHandlers.NewZodiacHandler()
+	adminHandler := authHandlers.NewAdminHandler(adminService)
+	profileHandler := authHandlers.NewProfileHandler(profileService)
+	blockHandler := authHandlers.NewBlockHandler(blockService)
 
 	// ========== AI SERVICE ==========
-	geminiClient, err := client.NewGeminiClient(cfg.GeminiAPIKey)
+	provider, err := client.NewProvider(client.ProviderConfig{
+		Provider:   cfg.LLMProvider,
+		Model:      cfg.LLMModel,
+		BaseURL:    cfg.LLMBaseURL,
+		APIKey:     cfg.LLMAPIKey,
+		FakeScript: cfg.LLMFakeScript,
+	})
 	if err != nil {
-		log.Printf("Warning: Failed to initialize Gemini client: %v", err)
+		log.Fatalf("Failed to initialize LLM provider: %v", err)
 	}
-	defer geminiClient.Close()
+
+	aiClient := client.NewAIClient(provider)
+	defer aiClient.Close()
 
 	// Initialize request queue for AI service
 	aiRequestQueue := queue.NewRequestQueue(queue.Config{
@@ -95,12 +196,10 @@ func main() {
 		Workers:   10,   // 10 concurrent workers
 		Processor: func(ctx context.Context, data interface{}) (interface{}, error) {
 			// Extract request data
-			reqData := data.(map[string]interface{})
-			zodiacSign := reqData["zodiac_sign"].(string)
-			userMessage := reqData["user_message"].(string)
+			chatReq := data.(*client.ChatRequest)
 
 			// Generate AI response
-			response, err := geminiClient.GenerateChatResponse(ctx, zodiacSign, userMessage)
+			response, err := aiClient.GenerateChatResponse(ctx, chatReq)
 			return response, err
 		},
 	})
@@ -114,12 +213,13 @@ func main() {
 		}
 	}()
 
-	aiHandler := aiHandlers.NewAIHandler(geminiClient, aiRequestQueue)
+	aiHandler := aiHandlers.NewAIHandler(aiClient, aiRequestQueue)
 
 	// ========== CHAT SERVICE ==========
 	sessionRepo := chatRepos.NewChatSessionRepository(db)
 	messageRepo := chatRepos.NewMessageRepository(db)
 	roomRepo := chatRepos.NewRoomRepository(db)
+	roomMemberRepo := chatRepos.NewRoomMemberRepository(db)
 
 	// AI service URL is internal (same app) - use direct handler call instead of HTTP
 	// For simplicity, we'll keep HTTP but use localhost with the correct port
@@ -127,28 +227,62 @@ func main() {
 	if aiServiceURL == "" {
 		aiServiceURL = "http://localhost:" + port
 	}
-	chatService := chatServices.NewChatService(sessionRepo, messageRepo, aiServiceURL)
+	chatService := chatServices.NewChatService(sessionRepo, messageRepo, aiServiceURL, chatServices.ContextConfig{
+		TokenBudget: cfg.AIHistoryTokenBudget,
+		MaxMessages: cfg.AIHistoryMaxMessages,
+		Summarize:   cfg.AIHistorySummary,
+	})
 
 	chatHandler := chatHandlers.NewChatHandler(chatService)
 
 	// WebSocket Hub
-	hub := websocket.NewHub()
+	var backplane websocket.Backplane = websocket.NewMemoryBackplane()
+	if cfg.WSBackplane == "mongo" {
+		backplane = websocket.NewMongoBackplane(db)
+	}
+	defer backplane.Close()
+
+	hub := websocket.NewHub(roomRepo, backplane, websocket.Config{
+		PingInterval:   cfg.WSPingInterval,
+		PongWait:       cfg.WSPongWait,
+		WriteWait:      cfg.WSWriteWait,
+		MaxMessageSize: int64(cfg.WSMaxMessageSize),
+		SendBufferSize: cfg.WSSendBufferSize,
+	})
 	go hub.Run()
 
-	roomHandler := chatHandlers.NewRoomHandler(roomRepo, hub)
+	// Connected users who get suspended are disconnected within one session cache TTL
+	go hub.WatchSuspensions(userRepo.SuspendedAmong, cfg.SessionCacheTTL)
+
+	// Blocks made while users are connected apply to room frames within one session cache TTL
+	go hub.WatchBlocks(blockRepo.HiddenAmong, cfg.SessionCacheTTL)
+
+	roomService := chatServices.NewRoomService(roomRepo, roomMemberRepo, userRepo)
+	roomHandler := chatHandlers.NewRoomHandler(roomService, roomRepo, userRepo, blockRepo, hub)
+	roomModerationService := chatServices.NewModerationService(roomService, auditLogRepo)
+	roomModerationHandler := chatHandlers.NewModerationHandler(roomModerationService, hub)
+
+	conversationRepo := chatRepos.NewConversationRepository(db)
+	dmService := chatServices.NewDirectMessageService(conversationRepo, friendshipRepo, userRepo)
+	dmHandler := chatHandlers.NewDirectMessageHandler(dmService, userRepo, hub)
 
 	// ========== SOCIAL SERVICE ==========
 	postRepo := socialRepos.NewPostRepository(db)
 	commentRepo := socialRepos.NewCommentRepository(db)
 
-	socialService := socialServices.NewSocialService(postRepo, commentRepo)
+	socialService := socialServices.NewSocialService(postRepo, commentRepo, blockRepo)
+	postModerationService := socialServices.NewModerationService(postRepo, commentRepo, auditLogRepo)
 
 	socialHandler := socialHandlers.NewSocialHandler(socialService)
+	postModerationHandler := socialHandlers.NewModerationHandler(postModerationService)
 
 	// ========== FIBER APP ==========
 	app := fiber.New(fiber.Config{
 		AppName:      "Zodiac AI - All-in-One",
 		ErrorHandler: customErrorHandler,
+		// X-Forwarded-For is only believed from these (see clientInfo in the auth handlers)
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxies,
 	})
 
 	// Global middleware
@@ -157,6 +291,9 @@ func main() {
 	app.Use(middleware.SetupCORS())
 	app.Use(middleware.RequestID())
 
+	// Token verification keys (public)
+	app.Get("/.well-known/jwks.json", authHandler.JWKS)
+
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -170,9 +307,10 @@ func main() {
 		}
 
 		return c.JSON(fiber.Map{
-			"status":   "healthy",
-			"service":  "zodiac-ai-all-in-one",
-			"ai_queue": aiRequestQueue.Stats(),
+			"status":    "healthy",
+			"service":   "zodiac-ai-all-in-one",
+			"ai_queue":  aiRequestQueue.Stats(),
+			"websocket": hub.Stats(),
 		})
 	})
 
@@ -181,70 +319,153 @@ func main() {
 
 	// ========== AUTH ROUTES ==========
 	auth := api.Group("/auth")
+	auth.Use(authRateLimiter.RateLimitMiddleware())
 	auth.Post("/register", authHandler.Register)
 	auth.Post("/login", authHandler.Login)
 	auth.Post("/refresh", authHandler.RefreshToken)
+	auth.Post("/logout", authHandler.Logout)
+	auth.Post("/logout-all", authMiddleware, authHandler.LogoutAll)
+	auth.Post("/forgot-password", accountHandler.ForgotPassword)
+	auth.Post("/reset-password", accountHandler.ResetPassword)
+	auth.Post("/verify-email", accountHandler.VerifyEmail)
+	auth.Post("/resend-verification", authMiddleware, accountHandler.ResendVerification)
+	auth.Post("/mfa/enroll", authMiddleware, mfaHandler.Enroll)
+	auth.Post("/mfa/confirm", authMiddleware, mfaHandler.Confirm)
+	auth.Post("/mfa/verify", mfaHandler.Verify)
+	auth.Post("/mfa/disable", authMiddleware, mfaHandler.Disable)
+	auth.Get("/oidc/providers", oidcHandler.GetProviders)
+	auth.Get("/oidc/:provider/authorize", oidcHandler.Authorize)
+	auth.Post("/oidc/:provider/callback", oidcHandler.Callback)
+	auth.Get("/oidc/:provider/link", authMiddleware, oidcHandler.StartLink)
+	auth.Post("/oidc/:provider/link", authMiddleware, oidcHandler.Link)
 
 	// User routes (protected)
 	users := api.Group("/users")
-	users.Use(middleware.AuthMiddleware(jwtManager))
+	users.Use(authMiddleware)
 	users.Use(rateLimiter.RateLimitMiddleware())
 	users.Get("/me", authHandler.GetProfile)
 	users.Put("/me", authHandler.UpdateProfile)
+	users.Delete("/me", privacyHandler.DeleteAccount)
+	users.Get("/me/export", privacyHandler.Export)
+	users.Post("/me/complete-profile", authHandler.CompleteProfile)
+	users.Get("/me/sessions", authHandler.GetSessions)
+	users.Delete("/me/sessions/:id", authHandler.RevokeSession)
+	users.Put("/me/privacy", profileHandler.UpdatePrivacy)
+	users.Get("/me/blocks", blockHandler.GetBlocked)
+	users.Get("/search", profileHandler.Search)
+	users.Post("/batch", profileHandler.Batch)
+	users.Get("/:id", profileHandler.GetProfile) // After the /me, /search and /batch routes
+	users.Post("/:id/block", blockHandler.Block)
+	users.Delete("/:id/block", blockHandler.Unblock)
 
 	// Friend routes (protected)
 	friends := api.Group("/friends")
-	friends.Use(middleware.AuthMiddleware(jwtManager))
+	friends.Use(authMiddleware)
 	friends.Use(rateLimiter.RateLimitMiddleware())
 	friends.Post("/requests", friendHandler.SendFriendRequest)
+	friends.Get("/requests", friendHandler.GetFriendRequests)
 	friends.Put("/requests/:id", friendHandler.AcceptRejectRequest)
+	friends.Delete("/requests/:id", friendHandler.CancelFriendRequest)
 	friends.Get("", friendHandler.GetFriends)
+	friends.Get("/suggestions", suggestionHandler.GetSuggestions)
 	friends.Get("/status/:user_id", friendHandler.CheckFriendshipStatus)
+	friends.Get("/:id/compatibility", friendHandler.GetCompatibility)
+	friends.Delete("/:user_id", friendHandler.Unfriend)
+
+	// Zodiac routes (public)
+	zodiac := api.Group("/zodiac")
+	zodiac.Use(rateLimiter.RateLimitMiddleware())
+	zodiac.Get("/compatibility", zodiacHandler.GetCompatibility)
 
 	// ========== CHAT ROUTES ==========
 	chat := api.Group("/chat")
-	chat.Use(middleware.AuthMiddleware(jwtManager))
+	chat.Use(authMiddleware)
 	chat.Use(rateLimiter.RateLimitMiddleware())
 	chat.Post("/sessions", chatHandler.CreateSession)
 	chat.Get("/sessions", chatHandler.GetSessions)
 	chat.Post("/sessions/:id/messages", chatHandler.SendMessage)
+	chat.Post("/sessions/:id/messages/stream", chatHandler.StreamMessage)
 	chat.Get("/sessions/:id/messages", chatHandler.GetMessages)
 	chat.Post("/sessions/:id/generate-insight", chatHandler.GenerateInsight)
 
 	// ========== ROOM ROUTES ==========
+	// WebSocket route (registered before the rooms group so its auth middleware doesn't
+	// answer with HTTP errors; AuthorizeJoin reports failures as typed close frames)
+	app.Get("/api/v1/rooms/:id/ws", roomHandler.AuthorizeJoin(jwtManager, suspensionCache.Validate, sessionCache.Validate), ws.New(func(c *ws.Conn) {
+		roomHandler.JoinRoom(c)
+	}))
+
 	rooms := api.Group("/rooms")
-	rooms.Use(middleware.AuthMiddleware(jwtManager))
+	rooms.Use(authMiddleware)
 	rooms.Use(rateLimiter.RateLimitMiddleware())
 	rooms.Post("", roomHandler.CreateRoom)
 	rooms.Get("", roomHandler.GetRooms)
+	rooms.Get("/:id/messages", roomHandler.GetMessages)
 	rooms.Delete("/:id", roomHandler.DeleteRoom)
-
-	// WebSocket route
-	app.Get("/api/v1/rooms/:id/ws", ws.New(func(c *ws.Conn) {
-		roomHandler.JoinRoom(c)
+	rooms.Post("/:id/join", roomHandler.Join)
+	rooms.Post("/:id/leave", roomHandler.Leave)
+	rooms.Get("/:id/members", roomHandler.GetMembers)
+	rooms.Post("/:id/members/:userId/kick", roomHandler.KickMember)
+	rooms.Post("/:id/members/:userId/ban", roomHandler.BanMember)
+	rooms.Delete("/:id/members/:userId/ban", roomHandler.UnbanMember)
+	rooms.Post("/:id/members/:userId/mute", roomHandler.MuteMember)
+	rooms.Delete("/:id/members/:userId/mute", roomHandler.UnmuteMember)
+	rooms.Put("/:id/members/:userId/role", roomHandler.UpdateMemberRole)
+
+	// ========== DIRECT MESSAGE ROUTES ==========
+	// Receive-only inbox socket (registered before the group for the same reason as room sockets)
+	app.Get("/api/v1/conversations/ws", dmHandler.AuthorizeInbox(jwtManager, suspensionCache.Validate, sessionCache.Validate), ws.New(func(c *ws.Conn) {
+		dmHandler.Inbox(c)
 	}))
 
+	conversations := api.Group("/conversations")
+	conversations.Use(authMiddleware)
+	conversations.Use(rateLimiter.RateLimitMiddleware())
+	conversations.Post("", dmHandler.StartConversation)
+	conversations.Get("", dmHandler.GetConversations)
+	conversations.Get("/:id/messages", dmHandler.GetMessages)
+	conversations.Post("/:id/messages", dmHandler.SendMessage)
+	conversations.Post("/:id/read", dmHandler.MarkRead)
+
 	// ========== SOCIAL ROUTES ==========
 	posts := api.Group("/posts")
 
-	// Public routes
-	posts.Get("", socialHandler.GetFeed)
+	// Public routes (signed-in viewers don't see blocked users)
+	posts.Get("", optionalAuth, socialHandler.GetFeed)
 	posts.Get("/:id", socialHandler.GetPost)
-	posts.Get("/:id/comments", socialHandler.GetComments)
+	posts.Get("/:id/comments", optionalAuth, socialHandler.GetComments)
 
 	// Protected routes
 	postsProtected := posts.Group("")
-	postsProtected.Use(middleware.AuthMiddleware(jwtManager))
+	postsProtected.Use(authMiddleware)
 	postsProtected.Use(rateLimiter.RateLimitMiddleware())
 	postsProtected.Post("", socialHandler.PublishPost)
 	postsProtected.Post("/:id/like", socialHandler.LikePost)
 	postsProtected.Delete("/:id/like", socialHandler.UnlikePost)
 	postsProtected.Post("/:id/comments", socialHandler.AddComment)
 
+	// ========== ADMIN ROUTES ==========
+	admin := api.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRole(jwt.RoleAdmin, jwt.RoleModerator))
+	admin.Use(rateLimiter.RateLimitMiddleware())
+	admin.Get("/users", adminHandler.ListUsers)
+	admin.Get("/users/:id", adminHandler.GetUser)
+	admin.Post("/users/:id/suspend", adminHandler.Suspend)
+	admin.Post("/users/:id/unsuspend", adminHandler.Unsuspend)
+	admin.Post("/users/:id/logout", adminHandler.ForceLogout)
+	admin.Put("/users/:id/roles", middleware.RequireRole(jwt.RoleAdmin), adminHandler.SetRoles)
+	admin.Delete("/posts/:id", postModerationHandler.DeletePost)
+	admin.Delete("/comments/:id", postModerationHandler.DeleteComment)
+	admin.Delete("/rooms/:id", roomModerationHandler.DeleteRoom)
+
 	// ========== AI ROUTES (Internal) ==========
 	ai := api.Group("/ai")
 	ai.Post("/chat", aiHandler.GenerateChatResponse)
+	ai.Post("/chat/stream", aiHandler.StreamChatResponse)
 	ai.Post("/insight", aiHandler.GenerateInsight)
+	ai.Post("/summarize", aiHandler.Summarize)
+	ai.Post("/tokens", aiHandler.CountTokens)
 
 	// Start server
 	// Port is already determined at the top
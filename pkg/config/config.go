@@ -1,9 +1,11 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -19,10 +21,21 @@ type Config struct {
 	JWTSecret        string
 	JWTAccessExpiry  time.Duration
 	JWTRefreshExpiry time.Duration
+	SessionCacheTTL  time.Duration // How long a session's revocation status is cached per service
+	JWTKeys          string        // "kid=path,..." PEM keys for RS256/EdDSA; empty uses JWTSecret (HS256)
+	JWTActiveKeyID   string        // Signing key ID, defaults to the first key
+	JWTAcceptHS256   bool          // With JWTKeys: still accept HS256 tokens during migration
 
 	// Gemini AI
 	GeminiAPIKey string
 
+	// LLM provider
+	LLMProvider   string // "gemini" (default), "openai" (OpenAI-compatible API) or "fake" (offline, scripted)
+	LLMModel      string // empty = provider default
+	LLMBaseURL    string // openai: API base URL
+	LLMAPIKey     string // openai: bearer token
+	LLMFakeScript string // fake: path to JSON rules
+
 	// Service Ports
 	APIGatewayPort  string
 	AuthServicePort string
@@ -40,10 +53,77 @@ type Config struct {
 	RateLimitRequests int
 	RateLimitWindow   time.Duration
 
+	// WebSocket
+	WSBackplane      string // "memory" (single replica) or "mongo" (change streams)
+	WSPingInterval   time.Duration
+	WSPongWait       time.Duration
+	WSWriteWait      time.Duration
+	WSMaxMessageSize int // bytes
+	WSSendBufferSize int // frames queued per client
+
+	// AI chat context
+	AIHistoryTokenBudget int  // estimated tokens of summary + history + new message per request
+	AIHistoryMaxMessages int  // most recent messages considered per request
+	AIHistorySummary     bool // fold older messages into a rolling session summary
+
+	// Login brute-force protection
+	AuthRateLimitRequests int           // Requests per window per IP on /auth routes
+	AuthRateLimitWindow   time.Duration
+	LoginMaxAttempts      int           // Failed logins per account before a lockout
+	LoginIPMaxAttempts    int           // Failed logins per IP address before a lockout
+	LoginAttemptWindow    time.Duration // Failures older than this are forgotten
+	LoginLockoutBase      time.Duration // First lockout, doubled for each further one
+	LoginLockoutMax       time.Duration
+	MFAIssuer             string   // Account issuer shown in authenticator apps
+	TrustedProxies        []string // Proxy IPs/CIDRs whose X-Forwarded-For is believed (auth service)
+
+	// Email (password reset and verification links)
+	Mailer       string // "log" (default, local development) or "smtp"
+	MailFrom     string
+	MailDir      string // Log mailer: also write emails to .eml files here
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	AppURL       string // Frontend base URL used in email links
+
+	// OpenID Connect social login
+	OIDCProviders []OIDCProviderConfig
+
+	// Account deletion
+	AccountDeletionPollInterval time.Duration // How often the deletion worker looks for queued or retried jobs
+
+	// Friend suggestions
+	SuggestionMutualWeight float64       // Weight of the mutual friends score in the ranking
+	SuggestionZodiacWeight float64       // Weight of the zodiac compatibility score in the ranking
+	SuggestionCacheTTL     time.Duration // How long a user's suggestions are cached
+
 	// Environment
 	Environment string
 }
 
+// OIDCProviderConfig configures one OpenID Connect login provider
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	ResponseMode string
+}
+
+// oidcDefaults are the well-known settings of supported providers
+// Any other name works too, as long as OIDC_<NAME>_ISSUER is set
+var oidcDefaults = map[string]OIDCProviderConfig{
+	"google": {Issuer: "https://accounts.google.com", Scopes: []string{"openid", "email", "profile"}},
+	// Apple requires form_post when scopes are requested and only sends the name on the first login
+	"apple": {Issuer: "https://appleid.apple.com", Scopes: []string{"openid", "email", "name"}, ResponseMode: "form_post"},
+}
+
+// defaultJWTSecret is the development fallback for JWT_SECRET
+const defaultJWTSecret = "your-super-secret-jwt-key-change-this-in-production"
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	// Load .env file if exists (ignore error in production)
@@ -55,13 +135,24 @@ func LoadConfig() *Config {
 		MongoDatabase: getEnv("MONGODB_DATABASE", "zodiac_ai"),
 
 		// JWT
-		JWTSecret:        getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-this-in-production"),
+		JWTSecret:        getEnv("JWT_SECRET", defaultJWTSecret),
 		JWTAccessExpiry:  parseDuration(getEnv("JWT_ACCESS_EXPIRY", "15m")),
 		JWTRefreshExpiry: parseDuration(getEnv("JWT_REFRESH_EXPIRY", "720h")),
+		SessionCacheTTL:  parseDuration(getEnv("SESSION_CACHE_TTL", "30s")),
+		JWTKeys:          getEnv("JWT_KEYS", ""),
+		JWTActiveKeyID:   getEnv("JWT_ACTIVE_KEY_ID", ""),
+		JWTAcceptHS256:   getEnv("JWT_ACCEPT_HS256", "false") == "true",
 
 		// Gemini AI
 		GeminiAPIKey: getEnv("GEMINI_API_KEY", ""),
 
+		// LLM provider
+		LLMProvider:   getEnv("LLM_PROVIDER", "gemini"),
+		LLMModel:      getEnv("LLM_MODEL", ""),
+		LLMBaseURL:    getEnv("LLM_BASE_URL", ""),
+		LLMAPIKey:     getEnv("LLM_API_KEY", ""),
+		LLMFakeScript: getEnv("LLM_FAKE_SCRIPT", ""),
+
 		// Service Ports
 		APIGatewayPort:    getEnv("API_GATEWAY_PORT", "8000"),
 		AuthServicePort:   getEnv("AUTH_SERVICE_PORT", "8001"),
@@ -79,11 +170,70 @@ func LoadConfig() *Config {
 		RateLimitRequests: parseInt(getEnv("RATE_LIMIT_REQUESTS", "100")),
 		RateLimitWindow:   parseDuration(getEnv("RATE_LIMIT_WINDOW", "60s")),
 
+		// WebSocket
+		WSBackplane:      getEnv("WS_BACKPLANE", "memory"),
+		WSPingInterval:   parseDuration(getEnv("WS_PING_INTERVAL", "30s")),
+		WSPongWait:       parseDuration(getEnv("WS_PONG_WAIT", "60s")),
+		WSWriteWait:      parseDuration(getEnv("WS_WRITE_WAIT", "10s")),
+		WSMaxMessageSize: parseInt(getEnv("WS_MAX_MESSAGE_SIZE", "8192")),
+		WSSendBufferSize: parseInt(getEnv("WS_SEND_BUFFER_SIZE", "256")),
+
+		// AI chat context
+		AIHistoryTokenBudget: parseInt(getEnv("AI_HISTORY_TOKEN_BUDGET", "2000")),
+		AIHistoryMaxMessages: parseInt(getEnv("AI_HISTORY_MAX_MESSAGES", "50")),
+		AIHistorySummary:     getEnv("AI_HISTORY_SUMMARY", "true") == "true",
+
+		// Login brute-force protection
+		AuthRateLimitRequests: parseInt(getEnv("AUTH_RATE_LIMIT_REQUESTS", "20")),
+		AuthRateLimitWindow:   parseDuration(getEnv("AUTH_RATE_LIMIT_WINDOW", "60s")),
+		LoginMaxAttempts:      parseInt(getEnv("LOGIN_MAX_ATTEMPTS", "5")),
+		LoginIPMaxAttempts:    parseInt(getEnv("LOGIN_IP_MAX_ATTEMPTS", "20")),
+		LoginAttemptWindow:    parseDuration(getEnv("LOGIN_ATTEMPT_WINDOW", "15m")),
+		LoginLockoutBase:      parseDuration(getEnv("LOGIN_LOCKOUT_BASE", "1m")),
+		LoginLockoutMax:       parseDuration(getEnv("LOGIN_LOCKOUT_MAX", "1h")),
+		MFAIssuer:             getEnv("MFA_ISSUER", "Zodiac AI"),
+		TrustedProxies:        parseList(getEnv("TRUSTED_PROXIES", "127.0.0.1,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16")),
+
+		// Email
+		Mailer:       getEnv("MAILER", "log"),
+		MailFrom:     getEnv("MAIL_FROM", "Zodiac AI <no-reply@zodiac-ai.local>"),
+		MailDir:      getEnv("MAIL_DIR", ""),
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     parseInt(getEnv("SMTP_PORT", "587")),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		AppURL:       getEnv("APP_URL", "http://localhost:3000"),
+
+		// OpenID Connect
+		OIDCProviders: loadOIDCProviders(),
+
+		// Account deletion
+		AccountDeletionPollInterval: parseDuration(getEnv("ACCOUNT_DELETION_POLL_INTERVAL", "1m")),
+
+		// Friend suggestions
+		SuggestionMutualWeight: parseFloat(getEnv("SUGGESTION_MUTUAL_WEIGHT", "0.7"), 0.7),
+		SuggestionZodiacWeight: parseFloat(getEnv("SUGGESTION_ZODIAC_WEIGHT", "0.3"), 0.3),
+		SuggestionCacheTTL:     parseDuration(getEnv("SUGGESTION_CACHE_TTL", "10m")),
+
 		// Environment
 		Environment: getEnv("ENVIRONMENT", "development"),
 	}
 }
 
+// Validate rejects settings that are only safe in development
+func (c *Config) Validate() error {
+	if c.Environment != "production" {
+		return nil
+	}
+
+	usesSecret := c.JWTKeys == "" || c.JWTAcceptHS256
+	if usesSecret && (c.JWTSecret == "" || c.JWTSecret == defaultJWTSecret) {
+		return errors.New("JWT_SECRET is not set (default secret refused in production); set it or configure JWT_KEYS")
+	}
+
+	return nil
+}
+
 // getEnv gets environment variable with fallback
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
@@ -102,6 +252,49 @@ func parseDuration(s string) time.Duration {
 	return d
 }
 
+// loadOIDCProviders reads the providers listed in OIDC_PROVIDERS (e.g. "google,apple")
+// Each provider is configured with OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL,
+// _SCOPES (space separated) and _RESPONSE_MODE
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		defaults := oidcDefaults[name]
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", defaults.Issuer),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:       defaults.Scopes,
+			ResponseMode: getEnv(prefix+"RESPONSE_MODE", defaults.ResponseMode),
+		}
+		if scopes := getEnv(prefix+"SCOPES", ""); scopes != "" {
+			provider.Scopes = strings.Fields(scopes)
+		}
+
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// parseList splits a comma-separated list, dropping empty entries
+func parseList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // parseInt parses integer string with error handling
 func parseInt(s string) int {
 	i, err := strconv.Atoi(s)
@@ -111,3 +304,13 @@ func parseInt(s string) int {
 	}
 	return i
 }
+
+// parseFloat parses float string with error handling, falling back to def
+func parseFloat(s string, def float64) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		log.Printf("Warning: Invalid number '%s', using default %v", s, def)
+		return def
+	}
+	return f
+}
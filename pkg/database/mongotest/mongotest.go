@@ -0,0 +1,55 @@
+// Package mongotest gives tests a throwaway MongoDB database
+// Tests using it are skipped unless MONGODB_TEST_URI is set. Transactions need a replica set,
+// so point it at a single-node one, e.g.:
+//
+//	docker run -d --name zodiac-test-mongo -p 27018:27017 mongo:7.0 --replSet rs0
+//	docker exec zodiac-test-mongo mongosh --quiet --eval 'rs.initiate()'
+//	MONGODB_TEST_URI='mongodb://localhost:27018/?directConnection=true' go test ./...
+package mongotest
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// NewDatabase connects to MONGODB_TEST_URI and returns an empty database that is dropped
+// when the test ends
+func NewDatabase(t testing.TB) *mongo.Database {
+	t.Helper()
+
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI not set, skipping MongoDB test")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetServerSelectionTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("connect to MongoDB: %v", err)
+	}
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		t.Fatalf("ping MongoDB: %v", err)
+	}
+
+	db := client.Database("test_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := db.Drop(ctx); err != nil {
+			t.Logf("drop test database %s: %v", db.Name(), err)
+		}
+		client.Disconnect(ctx)
+	})
+
+	return db
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var (
@@ -18,24 +19,49 @@ type TokenType string
 const (
 	AccessToken  TokenType = "access"
 	RefreshToken TokenType = "refresh"
+	MFAPending   TokenType = "mfa_pending" // Password checked, second factor still required
 )
 
+// User roles carried in access tokens
+const (
+	RoleAdmin     = "admin"     // Full access to the admin API, including roles
+	RoleModerator = "moderator" // Content moderation and suspending regular users
+)
+
+// mfaPendingExpiry is how long a user has to enter the TOTP code after the password
+const mfaPendingExpiry = 5 * time.Minute
+
 // Claims represents JWT custom claims
 type Claims struct {
 	UserID     string `json:"user_id"`
 	ZodiacSign string `json:"zodiac_sign"`
 	TokenType  TokenType `json:"token_type"`
+	SessionID  string    `json:"sid,omitempty"` // Access tokens: the login session (refresh token family)
+	Roles      []string  `json:"roles,omitempty"` // Access tokens: the user's roles when the token was issued
 	jwt.RegisteredClaims
 }
 
 // Manager handles JWT token operations
+// Signs with the key set's active key (RS256/EdDSA) when one is configured, HS256 otherwise
 type Manager struct {
 	secretKey        []byte
+	keys             *KeySet // nil: HS256 with secretKey
+	acceptHS256      bool    // HS256 tokens are still verified alongside the key set
 	accessExpiry     time.Duration
 	refreshExpiry    time.Duration
 }
 
-// NewManager creates a new JWT manager
+// Config configures a JWT manager
+type Config struct {
+	Secret            string // HS256 shared secret, used when Keys is empty
+	Keys              string // "kid=path,kid=path" PEM files (private on the auth service, public elsewhere)
+	ActiveKeyID       string // Signing key, defaults to the first key
+	AcceptLegacyHS256 bool   // With Keys: keep accepting HS256 tokens signed with Secret while migrating
+	AccessExpiry      time.Duration
+	RefreshExpiry     time.Duration
+}
+
+// NewManager creates a new HS256 JWT manager
 func NewManager(secretKey string, accessExpiry, refreshExpiry time.Duration) *Manager {
 	return &Manager{
 		secretKey:     []byte(secretKey),
@@ -44,14 +70,66 @@ func NewManager(secretKey string, accessExpiry, refreshExpiry time.Duration) *Ma
 	}
 }
 
+// New creates a JWT manager from configuration
+func New(cfg Config) (*Manager, error) {
+	m := NewManager(cfg.Secret, cfg.AccessExpiry, cfg.RefreshExpiry)
+	if cfg.Keys == "" {
+		return m, nil
+	}
+
+	keys, err := LoadKeySet(cfg.Keys, cfg.ActiveKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.keys = keys
+	m.acceptHS256 = cfg.AcceptLegacyHS256 && cfg.Secret != ""
+	return m, nil
+}
+
+// CanSign reports whether the manager can issue tokens
+// Verify-only deployments (gateway, services) hold public keys only
+func (m *Manager) CanSign() bool {
+	if m.keys == nil {
+		return len(m.secretKey) > 0
+	}
+	return m.keys.Active().CanSign()
+}
+
+// KeySet returns the asymmetric key set, or nil when signing with HS256
+func (m *Manager) KeySet() *KeySet {
+	return m.keys
+}
+
+// sign signs claims with the active key
+func (m *Manager) sign(claims Claims) (string, error) {
+	if m.keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(m.secretKey)
+	}
+
+	key := m.keys.Active()
+	if !key.CanSign() {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
+}
+
 // GenerateAccessToken creates a new access token
 // Access tokens are short-lived (15 min) to reduce attack window
 // Reference: Pragmatic Programmer - Security Through Simplicity
-func (m *Manager) GenerateAccessToken(userID, zodiacSign string) (string, error) {
+// sessionID lets services reject access tokens of a session that was logged out
+// Role changes take effect with the next access token (at most one access expiry later)
+func (m *Manager) GenerateAccessToken(userID, zodiacSign, sessionID string, roles []string) (string, error) {
 	claims := Claims{
 		UserID:     userID,
 		ZodiacSign: zodiacSign,
 		TokenType:  AccessToken,
+		SessionID:  sessionID,
+		Roles:      roles,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.accessExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -59,8 +137,7 @@ func (m *Manager) GenerateAccessToken(userID, zodiacSign string) (string, error)
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secretKey)
+	return m.sign(claims)
 }
 
 // GenerateRefreshToken creates a new refresh token
@@ -71,25 +148,42 @@ func (m *Manager) GenerateRefreshToken(userID, zodiacSign string) (string, error
 		ZodiacSign: zodiacSign,
 		TokenType:  RefreshToken,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(), // Unique per token, even when issued in the same second
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.refreshExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secretKey)
+	return m.sign(claims)
+}
+
+// GenerateMFAPendingToken creates the short-lived token of a login waiting for its second factor
+// It can't be used as an access token; it is only exchanged for tokens at /auth/mfa/verify
+func (m *Manager) GenerateMFAPendingToken(userID, zodiacSign string) (string, error) {
+	claims := Claims{
+		UserID:     userID,
+		ZodiacSign: zodiacSign,
+		TokenType:  MFAPending,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaPendingExpiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return m.sign(claims)
+}
+
+// RefreshExpiry returns how long refresh tokens are valid
+func (m *Manager) RefreshExpiry() time.Duration {
+	return m.refreshExpiry
 }
 
 // VerifyToken verifies and parses a JWT token
 func (m *Manager) VerifyToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
-		}
-		return m.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.verificationKey)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -106,6 +200,34 @@ func (m *Manager) VerifyToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+// verificationKey picks the key for a token by its kid
+// The token's algorithm must match the key's, so a public key can never be used as an HMAC secret
+func (m *Manager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if m.keys != nil && !m.acceptHS256 {
+			return nil, ErrInvalidToken
+		}
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, ErrInvalidToken
+		}
+		return m.secretKey, nil
+	}
+
+	if m.keys == nil {
+		return nil, ErrInvalidToken
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.keys.Get(kid)
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+	return key.public, nil
+}
+
 // ExtractUserID extracts user ID from token without full verification
 // Useful for logging and non-critical operations
 func (m *Manager) ExtractUserID(tokenString string) (string, error) {
@@ -116,6 +238,23 @@ func (m *Manager) ExtractUserID(tokenString string) (string, error) {
 	return claims.UserID, nil
 }
 
+// HasRole reports whether the token carries at least one of the roles
+func (c *Claims) HasRole(roles ...string) bool {
+	return HasRole(c.Roles, roles...)
+}
+
+// HasRole reports whether have contains at least one of the roles
+func HasRole(have []string, roles ...string) bool {
+	for _, role := range have {
+		for _, want := range roles {
+			if role == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // ValidateTokenType checks if token is of expected type
 func (m *Manager) ValidateTokenType(claims *Claims, expectedType TokenType) error {
 	if claims.TokenType != expectedType {
@@ -0,0 +1,310 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeys holds PEM files of one RSA and one Ed25519 key pair
+type testKeys struct {
+	dir       string
+	rsa       *rsa.PrivateKey
+	ed25519   ed25519.PrivateKey
+	rsaPublic []byte // PEM
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k := &testKeys{dir: t.TempDir(), rsa: rsaKey, ed25519: edKey}
+	k.write(t, "rsa.pem", "PRIVATE KEY", mustPKCS8(t, rsaKey))
+	k.write(t, "ed.pem", "PRIVATE KEY", mustPKCS8(t, edKey))
+	k.rsaPublic = k.write(t, "rsa.pub.pem", "PUBLIC KEY", mustPKIX(t, &rsaKey.PublicKey))
+	k.write(t, "ed.pub.pem", "PUBLIC KEY", mustPKIX(t, edKey.Public()))
+	return k
+}
+
+func (k *testKeys) write(t *testing.T, name, blockType string, der []byte) []byte {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(k.dir, name), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func (k *testKeys) path(name string) string {
+	return filepath.Join(k.dir, name)
+}
+
+func mustPKCS8(t *testing.T, key interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func mustPKIX(t *testing.T, key interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func newTestManager(t *testing.T, cfg Config) *Manager {
+	t.Helper()
+
+	cfg.AccessExpiry = 15 * time.Minute
+	cfg.RefreshExpiry = time.Hour
+	m, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestAsymmetricRoundTrip(t *testing.T) {
+	keys := newTestKeys(t)
+
+	tests := []struct {
+		name    string
+		signer  string // private key file
+		checker string // public key file of a verify-only service
+		alg     string
+	}{
+		{name: "RS256", signer: "rsa.pem", checker: "rsa.pub.pem", alg: "RS256"},
+		{name: "EdDSA", signer: "ed.pem", checker: "ed.pub.pem", alg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := newTestManager(t, Config{Keys: "k1=" + keys.path(tt.signer)})
+			verifier := newTestManager(t, Config{Keys: "k1=" + keys.path(tt.checker)})
+
+			token, err := signer.GenerateAccessToken("user-1", "Leo", "session-1", []string{RoleAdmin})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Method.Alg() != tt.alg || parsed.Header["kid"] != "k1" {
+				t.Fatalf("header alg=%v kid=%v, want %s k1", parsed.Method.Alg(), parsed.Header["kid"], tt.alg)
+			}
+
+			for _, m := range []*Manager{signer, verifier} {
+				claims, err := m.VerifyToken(token)
+				if err != nil {
+					t.Fatalf("VerifyToken: %v", err)
+				}
+				if claims.UserID != "user-1" || claims.SessionID != "session-1" || !claims.HasRole(RoleAdmin) {
+					t.Fatalf("unexpected claims %+v", claims)
+				}
+			}
+
+			if verifier.CanSign() {
+				t.Fatal("a public key only manager must not be able to sign")
+			}
+			if _, err := verifier.GenerateAccessToken("user-1", "Leo", "", nil); !errors.Is(err, ErrNoSigningKey) {
+				t.Fatalf("signing with a public key: err = %v, want ErrNoSigningKey", err)
+			}
+		})
+	}
+}
+
+func TestRetiringKeyStillVerifies(t *testing.T) {
+	keys := newTestKeys(t)
+
+	// Before rotation: the RSA key signs
+	before := newTestManager(t, Config{Keys: "old=" + keys.path("rsa.pem")})
+	oldToken, err := before.GenerateAccessToken("user-1", "Leo", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// After rotation: the Ed25519 key signs, the RSA key is kept for verification
+	after := newTestManager(t, Config{
+		Keys:        "old=" + keys.path("rsa.pem") + ",new=" + keys.path("ed.pem"),
+		ActiveKeyID: "new",
+	})
+
+	if _, err := after.VerifyToken(oldToken); err != nil {
+		t.Fatalf("token of the retiring key: %v", err)
+	}
+
+	newToken, err := after.GenerateAccessToken("user-1", "Leo", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != "new" {
+		t.Fatalf("kid = %v, want the active key", parsed.Header["kid"])
+	}
+
+	// Once the old key is dropped, its tokens stop verifying
+	dropped := newTestManager(t, Config{Keys: "new=" + keys.path("ed.pem")})
+	if _, err := dropped.VerifyToken(oldToken); err == nil {
+		t.Fatal("token of a dropped key verified")
+	}
+}
+
+func TestUnknownKidRejected(t *testing.T) {
+	keys := newTestKeys(t)
+
+	signer := newTestManager(t, Config{Keys: "other=" + keys.path("rsa.pem")})
+	token, err := signer.GenerateAccessToken("user-1", "Leo", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := newTestManager(t, Config{Keys: "k1=" + keys.path("rsa.pub.pem")})
+	if _, err := verifier.VerifyToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("err = %v, want ErrInvalidToken", err)
+	}
+
+	// A token without a kid is rejected as well
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodRS256, Claims{UserID: "user-1"})
+	noKid, err := unsigned.SignedString(keys.rsa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.VerifyToken(noKid); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("token without kid: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestAlgorithmConfusionRejected(t *testing.T) {
+	keys := newTestKeys(t)
+	claims := Claims{
+		UserID:    "attacker",
+		TokenType: AccessToken,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	// HS256 signed with the (public) RSA key PEM as the HMAC secret, carrying the RSA key's kid
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hmacToken.Header["kid"] = "k1"
+	forged, err := hmacToken.SignedString(keys.rsaPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// EdDSA signature presented under the RSA key's kid
+	edToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	edToken.Header["kid"] = "k1"
+	mismatched, err := edToken.SignedString(keys.ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	managers := map[string]*Manager{
+		"keys only":           newTestManager(t, Config{Keys: "k1=" + keys.path("rsa.pub.pem")}),
+		"legacy HS256 secret": newTestManager(t, Config{Keys: "k1=" + keys.path("rsa.pub.pem"), Secret: "legacy-secret", AcceptLegacyHS256: true}),
+	}
+
+	for name, m := range managers {
+		t.Run(name, func(t *testing.T) {
+			if _, err := m.VerifyToken(forged); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("HS256 token with an RSA kid: err = %v, want ErrInvalidToken", err)
+			}
+			if _, err := m.VerifyToken(mismatched); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("EdDSA token with an RSA kid: err = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestJWKSContainsOnlyPublicKeys(t *testing.T) {
+	keys := newTestKeys(t)
+
+	m := newTestManager(t, Config{
+		Keys:        "rsa=" + keys.path("rsa.pem") + ",ed=" + keys.path("ed.pem"),
+		ActiveKeyID: "ed",
+	})
+
+	data, err := json.Marshal(m.KeySet().JWKS())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var raw struct {
+		Keys []map[string]interface{} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if len(raw.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(raw.Keys))
+	}
+	if raw.Keys[0]["kid"] != "ed" {
+		t.Fatalf("first key = %v, want the active key", raw.Keys[0]["kid"])
+	}
+
+	// Private JWK members (RFC 7518 section 6.3.2 and RFC 8037)
+	for _, jwk := range raw.Keys {
+		for _, member := range []string{"d", "p", "q", "dp", "dq", "qi", "oth", "k"} {
+			if _, ok := jwk[member]; ok {
+				t.Fatalf("key %v exposes private member %q", jwk["kid"], member)
+			}
+		}
+	}
+
+	set := m.KeySet().JWKS()
+	for _, jwk := range set.Keys {
+		switch jwk.Kid {
+		case "rsa":
+			if jwk.Kty != "RSA" || jwk.Alg != "RS256" {
+				t.Fatalf("rsa key kty=%s alg=%s", jwk.Kty, jwk.Alg)
+			}
+			if jwk.N != base64.RawURLEncoding.EncodeToString(keys.rsa.N.Bytes()) {
+				t.Fatal("rsa modulus doesn't match the public key")
+			}
+		case "ed":
+			public := keys.ed25519.Public().(ed25519.PublicKey)
+			if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.Alg != "EdDSA" {
+				t.Fatalf("ed key kty=%s crv=%s alg=%s", jwk.Kty, jwk.Crv, jwk.Alg)
+			}
+			if jwk.X != base64.RawURLEncoding.EncodeToString(public) {
+				t.Fatal("ed25519 x doesn't match the public key")
+			}
+		}
+	}
+
+	if strings.Contains(string(data), base64.RawURLEncoding.EncodeToString(keys.ed25519.Seed())) {
+		t.Fatal("JWKS contains the ed25519 seed")
+	}
+}
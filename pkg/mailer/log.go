@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogMailer logs emails instead of sending them (local development)
+// With a directory set, each message is also written to an .eml file there
+type LogMailer struct {
+	from string
+	dir  string
+}
+
+// NewLogMailer creates a new log mailer
+func NewLogMailer(from, dir string) *LogMailer {
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+	return &LogMailer{from: from, dir: dir}
+}
+
+// Send logs a message
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	log.Printf("📧 Email to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+
+	if m.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000"), sanitizeFilename(msg.To))
+	if err := os.WriteFile(filepath.Join(m.dir, name), formatMessage(m.from, msg), 0o644); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	return nil
+}
+
+// sanitizeFilename keeps an address usable as part of a file name
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '@', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
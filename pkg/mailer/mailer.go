@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+)
+
+// Mailer backends
+const (
+	BackendSMTP = "smtp"
+	BackendLog  = "log"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Config selects and configures a mailer
+type Config struct {
+	Backend  string // "smtp" or "log" (default)
+	From     string
+	Host     string // SMTP only
+	Port     int    // SMTP only
+	Username string // SMTP only (optional)
+	Password string // SMTP only (optional)
+	Dir      string // Log only: also write each message to a file in this directory
+}
+
+// New creates the mailer for the configured backend
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Backend {
+	case BackendSMTP:
+		return NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.From)
+	case BackendLog, "":
+		return NewLogMailer(cfg.From, cfg.Dir), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q (expected smtp or log)", cfg.Backend)
+	}
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"zodiac-ai-backend/pkg/jwt"
 	"zodiac-ai-backend/pkg/response"
@@ -8,27 +10,45 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var (
+	ErrInvalidAuthHeader = errors.New("invalid authorization header format")
+	ErrMissingToken      = errors.New("missing authorization header or token")
+)
+
+// ExtractToken gets the bearer token from the Authorization header,
+// falling back to the "token" query param (browsers can't set headers on WebSocket upgrades)
+func ExtractToken(c *fiber.Ctx) (string, error) {
+	authHeader := c.Get("Authorization")
+	if authHeader != "" {
+		// Check Bearer prefix
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			return "", ErrInvalidAuthHeader
+		}
+		return parts[1], nil
+	}
+
+	tokenString := c.Query("token")
+	if tokenString == "" {
+		return "", ErrMissingToken
+	}
+	return tokenString, nil
+}
+
+// TokenValidator performs extra checks on a verified access token (e.g. session revocation)
+type TokenValidator func(ctx context.Context, claims *jwt.Claims) error
+
 // AuthMiddleware creates authentication middleware
-// Verifies JWT token and injects user context
-func AuthMiddleware(jwtManager *jwt.Manager) fiber.Handler {
+// Verifies JWT token, runs the validators and injects user context
+func AuthMiddleware(jwtManager *jwt.Manager, validators ...TokenValidator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Extract token from Authorization header or query param (for WebSocket)
-		authHeader := c.Get("Authorization")
-		var tokenString string
-
-		if authHeader != "" {
-			// Check Bearer prefix
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
+		tokenString, err := ExtractToken(c)
+		if err != nil {
+			if err == ErrInvalidAuthHeader {
 				return response.Unauthorized(c, "Invalid authorization header format")
 			}
-			tokenString = parts[1]
-		} else {
-			// Try to get token from query param (for WebSocket)
-			tokenString = c.Query("token")
-			if tokenString == "" {
-				return response.Unauthorized(c, "Missing authorization header or token")
-			}
+			return response.Unauthorized(c, "Missing authorization header or token")
 		}
 
 		// Verify token
@@ -45,14 +65,60 @@ func AuthMiddleware(jwtManager *jwt.Manager) fiber.Handler {
 			return response.Unauthorized(c, "Invalid token type")
 		}
 
+		for _, validate := range validators {
+			if err := validate(c.Context(), claims); err != nil {
+				if err == ErrSessionRevoked {
+					return response.Unauthorized(c, "Session has been revoked")
+				}
+				if err == ErrUserSuspended {
+					return response.Forbidden(c, "Account suspended")
+				}
+				return response.ServiceUnavailable(c, "Unable to verify session")
+			}
+		}
+
 		// Inject user context into request
-		c.Locals("user_id", claims.UserID)
-		c.Locals("zodiac_sign", claims.ZodiacSign)
+		setUserContext(c, claims)
 
 		return c.Next()
 	}
 }
 
+// OptionalAuthMiddleware identifies the user on public routes
+// Requests with a valid access token get the same user context as AuthMiddleware;
+// requests without one, or with one that fails verification, continue anonymously
+func OptionalAuthMiddleware(jwtManager *jwt.Manager, validators ...TokenValidator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokenString, err := ExtractToken(c)
+		if err != nil {
+			return c.Next()
+		}
+
+		claims, err := jwtManager.VerifyToken(tokenString)
+		if err != nil || jwtManager.ValidateTokenType(claims, jwt.AccessToken) != nil {
+			return c.Next()
+		}
+
+		for _, validate := range validators {
+			if err := validate(c.Context(), claims); err != nil {
+				return c.Next()
+			}
+		}
+
+		setUserContext(c, claims)
+
+		return c.Next()
+	}
+}
+
+// setUserContext injects the verified user's identity into the request
+func setUserContext(c *fiber.Ctx, claims *jwt.Claims) {
+	c.Locals("user_id", claims.UserID)
+	c.Locals("zodiac_sign", claims.ZodiacSign)
+	c.Locals("session_id", claims.SessionID)
+	c.Locals("roles", claims.Roles)
+}
+
 // GetUserID extracts user ID from context
 func GetUserID(c *fiber.Ctx) string {
 	userID, ok := c.Locals("user_id").(string)
@@ -62,6 +128,16 @@ func GetUserID(c *fiber.Ctx) string {
 	return userID
 }
 
+// GetSessionID extracts the session ID (refresh token family) from context
+// Empty for access tokens issued before sessions were tracked
+func GetSessionID(c *fiber.Ctx) string {
+	sessionID, ok := c.Locals("session_id").(string)
+	if !ok {
+		return ""
+	}
+	return sessionID
+}
+
 // GetZodiacSign extracts zodiac sign from context
 func GetZodiacSign(c *fiber.Ctx) string {
 	zodiacSign, ok := c.Locals("zodiac_sign").(string)
@@ -70,3 +146,12 @@ func GetZodiacSign(c *fiber.Ctx) string {
 	}
 	return zodiacSign
 }
+
+// GetRoles extracts the user's roles from context
+func GetRoles(c *fiber.Ctx) []string {
+	roles, ok := c.Locals("roles").([]string)
+	if !ok {
+		return nil
+	}
+	return roles
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"zodiac-ai-backend/pkg/jwt"
+	"zodiac-ai-backend/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole allows requests whose access token carries at least one of the roles
+// Must run after AuthMiddleware
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if jwt.HasRole(GetRoles(c), roles...) {
+			return c.Next()
+		}
+		return response.Forbidden(c, "Insufficient permissions")
+	}
+}
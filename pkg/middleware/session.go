@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"zodiac-ai-backend/pkg/jwt"
+)
+
+var (
+	ErrSessionRevoked = errors.New("session has been revoked")
+)
+
+// SessionChecker reports whether a login session is still active
+// Implemented by the auth service's RefreshTokenRepository
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+}
+
+// RevocationCache enforces logout on access tokens
+// Access tokens are stateless, so without this check they stay valid until they expire even after
+// their session is logged out. Results are cached for a short TTL to avoid a database lookup per
+// request: a revoked session is rejected at most one TTL after logout.
+type RevocationCache struct {
+	checker SessionChecker
+	active  *ttlCache[bool] // By session ID
+}
+
+// NewRevocationCache creates a new revocation cache
+func NewRevocationCache(checker SessionChecker, ttl time.Duration) *RevocationCache {
+	return &RevocationCache{
+		checker: checker,
+		active:  newTTLCache[bool](ttl),
+	}
+}
+
+// Validate rejects access tokens whose session has been revoked
+// Tokens without a session ID (issued before sessions were tracked) are accepted until they expire
+func (rc *RevocationCache) Validate(ctx context.Context, claims *jwt.Claims) error {
+	if claims.SessionID == "" {
+		return nil
+	}
+
+	active, err := rc.active.get(ctx, claims.SessionID, rc.checker.IsSessionActive)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrSessionRevoked
+	}
+	return nil
+}
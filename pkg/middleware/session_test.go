@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"zodiac-ai-backend/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeSessions is a SessionChecker backed by a map, counting lookups
+type fakeSessions struct {
+	mu      sync.Mutex
+	revoked map[string]bool
+	lookups int
+	err     error
+}
+
+func (f *fakeSessions) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lookups++
+	if f.err != nil {
+		return false, f.err
+	}
+	return !f.revoked[sessionID], nil
+}
+
+func (f *fakeSessions) revoke(sessionID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.revoked[sessionID] = true
+}
+
+func (f *fakeSessions) lookupCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lookups
+}
+
+// newSessionTestApp serves GET / behind AuthMiddleware with a revocation cache
+func newSessionTestApp(jwtManager *jwt.Manager, cache *RevocationCache) *fiber.App {
+	app := fiber.New()
+	app.Get("/", AuthMiddleware(jwtManager, cache.Validate), func(c *fiber.Ctx) error {
+		return c.SendString(GetSessionID(c))
+	})
+	return app
+}
+
+// get requests / with an access token and returns the status code
+func get(t *testing.T, app *fiber.App, accessToken string) int {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+accessToken)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func TestRevokedSessionRejectedOnceCacheEntryExpires(t *testing.T) {
+	jwtManager := jwt.NewManager("test-secret", 15*time.Minute, time.Hour)
+	sessions := &fakeSessions{revoked: map[string]bool{}}
+	cache := NewRevocationCache(sessions, 50*time.Millisecond)
+	app := newSessionTestApp(jwtManager, cache)
+
+	token, err := jwtManager.GenerateAccessToken("user-1", "Leo", "session-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status := get(t, app, token); status != fiber.StatusOK {
+		t.Fatalf("active session: status %d, want 200", status)
+	}
+
+	// Logged out: the cached answer holds until the entry expires
+	sessions.revoke("session-1")
+	if status := get(t, app, token); status != fiber.StatusOK {
+		t.Fatalf("cached session: status %d, want 200", status)
+	}
+	if n := sessions.lookupCount(); n != 1 {
+		t.Fatalf("%d lookups, want 1 (second request served from the cache)", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if status := get(t, app, token); status != fiber.StatusUnauthorized {
+		t.Fatalf("revoked session: status %d, want 401", status)
+	}
+
+	// Other sessions are unaffected
+	other, err := jwtManager.GenerateAccessToken("user-1", "Leo", "session-2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := get(t, app, other); status != fiber.StatusOK {
+		t.Fatalf("other session: status %d, want 200", status)
+	}
+}
+
+func TestRevocationCacheValidate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("token without session", func(t *testing.T) {
+		sessions := &fakeSessions{revoked: map[string]bool{}}
+		cache := NewRevocationCache(sessions, time.Minute)
+
+		if err := cache.Validate(ctx, &jwt.Claims{UserID: "user-1"}); err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+		if n := sessions.lookupCount(); n != 0 {
+			t.Fatalf("%d lookups for a token without a session, want 0", n)
+		}
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		sessions := &fakeSessions{revoked: map[string]bool{"session-1": true}}
+		cache := NewRevocationCache(sessions, time.Minute)
+
+		for i := 0; i < 2; i++ {
+			if err := cache.Validate(ctx, &jwt.Claims{SessionID: "session-1"}); err != ErrSessionRevoked {
+				t.Fatalf("err = %v, want ErrSessionRevoked", err)
+			}
+		}
+		if n := sessions.lookupCount(); n != 1 {
+			t.Fatalf("%d lookups, want 1 (revocations are cached too)", n)
+		}
+	})
+
+	t.Run("lookup error", func(t *testing.T) {
+		lookupErr := errors.New("database down")
+		sessions := &fakeSessions{revoked: map[string]bool{}, err: lookupErr}
+		cache := NewRevocationCache(sessions, time.Minute)
+
+		if err := cache.Validate(ctx, &jwt.Claims{SessionID: "session-1"}); err != lookupErr {
+			t.Fatalf("err = %v, want the lookup error", err)
+		}
+
+		// Errors aren't cached
+		sessions.err = nil
+		if err := cache.Validate(ctx, &jwt.Claims{SessionID: "session-1"}); err != nil {
+			t.Fatalf("after recovery: err = %v, want nil", err)
+		}
+	})
+}
+
+func TestAuthMiddlewareUnavailableWhenSessionLookupFails(t *testing.T) {
+	jwtManager := jwt.NewManager("test-secret", 15*time.Minute, time.Hour)
+	sessions := &fakeSessions{revoked: map[string]bool{}, err: errors.New("database down")}
+	app := newSessionTestApp(jwtManager, NewRevocationCache(sessions, time.Minute))
+
+	token, err := jwtManager.GenerateAccessToken("user-1", "Leo", "session-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := get(t, app, token); status != fiber.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503", status)
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"zodiac-ai-backend/pkg/jwt"
+)
+
+var (
+	ErrUserSuspended = errors.New("account suspended")
+)
+
+// SuspensionChecker reports whether a user is currently suspended
+// Implemented by the auth service's UserRepository
+type SuspensionChecker interface {
+	IsSuspended(ctx context.Context, userID string) (bool, error)
+}
+
+// SuspensionCache rejects access tokens of suspended users
+// Like RevocationCache, results are cached for a short TTL: a suspension takes effect at most
+// one TTL after it is issued, without a database lookup per request.
+type SuspensionCache struct {
+	checker   SuspensionChecker
+	suspended *ttlCache[bool] // By user ID
+}
+
+// NewSuspensionCache creates a new suspension cache
+func NewSuspensionCache(checker SuspensionChecker, ttl time.Duration) *SuspensionCache {
+	return &SuspensionCache{
+		checker:   checker,
+		suspended: newTTLCache[bool](ttl),
+	}
+}
+
+// Validate rejects access tokens of suspended users
+func (sc *SuspensionCache) Validate(ctx context.Context, claims *jwt.Claims) error {
+	suspended, err := sc.suspended.get(ctx, claims.UserID, sc.checker.IsSuspended)
+	if err != nil {
+		return err
+	}
+	if suspended {
+		return ErrUserSuspended
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"zodiac-ai-backend/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeSuspensions is a SuspensionChecker backed by a map
+type fakeSuspensions map[string]bool
+
+func (f fakeSuspensions) IsSuspended(ctx context.Context, userID string) (bool, error) {
+	return f[userID], nil
+}
+
+func TestSuspendedUserRejected(t *testing.T) {
+	jwtManager := jwt.NewManager("test-secret", 15*time.Minute, time.Hour)
+	cache := NewSuspensionCache(fakeSuspensions{"suspended": true}, time.Minute)
+
+	app := fiber.New()
+	app.Get("/", AuthMiddleware(jwtManager, cache.Validate), func(c *fiber.Ctx) error {
+		return c.SendString(GetUserID(c))
+	})
+
+	tests := []struct {
+		userID string
+		want   int
+	}{
+		{userID: "suspended", want: fiber.StatusForbidden},
+		{userID: "active", want: fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		token, err := jwtManager.GenerateAccessToken(tt.userID, "Leo", "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := get(t, app, token); status != tt.want {
+			t.Fatalf("%s user: status %d, want %d", tt.userID, status, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ttlCache caches the result of a lookup per key for a short TTL
+// It backs the token validators (RevocationCache, SuspensionCache), which would otherwise
+// hit the database on every request.
+type ttlCache[V any] struct {
+	ttl     time.Duration
+	entries map[string]ttlEntry[V]
+	mu      sync.RWMutex
+}
+
+type ttlEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// newTTLCache creates a new TTL cache
+func newTTLCache[V any](ttl time.Duration) *ttlCache[V] {
+	c := &ttlCache[V]{
+		ttl:     ttl,
+		entries: make(map[string]ttlEntry[V]),
+	}
+
+	// Cleanup goroutine to prevent memory leaks
+	go c.cleanup()
+
+	return c
+}
+
+// get returns the cached value of key, calling load if there is none or it expired
+// Errors from load aren't cached
+func (c *ttlCache[V]) get(ctx context.Context, key string, load func(ctx context.Context, key string) (V, error)) (V, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	value, err := load(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = ttlEntry[V]{value: value, expiresAt: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return value, nil
+}
+
+// cleanup removes expired entries to prevent memory leaks
+func (c *ttlCache[V]) cleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.mu.Lock()
+		now := time.Now()
+		for key, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
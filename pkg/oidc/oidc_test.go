@@ -0,0 +1,246 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockServer is a minimal OpenID Connect provider: discovery, JWKS and a token endpoint
+// that checks the PKCE verifier and returns an ID token with the configured claims
+type mockServer struct {
+	*httptest.Server
+	key       *ecdsa.PrivateKey
+	challenge string          // PKCE challenge of the last authorization request
+	claims    jwt.MapClaims   // Extra claims for the next ID token
+	codes     map[string]bool // Issued authorization codes
+}
+
+func newMockServer(t *testing.T) *mockServer {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockServer{key: key, codes: map[string]bool{}}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "EC",
+				"kid": "mock-key",
+				"use": "sig",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(key.PublicKey.X.FillBytes(make([]byte, 32))),
+				"y":   base64.RawURLEncoding.EncodeToString(key.PublicKey.Y.FillBytes(make([]byte, 32))),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		code := r.PostForm.Get("code")
+		if !m.codes[code] || ChallengeS256(r.PostForm.Get("code_verifier")) != m.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		delete(m.codes, code)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "mock-access-token",
+			"token_type":   "Bearer",
+			"id_token":     m.idToken(t, r.PostForm.Get("client_id")),
+			"expires_in":   3600,
+		})
+	})
+
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// authorize simulates the user signing in: records the PKCE challenge and returns a code
+func (m *mockServer) authorize(t *testing.T, authURL string) string {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method := u.Query().Get("code_challenge_method"); method != "S256" {
+		t.Fatalf("code_challenge_method = %q, want S256", method)
+	}
+
+	m.challenge = u.Query().Get("code_challenge")
+	m.claims["nonce"] = u.Query().Get("nonce")
+	m.codes["mock-code"] = true
+	return "mock-code"
+}
+
+func (m *mockServer) idToken(t *testing.T, audience string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"iss": m.URL,
+		"aud": audience,
+		"sub": "mock-user-1",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range m.claims {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = "mock-key"
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func newTestProvider(t *testing.T, issuer string) *Provider {
+	t.Helper()
+
+	provider, err := NewProvider(Config{
+		Name:        "mock",
+		Issuer:      issuer,
+		ClientID:    "zodiac-client",
+		RedirectURL: "http://localhost:3000/auth/callback/mock",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	server := newMockServer(t)
+	provider := newTestProvider(t, server.URL)
+	ctx := context.Background()
+
+	verifier, err := GenerateVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Apple sends email_verified as a string
+	server.claims = jwt.MapClaims{"email": "user@example.com", "email_verified": "true", "name": "Mock User"}
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", ChallengeS256(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code := server.authorize(t, authURL)
+
+	tokens, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	idToken, err := provider.VerifyIDToken(ctx, tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+
+	if idToken.Subject != "mock-user-1" || idToken.Email != "user@example.com" || !idToken.EmailVerified || idToken.Name != "Mock User" {
+		t.Errorf("unexpected ID token claims: %+v", idToken)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	server := newMockServer(t)
+	provider := newTestProvider(t, server.URL)
+	ctx := context.Background()
+
+	verifier, _ := GenerateVerifier()
+	server.claims = jwt.MapClaims{}
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", ChallengeS256(verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := server.authorize(t, authURL)
+
+	otherVerifier, _ := GenerateVerifier()
+	if _, err := provider.Exchange(ctx, code, otherVerifier); !errors.Is(err, ErrTokenExchange) {
+		t.Errorf("Exchange with wrong verifier: err = %v, want ErrTokenExchange", err)
+	}
+}
+
+func TestVerifyIDTokenRejections(t *testing.T) {
+	server := newMockServer(t)
+	provider := newTestProvider(t, server.URL)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		nonce  string
+		want   error
+	}{
+		{"wrong nonce", jwt.MapClaims{"nonce": "other"}, "nonce-1", ErrNonceMismatch},
+		{"wrong audience", jwt.MapClaims{"nonce": "nonce-1", "aud": "someone-else"}, "nonce-1", ErrInvalidIDToken},
+		{"wrong issuer", jwt.MapClaims{"nonce": "nonce-1", "iss": "https://evil.example.com"}, "nonce-1", ErrInvalidIDToken},
+		{"expired", jwt.MapClaims{"nonce": "nonce-1", "exp": time.Now().Add(-time.Hour).Unix()}, "nonce-1", ErrInvalidIDToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.claims = tt.claims
+			raw := server.idToken(t, "zodiac-client")
+
+			if _, err := provider.VerifyIDToken(ctx, raw, tt.nonce); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// A token signed by another key with the same kid
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	forged := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": server.URL, "aud": "zodiac-client", "sub": "mock-user-1", "nonce": "nonce-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = "mock-key"
+	raw, _ := forged.SignedString(otherKey)
+
+	if _, err := provider.VerifyIDToken(ctx, raw, "nonce-1"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("forged token: err = %v, want ErrInvalidIDToken", err)
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	server := newMockServer(t)
+
+	// An issuer the server doesn't serve a discovery document for
+	provider := newTestProvider(t, server.URL+"/other")
+	if _, err := provider.AuthCodeURL(context.Background(), "s", "n", "c"); !errors.Is(err, ErrDiscovery) {
+		t.Errorf("err = %v, want ErrDiscovery", err)
+	}
+}
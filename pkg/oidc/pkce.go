@@ -0,0 +1,28 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// RandomString returns a URL-safe random string carrying n bytes of entropy
+// Used for state, nonce and PKCE verifiers
+func RandomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GenerateVerifier returns a PKCE code verifier (RFC 7636: 43 characters)
+func GenerateVerifier() (string, error) {
+	return RandomString(32)
+}
+
+// ChallengeS256 returns the S256 code challenge of a verifier
+func ChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -62,6 +62,15 @@ func Created(c *fiber.Ctx, message string, data interface{}) error {
 	})
 }
 
+// Accepted sends a 202 Accepted response (the work continues in the background)
+func Accepted(c *fiber.Ctx, message string, data interface{}) error {
+	return c.Status(fiber.StatusAccepted).JSON(APIResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
 // BadRequest sends a 400 Bad Request response
 func BadRequest(c *fiber.Ctx, message string, details map[string]interface{}) error {
 	return c.Status(fiber.StatusBadRequest).JSON(APIResponse{
@@ -0,0 +1,114 @@
+package sse
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Event names shared by the AI service and the chat service streams
+const (
+	EventStart = "start" // Chat stream only: the saved user message
+	EventChunk = "chunk" // Token chunk: {"delta": "..."}
+	EventDone  = "done"  // Stream completed
+	EventError = "error" // Stream failed: {"message": "..."}
+)
+
+// Event represents a single Server-Sent Event
+type Event struct {
+	Name string
+	Data string
+}
+
+// Chunk is the payload of a chunk event
+type Chunk struct {
+	Delta string `json:"delta"`
+}
+
+// Error is the payload of an error event
+type Error struct {
+	Message string `json:"message"`
+}
+
+// Headers returns the response headers for an event stream
+// X-Accel-Buffering disables proxy buffering (nginx) so chunks arrive as they are written
+func Headers() map[string]string {
+	return map[string]string{
+		"Content-Type":      "text/event-stream",
+		"Cache-Control":     "no-cache",
+		"Connection":        "keep-alive",
+		"X-Accel-Buffering": "no",
+	}
+}
+
+// IsEventStream reports whether a Content-Type header is an event stream
+func IsEventStream(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/event-stream")
+}
+
+// Write writes a JSON-encoded event and flushes it to the client
+// A flush error means the client has gone away
+func Write(w *bufio.Writer, name string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.WriteString("event: " + name + "\ndata: " + string(payload) + "\n\n"); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// Reader reads events from an event stream
+type Reader struct {
+	scanner *bufio.Scanner
+}
+
+// NewReader creates a new event stream reader
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), 1024*1024)
+	return &Reader{scanner: scanner}
+}
+
+// Next reads the next event
+// Returns io.EOF when the stream ends
+func (r *Reader) Next() (*Event, error) {
+	var event Event
+	var data []string
+
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+
+		// Blank line dispatches the event
+		if line == "" {
+			if event.Name == "" && len(data) == 0 {
+				continue
+			}
+			event.Data = strings.Join(data, "\n")
+			return &event, nil
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event.Name = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Decode decodes the event's JSON data
+func (e *Event) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(e.Data), v)
+}
@@ -0,0 +1,137 @@
+package totp
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 4226 Appendix D: HOTP values for the ASCII secret "12345678901234567890"
+func TestHOTPRFC4226Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	expected := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+
+	for counter, want := range expected {
+		if got := HOTP(key, uint64(counter), 6, sha1.New); got != want {
+			t.Errorf("HOTP(counter=%d) = %s, want %s", counter, got, want)
+		}
+	}
+}
+
+// RFC 6238 Appendix B: 8-digit TOTP values with a 30 second period
+func TestTOTPRFC6238Vectors(t *testing.T) {
+	seed := "12345678901234567890"
+	keys := map[string]struct {
+		key     []byte
+		newHash func() hash.Hash
+	}{
+		"SHA1":   {[]byte(seed), sha1.New},
+		"SHA256": {[]byte(strings.Repeat(seed, 2)[:32]), sha256.New},
+		"SHA512": {[]byte(strings.Repeat(seed, 4)[:64]), sha512.New},
+	}
+
+	vectors := []struct {
+		unix int64
+		algo string
+		want string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111109, "SHA256", "68084774"},
+		{1111111109, "SHA512", "25091201"},
+		{1111111111, "SHA1", "14050471"},
+		{1111111111, "SHA256", "67062674"},
+		{1111111111, "SHA512", "99943326"},
+		{1234567890, "SHA1", "89005924"},
+		{1234567890, "SHA256", "91819424"},
+		{1234567890, "SHA512", "93441116"},
+		{2000000000, "SHA1", "69279037"},
+		{2000000000, "SHA256", "90698825"},
+		{2000000000, "SHA512", "38618901"},
+		{20000000000, "SHA1", "65353130"},
+		{20000000000, "SHA256", "77737706"},
+		{20000000000, "SHA512", "47863826"},
+	}
+
+	for _, v := range vectors {
+		k := keys[v.algo]
+		step := Step(time.Unix(v.unix, 0))
+		if got := HOTP(k.key, uint64(step), 8, k.newHash); got != v.want {
+			t.Errorf("TOTP(%s, t=%d) = %s, want %s", v.algo, v.unix, got, v.want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	// base32 of "12345678901234567890"
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	now := time.Unix(1111111109, 0)
+
+	code, err := Code(secret, now)
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+	if code != "081804" {
+		t.Fatalf("Code = %s, want 081804", code)
+	}
+
+	if step, ok := Verify(secret, code, now, 1); !ok || step != Step(now) {
+		t.Errorf("Verify(current code) = %d, %v", step, ok)
+	}
+
+	// One step of clock drift is tolerated, two are not
+	if _, ok := Verify(secret, code, now.Add(Period), 1); !ok {
+		t.Error("Verify rejected a code one step old")
+	}
+	if _, ok := Verify(secret, code, now.Add(2*Period), 1); ok {
+		t.Error("Verify accepted a code two steps old")
+	}
+
+	if _, ok := Verify(secret, "000000", now, 1); ok {
+		t.Error("Verify accepted a wrong code")
+	}
+	if _, ok := Verify(secret, "12345", now, 1); ok {
+		t.Error("Verify accepted a short code")
+	}
+	if _, ok := Verify("not base32!", code, now, 1); ok {
+		t.Error("Verify accepted an invalid secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+
+	key, err := DecodeSecret(secret)
+	if err != nil {
+		t.Fatalf("DecodeSecret(%q): %v", secret, err)
+	}
+	if len(key) != 20 {
+		t.Errorf("secret is %d bytes, want 20", len(key))
+	}
+
+	// Hand-typed secrets: lowercase and spaces
+	if _, err := DecodeSecret(strings.ToLower(secret[:4]) + " " + secret[4:]); err != nil {
+		t.Errorf("DecodeSecret rejected a lowercase secret with spaces: %v", err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Zodiac AI", "user@example.com", "JBSWY3DPEHPK3PXP")
+
+	want := "otpauth://totp/Zodiac%20AI:user@example.com?algorithm=SHA1&digits=6&issuer=Zodiac+AI&period=30&secret=JBSWY3DPEHPK3PXP"
+	if uri != want {
+		t.Errorf("URI = %s, want %s", uri, want)
+	}
+}
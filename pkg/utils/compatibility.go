@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownSign = errors.New("unknown zodiac sign")
+)
+
+// Element is a sign's element
+type Element string
+
+const (
+	Fire  Element = "fire"
+	Earth Element = "earth"
+	Air   Element = "air"
+	Water Element = "water"
+)
+
+// Modality is a sign's modality (quality)
+type Modality string
+
+const (
+	Cardinal Modality = "cardinal"
+	Fixed    Modality = "fixed"
+	Mutable  Modality = "mutable"
+)
+
+// SignInfo is a sign with its element and modality
+type SignInfo struct {
+	Sign     ZodiacSign `json:"sign"`
+	Element  Element    `json:"element"`
+	Modality Modality   `json:"modality"`
+}
+
+// signInfos holds the metadata of each sign
+var signInfos = map[ZodiacSign]SignInfo{
+	Aries:       {Sign: Aries, Element: Fire, Modality: Cardinal},
+	Taurus:      {Sign: Taurus, Element: Earth, Modality: Fixed},
+	Gemini:      {Sign: Gemini, Element: Air, Modality: Mutable},
+	Cancer:      {Sign: Cancer, Element: Water, Modality: Cardinal},
+	Leo:         {Sign: Leo, Element: Fire, Modality: Fixed},
+	Virgo:       {Sign: Virgo, Element: Earth, Modality: Mutable},
+	Libra:       {Sign: Libra, Element: Air, Modality: Cardinal},
+	Scorpio:     {Sign: Scorpio, Element: Water, Modality: Fixed},
+	Sagittarius: {Sign: Sagittarius, Element: Fire, Modality: Mutable},
+	Capricorn:   {Sign: Capricorn, Element: Earth, Modality: Cardinal},
+	Aquarius:    {Sign: Aquarius, Element: Air, Modality: Fixed},
+	Pisces:      {Sign: Pisces, Element: Water, Modality: Mutable},
+}
+
+// GetSignInfo returns a sign's element and modality
+func GetSignInfo(sign ZodiacSign) (SignInfo, bool) {
+	info, ok := signInfos[sign]
+	return info, ok
+}
+
+// ParseZodiacSign parses a sign name, ignoring case (e.g. "leo" is Leo)
+func ParseZodiacSign(name string) (ZodiacSign, bool) {
+	for _, sign := range Signs {
+		if strings.EqualFold(string(sign), strings.TrimSpace(name)) {
+			return sign, true
+		}
+	}
+	return "", false
+}
+
+// Compatibility rates how well two signs get along
+type Compatibility struct {
+	SignA       SignInfo `json:"sign_a"`
+	SignB       SignInfo `json:"sign_b"`
+	Aspect      string   `json:"aspect"` // conjunction, semi-sextile, sextile, square, trine, quincunx or opposition
+	Score       int      `json:"score"`  // 0 to 100
+	Explanation string   `json:"explanation"`
+}
+
+// aspect is the relationship between two signs a given number of signs apart
+type aspect struct {
+	name  string
+	score int
+}
+
+// aspects by distance between the signs around the zodiac (0 to 6)
+// Signs two and four apart share complementary or equal elements, three apart clash,
+// six apart are opposites of the same modality.
+var aspects = [7]aspect{
+	{name: "conjunction", score: 80},
+	{name: "semi-sextile", score: 55},
+	{name: "sextile", score: 85},
+	{name: "square", score: 40},
+	{name: "trine", score: 95},
+	{name: "quincunx", score: 35},
+	{name: "opposition", score: 70},
+}
+
+// compatibilityMatrix holds the compatibility of every pair of signs, indexed like Signs
+var compatibilityMatrix = buildCompatibilityMatrix()
+
+// buildCompatibilityMatrix rates every pair of signs by their aspect
+func buildCompatibilityMatrix() [12][12]Compatibility {
+	var matrix [12][12]Compatibility
+	for i, a := range Signs {
+		for j, b := range Signs {
+			distance := (j - i + 12) % 12
+			if distance > 6 {
+				distance = 12 - distance
+			}
+
+			infoA, infoB := signInfos[a], signInfos[b]
+			matrix[i][j] = Compatibility{
+				SignA:       infoA,
+				SignB:       infoB,
+				Aspect:      aspects[distance].name,
+				Score:       aspects[distance].score,
+				Explanation: explainAspect(distance, infoA, infoB),
+			}
+		}
+	}
+	return matrix
+}
+
+// explainAspect explains the compatibility of two signs distance signs apart
+func explainAspect(distance int, a, b SignInfo) string {
+	switch distance {
+	case 0:
+		return fmt.Sprintf("Both %s: they understand each other instinctively, but share the same blind spots.", a.Sign)
+	case 1:
+		return fmt.Sprintf("Neighbouring signs: %s and %s have little in common, so they learn from each other once they make the effort.", a.Element, b.Element)
+	case 2:
+		return fmt.Sprintf("%s and %s complement each other: an easy, stimulating friendship.", capitalize(string(a.Element)), b.Element)
+	case 3:
+		return fmt.Sprintf("Both %s signs with clashing elements (%s and %s): friction that can push them to grow.", a.Modality, a.Element, b.Element)
+	case 4:
+		return fmt.Sprintf("Both %s signs: they share values and rhythm, the most natural match.", a.Element)
+	case 5:
+		return fmt.Sprintf("%s and %s, %s and %s: different in almost every way, it takes patience.", capitalize(string(a.Element)), b.Element, a.Modality, b.Modality)
+	default:
+		return fmt.Sprintf("Opposite %s signs: %s and %s attract and balance each other, with some tension.", a.Modality, a.Element, b.Element)
+	}
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// CompareSigns returns the compatibility of two signs
+func CompareSigns(a, b ZodiacSign) (*Compatibility, error) {
+	i, j := signIndex(a), signIndex(b)
+	if i < 0 || j < 0 {
+		return nil, ErrUnknownSign
+	}
+
+	compatibility := compatibilityMatrix[i][j]
+	return &compatibility, nil
+}
+
+// CompatibilityScore rates how well two signs get along, from 0 to 1
+// An unknown sign scores a neutral 0.5
+func CompatibilityScore(a, b ZodiacSign) float64 {
+	compatibility, err := CompareSigns(a, b)
+	if err != nil {
+		return 0.5
+	}
+	return float64(compatibility.Score) / 100
+}
+
+// signIndex returns the position of sign in Signs, or -1
+func signIndex(sign ZodiacSign) int {
+	for i, candidate := range Signs {
+		if candidate == sign {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompareSigns(t *testing.T) {
+	tests := []struct {
+		a, b   ZodiacSign
+		aspect string
+		score  int
+	}{
+		{a: Aries, b: Aries, aspect: "conjunction", score: 80},
+		{a: Aries, b: Taurus, aspect: "semi-sextile", score: 55},
+		{a: Aries, b: Gemini, aspect: "sextile", score: 85},
+		{a: Aries, b: Cancer, aspect: "square", score: 40},
+		{a: Aries, b: Leo, aspect: "trine", score: 95},
+		{a: Aries, b: Virgo, aspect: "quincunx", score: 35},
+		{a: Aries, b: Libra, aspect: "opposition", score: 70},
+		{a: Taurus, b: Capricorn, aspect: "trine", score: 95},
+		{a: Leo, b: Scorpio, aspect: "square", score: 40},
+		{a: Cancer, b: Capricorn, aspect: "opposition", score: 70},
+		{a: Pisces, b: Aries, aspect: "semi-sextile", score: 55}, // Wraps around the zodiac
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.a)+"/"+string(tt.b), func(t *testing.T) {
+			compatibility, err := CompareSigns(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if compatibility.Aspect != tt.aspect || compatibility.Score != tt.score {
+				t.Fatalf("got %s %d, want %s %d", compatibility.Aspect, compatibility.Score, tt.aspect, tt.score)
+			}
+			if compatibility.SignA.Sign != tt.a || compatibility.SignB.Sign != tt.b {
+				t.Fatalf("signs %s/%s, want %s/%s", compatibility.SignA.Sign, compatibility.SignB.Sign, tt.a, tt.b)
+			}
+		})
+	}
+}
+
+func TestCompareSignsSymmetricWithConjunctionDiagonal(t *testing.T) {
+	for _, a := range Signs {
+		for _, b := range Signs {
+			ab, err := CompareSigns(a, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ba, err := CompareSigns(b, a)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ab.Aspect != ba.Aspect || ab.Score != ba.Score {
+				t.Fatalf("%s/%s is %s %d but %s/%s is %s %d", a, b, ab.Aspect, ab.Score, b, a, ba.Aspect, ba.Score)
+			}
+			if CompatibilityScore(a, b) != CompatibilityScore(b, a) {
+				t.Fatalf("CompatibilityScore(%s, %s) isn't symmetric", a, b)
+			}
+			if a == b && (ab.Aspect != "conjunction" || ab.Score != 80) {
+				t.Fatalf("%s with itself is %s %d, want conjunction 80", a, ab.Aspect, ab.Score)
+			}
+		}
+	}
+}
+
+func TestCompareSignsUnknown(t *testing.T) {
+	if _, err := CompareSigns("Ophiuchus", Leo); !errors.Is(err, ErrUnknownSign) {
+		t.Fatalf("err = %v, want ErrUnknownSign", err)
+	}
+	if _, err := CompareSigns("leo", Leo); !errors.Is(err, ErrUnknownSign) {
+		t.Fatalf("lower-case sign: err = %v, want ErrUnknownSign (parse it first)", err)
+	}
+	if score := CompatibilityScore("", Leo); score != 0.5 {
+		t.Fatalf("score with an unknown sign = %v, want 0.5", score)
+	}
+}
+
+func TestParseZodiacSign(t *testing.T) {
+	tests := []struct {
+		name string
+		want ZodiacSign
+		ok   bool
+	}{
+		{name: "Leo", want: Leo, ok: true},
+		{name: "leo", want: Leo, ok: true},
+		{name: "LEO", want: Leo, ok: true},
+		{name: " sagittarius\n", want: Sagittarius, ok: true},
+		{name: "cApRiCoRn", want: Capricorn, ok: true},
+		{name: "", ok: false},
+		{name: "Ophiuchus", ok: false},
+		{name: "Le o", ok: false},
+	}
+
+	for _, tt := range tests {
+		sign, ok := ParseZodiacSign(tt.name)
+		if sign != tt.want || ok != tt.ok {
+			t.Fatalf("ParseZodiacSign(%q) = %q, %v, want %q, %v", tt.name, sign, ok, tt.want, tt.ok)
+		}
+	}
+}
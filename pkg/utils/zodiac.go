@@ -22,6 +22,12 @@ const (
 	Pisces      ZodiacSign = "Pisces"
 )
 
+// Signs lists the zodiac signs in order, starting with Aries
+var Signs = []ZodiacSign{
+	Aries, Taurus, Gemini, Cancer, Leo, Virgo,
+	Libra, Scorpio, Sagittarius, Capricorn, Aquarius, Pisces,
+}
+
 // CalculateZodiac calculates zodiac sign from date of birth
 // Based on astronomical dates
 func CalculateZodiac(dateOfBirth time.Time) ZodiacSign {
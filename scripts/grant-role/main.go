@@ -0,0 +1,74 @@
+// Command grant-role gives a user a role, or takes it away with -revoke
+// The first admin has to be created this way; after that admins manage roles through the admin API.
+//
+//	go run ./scripts/grant-role -email admin@example.com -role admin
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"zodiac-ai-backend/pkg/config"
+	"zodiac-ai-backend/pkg/database"
+	"zodiac-ai-backend/pkg/jwt"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func main() {
+	email := flag.String("email", "", "email of the user")
+	role := flag.String("role", jwt.RoleAdmin, "role to grant: admin or moderator")
+	revoke := flag.Bool("revoke", false, "remove the role instead of granting it")
+	flag.Parse()
+
+	if *email == "" {
+		log.Fatalf("Usage: grant-role -email <email> [-role admin|moderator] [-revoke]")
+	}
+	if *role != jwt.RoleAdmin && *role != jwt.RoleModerator {
+		log.Fatalf("Unknown role %q, expected admin or moderator", *role)
+	}
+
+	// Load config
+	cfg := config.LoadConfig()
+
+	// Connect to MongoDB
+	_, err := database.Connect(database.MongoConfig{
+		URI:      cfg.MongoURI,
+		Database: cfg.MongoDatabase,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer database.Disconnect()
+
+	db := database.GetDatabase(cfg.MongoDatabase)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	userRepo := repositories.NewUserRepository(db)
+
+	user, err := userRepo.FindByEmail(ctx, *email)
+	if err != nil {
+		log.Fatalf("Failed to find user %s: %v", *email, err)
+	}
+
+	roles := []string{}
+	for _, r := range user.Roles {
+		if r != *role {
+			roles = append(roles, r)
+		}
+	}
+	if !*revoke {
+		roles = append(roles, *role)
+	}
+
+	if err := userRepo.Update(ctx, user.ID, bson.M{"roles": roles}); err != nil {
+		log.Fatalf("Failed to update roles: %v", err)
+	}
+
+	// Roles reach the access token on the next refresh or login
+	log.Printf("✅ Roles of %s: %v", *email, roles)
+}
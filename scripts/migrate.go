@@ -8,8 +8,10 @@ import (
 
 	"zodiac-ai-backend/pkg/config"
 	"zodiac-ai-backend/pkg/database"
+	"zodiac-ai-backend/services/auth-service/repositories"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -43,10 +45,38 @@ func main() {
 		log.Fatalf("Failed to migrate friendships: %v", err)
 	}
 
+	if err := migrateFriendRequests(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate friend requests: %v", err)
+	}
+
+	if err := migrateBlocks(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate blocks: %v", err)
+	}
+
 	if err := migrateRefreshTokens(ctx, db); err != nil {
 		log.Fatalf("Failed to migrate refresh tokens: %v", err)
 	}
 
+	if err := migrateUserTokens(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate user tokens: %v", err)
+	}
+
+	if err := migrateOIDCStates(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate OIDC states: %v", err)
+	}
+
+	if err := migrateLoginAttempts(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate login attempts: %v", err)
+	}
+
+	if err := migrateAccountDeletions(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate account deletions: %v", err)
+	}
+
+	if err := migrateAuditLogs(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate audit logs: %v", err)
+	}
+
 	if err := migrateMessages(ctx, db); err != nil {
 		log.Fatalf("Failed to migrate messages: %v", err)
 	}
@@ -59,6 +89,26 @@ func main() {
 		log.Fatalf("Failed to migrate room messages: %v", err)
 	}
 
+	if err := migrateRoomMembers(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate room members: %v", err)
+	}
+
+	if err := migrateRoomEvents(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate room events: %v", err)
+	}
+
+	if err := migrateRoomPresence(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate room presence: %v", err)
+	}
+
+	if err := migrateConversations(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate conversations: %v", err)
+	}
+
+	if err := migrateDirectMessages(ctx, db); err != nil {
+		log.Fatalf("Failed to migrate direct messages: %v", err)
+	}
+
 	if err := migratePosts(ctx, db); err != nil {
 		log.Fatalf("Failed to migrate posts: %v", err)
 	}
@@ -87,6 +137,30 @@ func migrateUsers(ctx context.Context, db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "zodiac_sign", Value: 1}},
 		},
+		{
+			// One user per provider account (social login)
+			Keys: bson.D{
+				{Key: "identities.provider", Value: 1},
+				{Key: "identities.subject", Value: 1},
+			},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"identities.subject": bson.M{"$exists": true}}),
+		},
+		{
+			// User search by display name prefix (scanned in the index, not the documents)
+			Keys: bson.D{{Key: "display_name", Value: 1}},
+		},
+		{
+			// Admin user list filtered by role (only the few users with roles are indexed)
+			Keys:    bson.D{{Key: "roles", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// Admin user list of suspended users, suspension checks
+			Keys:    bson.D{{Key: "suspended", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
 	}
 
 	_, err := coll.Indexes().CreateMany(ctx, indexes)
@@ -125,24 +199,135 @@ func migrateFriendships(ctx context.Context, db *mongo.Database) error {
 	return nil
 }
 
-// migrateRefreshTokens creates indexes for refresh_tokens collection
+// migrateFriendRequests creates indexes for friend_requests collection
+func migrateFriendRequests(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating friend_requests collection...")
+	coll := db.Collection("friend_requests")
+
+	// Re-sent requests used to create a new document each time; keep only the latest one
+	// per pair so the unique index can be built
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "updated_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "sender_id", Value: "$sender_id"}, {Key: "receiver_id", Value: "$receiver_id"}}},
+			{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "ids.1", Value: bson.D{{Key: "$exists", Value: true}}}}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find duplicate friend requests: %w", err)
+	}
+
+	var duplicates []struct {
+		IDs bson.A `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return fmt.Errorf("failed to find duplicate friend requests: %w", err)
+	}
+	for _, group := range duplicates {
+		if _, err := coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": group.IDs[1:]}}); err != nil {
+			return fmt.Errorf("failed to remove duplicate friend requests: %w", err)
+		}
+	}
+	if len(duplicates) > 0 {
+		log.Printf("Removed duplicate friend requests for %d pairs", len(duplicates))
+	}
+
+	indexes := []mongo.IndexModel{
+		{
+			// One request per sender and receiver; sending again resets it
+			Keys: bson.D{
+				{Key: "sender_id", Value: 1},
+				{Key: "receiver_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Incoming requests
+			Keys: bson.D{
+				{Key: "receiver_id", Value: 1},
+				{Key: "status", Value: 1},
+			},
+		},
+	}
+
+	_, err = coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create friend_requests indexes: %w", err)
+	}
+
+	log.Println("✅ Friend requests collection migrated")
+	return nil
+}
+
+// migrateBlocks creates indexes for blocks collection
+func migrateBlocks(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating blocks collection...")
+	coll := db.Collection("blocks")
+
+	indexes := []mongo.IndexModel{
+		{
+			// One block per blocker and blocked user; also lists a user's blocks
+			Keys: bson.D{
+				{Key: "blocker_id", Value: 1},
+				{Key: "blocked_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Who blocked a user
+			Keys: bson.D{{Key: "blocked_id", Value: 1}},
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create blocks indexes: %w", err)
+	}
+
+	log.Println("✅ Blocks collection migrated")
+	return nil
+}
+
+// migrateRefreshTokens hashes legacy plaintext tokens and creates indexes for refresh_tokens collection
 func migrateRefreshTokens(ctx context.Context, db *mongo.Database) error {
 	log.Println("Migrating refresh_tokens collection...")
 	coll := db.Collection("refresh_tokens")
 
+	hashed, err := hashLegacyRefreshTokens(ctx, coll)
+	if err != nil {
+		return fmt.Errorf("failed to hash legacy refresh tokens: %w", err)
+	}
+	if hashed > 0 {
+		log.Printf("   Hashed %d legacy plaintext refresh tokens", hashed)
+	}
+
+	// No document has a plaintext token anymore. Ignore the errors if the indexes don't exist.
+	_, _ = coll.Indexes().DropOne(ctx, "token_1")
+	_, _ = coll.Indexes().DropOne(ctx, "token_legacy")
+
 	indexes := []mongo.IndexModel{
 		{
-			Keys:    bson.D{{Key: "token", Value: 1}},
-			Options: options.Index().SetUnique(true),
+			Keys: bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"token_hash": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{{Key: "family_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
 		},
 		{
 			// TTL index: auto-delete after 30 days (2592000 seconds)
+			// Rotated tokens are kept until then so their reuse can still be detected
 			Keys:    bson.D{{Key: "created_at", Value: 1}},
 			Options: options.Index().SetExpireAfterSeconds(2592000),
 		},
 	}
 
-	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	_, err = coll.Indexes().CreateMany(ctx, indexes)
 	if err != nil {
 		return fmt.Errorf("failed to create refresh_tokens indexes: %w", err)
 	}
@@ -151,6 +336,199 @@ func migrateRefreshTokens(ctx context.Context, db *mongo.Database) error {
 	return nil
 }
 
+// hashLegacyRefreshTokens replaces the plaintext token of documents stored before tokens were
+// hashed with its hash, so no live refresh token is kept in the clear. Returns how many it hashed.
+func hashLegacyRefreshTokens(ctx context.Context, coll *mongo.Collection) (int, error) {
+	cursor, err := coll.Find(ctx,
+		bson.M{"token": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"_id": 1, "token": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	hashed := 0
+	var writes []mongo.WriteModel
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		result, err := coll.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return err
+		}
+		hashed += int(result.ModifiedCount)
+		writes = writes[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var legacy struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Token string             `bson:"token"`
+		}
+		if err := cursor.Decode(&legacy); err != nil {
+			return hashed, err
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": legacy.ID}).
+			SetUpdate(bson.M{
+				"$set":   bson.M{"token_hash": repositories.HashToken(legacy.Token)},
+				"$unset": bson.M{"token": ""},
+			}))
+		if len(writes) == 500 {
+			if err := flush(); err != nil {
+				return hashed, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return hashed, err
+	}
+
+	return hashed, flush()
+}
+
+// migrateUserTokens creates indexes for user_tokens collection (password reset, email verification)
+func migrateUserTokens(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating user_tokens collection...")
+	coll := db.Collection("user_tokens")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "purpose", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			// TTL index: delete as soon as the token expires
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create user_tokens indexes: %w", err)
+	}
+
+	log.Println("✅ User tokens collection migrated")
+	return nil
+}
+
+// migrateOIDCStates creates indexes for oidc_states collection (pending social logins)
+func migrateOIDCStates(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating oidc_states collection...")
+	coll := db.Collection("oidc_states")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "state_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// TTL index: abandoned logins are removed once expired
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create oidc_states indexes: %w", err)
+	}
+
+	log.Println("✅ OIDC states collection migrated")
+	return nil
+}
+
+// migrateLoginAttempts creates indexes for login_attempts collection
+func migrateLoginAttempts(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating login_attempts collection...")
+	coll := db.Collection("login_attempts")
+
+	indexes := []mongo.IndexModel{
+		{
+			// TTL index: forget a key (and its lockout count) once expires_at passes
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create login_attempts indexes: %w", err)
+	}
+
+	log.Println("✅ Login attempts collection migrated")
+	return nil
+}
+
+// migrateAccountDeletions creates indexes for account_deletions collection
+func migrateAccountDeletions(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating account_deletions collection...")
+	coll := db.Collection("account_deletions")
+
+	indexes := []mongo.IndexModel{
+		{
+			// One job per account: repeated deletion requests are idempotent
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Workers claim pending jobs whose lease expired
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "lease_until", Value: 1},
+			},
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create account_deletions indexes: %w", err)
+	}
+
+	log.Println("✅ Account deletions collection migrated")
+	return nil
+}
+
+// migrateAuditLogs creates indexes for audit_logs collection
+func migrateAuditLogs(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating audit_logs collection...")
+	coll := db.Collection("audit_logs")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "event", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create audit_logs indexes: %w", err)
+	}
+
+	log.Println("✅ Audit logs collection migrated")
+	return nil
+}
+
 // migrateMessages creates indexes for messages collection
 func migrateMessages(ctx context.Context, db *mongo.Database) error {
 	log.Println("Migrating messages collection...")
@@ -224,6 +602,140 @@ func migrateRoomMessages(ctx context.Context, db *mongo.Database) error {
 	return nil
 }
 
+// migrateRoomMembers creates indexes for room_members collection
+func migrateRoomMembers(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating room_members collection...")
+	coll := db.Collection("room_members")
+
+	indexes := []mongo.IndexModel{
+		{
+			// One membership (or ban) per user per room
+			Keys: bson.D{
+				{Key: "room_id", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Member / ban lists with cursor pagination
+			Keys: bson.D{
+				{Key: "room_id", Value: 1},
+				{Key: "banned", Value: 1},
+				{Key: "_id", Value: -1},
+			},
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create room_members indexes: %w", err)
+	}
+
+	log.Println("✅ Room members collection migrated")
+	return nil
+}
+
+// migrateRoomEvents creates indexes for room_events collection (WebSocket backplane)
+func migrateRoomEvents(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating room_events collection...")
+	coll := db.Collection("room_events")
+
+	indexes := []mongo.IndexModel{
+		{
+			// TTL index: ephemeral frames only need to live until replicas see them
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(60),
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create room_events indexes: %w", err)
+	}
+
+	log.Println("✅ Room events collection migrated (TTL: 60 seconds)")
+	return nil
+}
+
+// migrateRoomPresence creates indexes for room_presence collection (WebSocket backplane)
+func migrateRoomPresence(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating room_presence collection...")
+	coll := db.Collection("room_presence")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "room_id", Value: 1}},
+		},
+		{
+			// TTL index: drop counts from replicas that stopped reporting
+			Keys:    bson.D{{Key: "updated_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(120),
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create room_presence indexes: %w", err)
+	}
+
+	log.Println("✅ Room presence collection migrated (TTL: 120 seconds)")
+	return nil
+}
+
+// migrateConversations creates indexes for conversations collection (direct messages)
+func migrateConversations(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating conversations collection...")
+	coll := db.Collection("conversations")
+
+	indexes := []mongo.IndexModel{
+		{
+			// One conversation per pair of users
+			Keys:    bson.D{{Key: "participant_key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Conversation list, most recently active first
+			Keys: bson.D{
+				{Key: "participants", Value: 1},
+				{Key: "updated_at", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create conversations indexes: %w", err)
+	}
+
+	log.Println("✅ Conversations collection migrated")
+	return nil
+}
+
+// migrateDirectMessages creates indexes for direct_messages collection
+func migrateDirectMessages(ctx context.Context, db *mongo.Database) error {
+	log.Println("Migrating direct_messages collection...")
+	coll := db.Collection("direct_messages")
+
+	indexes := []mongo.IndexModel{
+		{
+			// Conversation history with cursor pagination
+			Keys: bson.D{
+				{Key: "conversation_id", Value: 1},
+				{Key: "_id", Value: -1},
+			},
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create direct_messages indexes: %w", err)
+	}
+
+	log.Println("✅ Direct messages collection migrated")
+	return nil
+}
+
 // migratePosts creates indexes for posts collection
 func migratePosts(ctx context.Context, db *mongo.Database) error {
 	log.Println("Migrating posts collection...")
@@ -243,6 +755,10 @@ func migratePosts(ctx context.Context, db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "status", Value: 1}},
 		},
+		{
+			// A user's posts (data export, account deletion)
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
 	}
 
 	_, err := coll.Indexes().CreateMany(ctx, indexes)
@@ -268,6 +784,10 @@ func migrateLikes(ctx context.Context, db *mongo.Database) error {
 			},
 			Options: options.Index().SetUnique(true),
 		},
+		{
+			// A user's likes (data export, account deletion)
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
 	}
 
 	_, err := coll.Indexes().CreateMany(ctx, indexes)
@@ -291,6 +811,10 @@ func migrateComments(ctx context.Context, db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "parent_id", Value: 1}},
 		},
+		{
+			// A user's comments (data export, account deletion)
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
 	}
 
 	_, err := coll.Indexes().CreateMany(ctx, indexes)
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"zodiac-ai-backend/pkg/database/mongotest"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMigrateRefreshTokensHashesLegacyTokens(t *testing.T) {
+	db := mongotest.NewDatabase(t)
+	ctx := context.Background()
+	coll := db.Collection("refresh_tokens")
+
+	// Two legacy plaintext tokens and one already hashed
+	_, err := coll.InsertMany(ctx, []interface{}{
+		bson.M{"token": "legacy-1"},
+		bson.M{"token": "legacy-2"},
+		bson.M{"token_hash": repositories.HashToken("current")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateRefreshTokens(ctx, db); err != nil {
+		t.Fatalf("migrateRefreshTokens: %v", err)
+	}
+
+	plaintext, err := coll.CountDocuments(ctx, bson.M{"token": bson.M{"$exists": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plaintext != 0 {
+		t.Fatalf("%d documents still hold a plaintext token", plaintext)
+	}
+
+	repo := repositories.NewRefreshTokenRepository(db)
+	for _, token := range []string{"legacy-1", "legacy-2", "current"} {
+		if _, err := repo.FindByToken(ctx, token); err != nil {
+			t.Fatalf("FindByToken(%q) after migration: %v", token, err)
+		}
+	}
+
+	indexes, err := coll.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, index := range indexes {
+		if index.Name == "token_legacy" || index.Name == "token_1" {
+			t.Fatalf("plaintext token index %s still exists", index.Name)
+		}
+	}
+
+	// Running it again changes nothing
+	if err := migrateRefreshTokens(ctx, db); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+}
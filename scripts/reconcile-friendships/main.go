@@ -0,0 +1,482 @@
+// Command reconcile-friendships checks the denormalized friendship graph and repairs it:
+//   - asymmetric edges: a friend_ids entry without its reverse is completed if the users have an
+//     accepted request, and removed otherwise (also edges to deleted users and between blocked users)
+//   - stale pending entries: pending_sent / pending_received must match the pending friend_requests;
+//     pending requests between friends, blocked or deleted users are closed
+//   - stale accepted requests: an accepted request between users who are no longer friends is
+//     marked unfriended (unfriending does this since UNFRIENDED exists; older data may lack it)
+//   - wrong friends_count values, recounted from the repaired friend lists
+//
+// With -dry-run it only reports what it would change. Repairs are computed from one snapshot,
+// so run it while friendship traffic is low.
+//
+//	go run ./scripts/reconcile-friendships -dry-run
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"zodiac-ai-backend/pkg/config"
+	"zodiac-ai-backend/pkg/database"
+	"zodiac-ai-backend/services/auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pair identifies two users in a fixed order
+type pair [2]primitive.ObjectID
+
+func pairOf(a, b primitive.ObjectID) pair {
+	if a.Hex() > b.Hex() {
+		a, b = b, a
+	}
+	return pair{a, b}
+}
+
+// reconciler holds the snapshot being repaired
+type reconciler struct {
+	db     *mongo.Database
+	dryRun bool
+
+	friendsCount map[primitive.ObjectID]int                // users._id -> friends_count
+	graph        map[primitive.ObjectID]*models.Friendship // friendships by user_id
+	pending      []*models.FriendRequest                   // status PENDING
+	blocked      map[pair]bool                             // blocks, either way
+	fixes        map[string]int                            // repairs per kind, for the report
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report the repairs without writing them")
+	flag.Parse()
+
+	// Load config
+	cfg := config.LoadConfig()
+
+	// Connect to MongoDB
+	_, err := database.Connect(database.MongoConfig{
+		URI:      cfg.MongoURI,
+		Database: cfg.MongoDatabase,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer database.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	r := &reconciler{
+		db:     database.GetDatabase(cfg.MongoDatabase),
+		dryRun: *dryRun,
+		fixes:  make(map[string]int),
+	}
+
+	if err := r.run(ctx); err != nil {
+		log.Fatalf("Failed to reconcile friendships: %v", err)
+	}
+}
+
+// run loads the snapshot, repairs it phase by phase and reports the repairs
+func (r *reconciler) run(ctx context.Context) error {
+	if err := r.load(ctx); err != nil {
+		return fmt.Errorf("failed to load friendship data: %w", err)
+	}
+	log.Printf("Loaded %d users, %d friendship documents, %d pending requests, %d blocks",
+		len(r.friendsCount), len(r.graph), len(r.pending), len(r.blocked))
+
+	if err := r.reconcileEdges(ctx); err != nil {
+		return fmt.Errorf("failed to reconcile friend lists: %w", err)
+	}
+	if err := r.reconcilePending(ctx); err != nil {
+		return fmt.Errorf("failed to reconcile pending requests: %w", err)
+	}
+	if err := r.reconcileAccepted(ctx); err != nil {
+		return fmt.Errorf("failed to reconcile accepted requests: %w", err)
+	}
+	if err := r.reconcileCounts(ctx); err != nil {
+		return fmt.Errorf("failed to reconcile friends counts: %w", err)
+	}
+
+	r.report()
+	return nil
+}
+
+// load reads users, friendships, pending requests and blocks
+func (r *reconciler) load(ctx context.Context) error {
+	r.friendsCount = make(map[primitive.ObjectID]int)
+	cursor, err := r.db.Collection("users").Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"_id": 1, "friends_count": 1}))
+	if err != nil {
+		return err
+	}
+	for cursor.Next(ctx) {
+		var user struct {
+			ID           primitive.ObjectID `bson:"_id"`
+			FriendsCount int                `bson:"friends_count"`
+		}
+		if err := cursor.Decode(&user); err != nil {
+			cursor.Close(ctx)
+			return err
+		}
+		r.friendsCount[user.ID] = user.FriendsCount
+	}
+	if err := cursor.Close(ctx); err != nil {
+		return err
+	}
+
+	r.graph = make(map[primitive.ObjectID]*models.Friendship)
+	var friendships []*models.Friendship
+	if err := r.findAll(ctx, "friendships", bson.M{}, &friendships); err != nil {
+		return err
+	}
+	for _, friendship := range friendships {
+		r.graph[friendship.UserID] = friendship
+	}
+
+	if err := r.findAll(ctx, "friend_requests", bson.M{"status": models.StatusPending}, &r.pending); err != nil {
+		return err
+	}
+
+	r.blocked = make(map[pair]bool)
+	var blocks []*models.Block
+	if err := r.findAll(ctx, "blocks", bson.M{}, &blocks); err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		r.blocked[pairOf(block.BlockerID, block.BlockedID)] = true
+	}
+
+	return nil
+}
+
+// findAll decodes every document of a collection matching filter into results
+func (r *reconciler) findAll(ctx context.Context, collection string, filter bson.M, results interface{}) error {
+	cursor, err := r.db.Collection(collection).Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, results)
+}
+
+// reconcileEdges makes every friendship symmetric and removes edges that can't exist
+func (r *reconciler) reconcileEdges(ctx context.Context) error {
+	for _, userID := range r.userIDs() {
+		friendship := r.graph[userID]
+		for _, friendID := range append([]primitive.ObjectID(nil), friendship.FriendIDs...) {
+			_, userExists := r.friendsCount[userID]
+			_, friendExists := r.friendsCount[friendID]
+
+			switch {
+			case !userExists || !friendExists:
+				if err := r.removeEdge(ctx, userID, friendID, "edge to a deleted user"); err != nil {
+					return err
+				}
+
+			case r.blocked[pairOf(userID, friendID)]:
+				if err := r.removeEdge(ctx, userID, friendID, "friends despite a block"); err != nil {
+					return err
+				}
+				if err := r.removeEdge(ctx, friendID, userID, "friends despite a block"); err != nil {
+					return err
+				}
+
+			case !r.hasEdge(friendID, userID):
+				accepted, err := r.acceptedBetween(ctx, userID, friendID)
+				if err != nil {
+					return err
+				}
+				if accepted {
+					err = r.addEdge(ctx, friendID, userID, "missing reverse edge")
+				} else {
+					err = r.removeEdge(ctx, userID, friendID, "one-sided edge")
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// reconcilePending closes pending requests that can't be answered and makes the
+// pending lists match the remaining ones
+func (r *reconciler) reconcilePending(ctx context.Context) error {
+	sent := make(map[primitive.ObjectID]map[primitive.ObjectID]bool)
+	received := make(map[primitive.ObjectID]map[primitive.ObjectID]bool)
+
+	for _, request := range r.pending {
+		_, senderExists := r.friendsCount[request.SenderID]
+		_, receiverExists := r.friendsCount[request.ReceiverID]
+
+		var status models.FriendshipStatus
+		var reason string
+		switch {
+		case !senderExists || !receiverExists:
+			status, reason = models.StatusCancelled, "pending request of a deleted user"
+		case r.blocked[pairOf(request.SenderID, request.ReceiverID)]:
+			status, reason = models.StatusCancelled, "pending request between blocked users"
+		case r.hasEdge(request.SenderID, request.ReceiverID):
+			status, reason = models.StatusAccepted, "pending request between friends"
+		}
+
+		if status != "" {
+			if err := r.closeRequest(ctx, request, status, reason); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if sent[request.SenderID] == nil {
+			sent[request.SenderID] = make(map[primitive.ObjectID]bool)
+		}
+		sent[request.SenderID][request.ReceiverID] = true
+		if received[request.ReceiverID] == nil {
+			received[request.ReceiverID] = make(map[primitive.ObjectID]bool)
+		}
+		received[request.ReceiverID][request.SenderID] = true
+	}
+
+	for _, userID := range r.userIDs() {
+		if err := r.syncPending(ctx, userID, "pending_sent", r.graph[userID].PendingSent, sent[userID]); err != nil {
+			return err
+		}
+		if err := r.syncPending(ctx, userID, "pending_received", r.graph[userID].PendingReceived, received[userID]); err != nil {
+			return err
+		}
+	}
+
+	// Pending requests of users without a friendship document
+	for userID, expected := range sent {
+		if _, ok := r.graph[userID]; !ok {
+			if err := r.syncPending(ctx, userID, "pending_sent", nil, expected); err != nil {
+				return err
+			}
+		}
+	}
+	for userID, expected := range received {
+		if _, ok := r.graph[userID]; !ok {
+			if err := r.syncPending(ctx, userID, "pending_received", nil, expected); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// reconcileAccepted marks accepted requests between users who aren't friends (any more) unfriended,
+// so a later one-sided edge isn't completed from them
+func (r *reconciler) reconcileAccepted(ctx context.Context) error {
+	cursor, err := r.db.Collection("friend_requests").Find(ctx, bson.M{"status": models.StatusAccepted})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var request models.FriendRequest
+		if err := cursor.Decode(&request); err != nil {
+			return err
+		}
+		if r.hasEdge(request.SenderID, request.ReceiverID) || r.hasEdge(request.ReceiverID, request.SenderID) {
+			continue
+		}
+		if err := r.closeRequest(ctx, &request, models.StatusUnfriended, "accepted request between non-friends"); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// syncPending makes one pending list of a user equal to expected
+func (r *reconciler) syncPending(ctx context.Context, userID primitive.ObjectID, field string, have []primitive.ObjectID, expected map[primitive.ObjectID]bool) error {
+	present := make(map[primitive.ObjectID]bool, len(have))
+	for _, id := range have {
+		present[id] = true
+		if !expected[id] {
+			r.record("stale "+field+" entry", "%s: remove %s from %s", userID.Hex(), id.Hex(), field)
+			if err := r.updateFriendship(ctx, userID, bson.M{"$pull": bson.M{field: id}}); err != nil {
+				return err
+			}
+		}
+	}
+
+	for id := range expected {
+		if !present[id] {
+			r.record("missing "+field+" entry", "%s: add %s to %s", userID.Hex(), id.Hex(), field)
+			if err := r.updateFriendship(ctx, userID, bson.M{"$addToSet": bson.M{field: id}}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reconcileCounts sets friends_count to the length of each user's repaired friend list
+func (r *reconciler) reconcileCounts(ctx context.Context) error {
+	for userID, count := range r.friendsCount {
+		want := 0
+		if friendship, ok := r.graph[userID]; ok {
+			want = len(friendship.FriendIDs)
+		}
+		if count == want {
+			continue
+		}
+
+		r.record("wrong friends_count", "%s: friends_count %d, should be %d", userID.Hex(), count, want)
+		if r.dryRun {
+			continue
+		}
+		_, err := r.db.Collection("users").UpdateOne(ctx,
+			bson.M{"_id": userID},
+			bson.M{"$set": bson.M{"friends_count": want}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasEdge reports whether friendID is in userID's friend list (as repaired so far)
+func (r *reconciler) hasEdge(userID, friendID primitive.ObjectID) bool {
+	friendship, ok := r.graph[userID]
+	if !ok {
+		return false
+	}
+	for _, id := range friendship.FriendIDs {
+		if id == friendID {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedBetween reports whether either user accepted a request from the other
+func (r *reconciler) acceptedBetween(ctx context.Context, userID, friendID primitive.ObjectID) (bool, error) {
+	count, err := r.db.Collection("friend_requests").CountDocuments(ctx, bson.M{
+		"status": models.StatusAccepted,
+		"$or": bson.A{
+			bson.M{"sender_id": userID, "receiver_id": friendID},
+			bson.M{"sender_id": friendID, "receiver_id": userID},
+		},
+	}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// removeEdge removes friendID from userID's friend list
+func (r *reconciler) removeEdge(ctx context.Context, userID, friendID primitive.ObjectID, reason string) error {
+	if !r.hasEdge(userID, friendID) {
+		return nil
+	}
+
+	friendship := r.graph[userID]
+	kept := make([]primitive.ObjectID, 0, len(friendship.FriendIDs))
+	for _, id := range friendship.FriendIDs {
+		if id != friendID {
+			kept = append(kept, id)
+		}
+	}
+	friendship.FriendIDs = kept
+
+	r.record(reason, "%s: remove friend %s", userID.Hex(), friendID.Hex())
+	return r.updateFriendship(ctx, userID, bson.M{"$pull": bson.M{"friend_ids": friendID}})
+}
+
+// addEdge adds friendID to userID's friend list
+func (r *reconciler) addEdge(ctx context.Context, userID, friendID primitive.ObjectID, reason string) error {
+	friendship, ok := r.graph[userID]
+	if !ok {
+		friendship = &models.Friendship{UserID: userID}
+		r.graph[userID] = friendship
+	}
+	friendship.FriendIDs = append(friendship.FriendIDs, friendID)
+
+	r.record(reason, "%s: add friend %s", userID.Hex(), friendID.Hex())
+	return r.updateFriendship(ctx, userID, bson.M{"$addToSet": bson.M{"friend_ids": friendID}})
+}
+
+// closeRequest moves a request to status, unless its status changed since it was read
+func (r *reconciler) closeRequest(ctx context.Context, request *models.FriendRequest, status models.FriendshipStatus, reason string) error {
+	r.record(reason, "request %s (%s -> %s): mark %s", request.ID.Hex(), request.SenderID.Hex(), request.ReceiverID.Hex(), status)
+	if r.dryRun {
+		return nil
+	}
+
+	_, err := r.db.Collection("friend_requests").UpdateOne(ctx,
+		bson.M{"_id": request.ID, "status": request.Status},
+		bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}},
+	)
+	return err
+}
+
+// updateFriendship applies an update to a user's friendship document, creating it if needed
+func (r *reconciler) updateFriendship(ctx context.Context, userID primitive.ObjectID, update bson.M) error {
+	if r.dryRun {
+		return nil
+	}
+
+	update["$set"] = bson.M{"updated_at": time.Now()}
+	_, err := r.db.Collection("friendships").UpdateOne(ctx,
+		bson.M{"user_id": userID},
+		update,
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// userIDs lists the users with a friendship document, in a stable order
+func (r *reconciler) userIDs() []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(r.graph))
+	for id := range r.graph {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Hex() < ids[j].Hex() })
+	return ids
+}
+
+// record logs a repair and counts it for the report
+func (r *reconciler) record(kind, format string, args ...interface{}) {
+	r.fixes[kind]++
+
+	prefix := "fix: "
+	if r.dryRun {
+		prefix = "would fix: "
+	}
+	log.Printf(prefix+format, args...)
+}
+
+// report prints the number of repairs per kind
+func (r *reconciler) report() {
+	if len(r.fixes) == 0 {
+		log.Println("✅ Friendship graph is consistent")
+		return
+	}
+
+	kinds := make([]string, 0, len(r.fixes))
+	total := 0
+	for kind, count := range r.fixes {
+		kinds = append(kinds, kind)
+		total += count
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		log.Printf("  %-40s %d", kind, r.fixes[kind])
+	}
+
+	if r.dryRun {
+		log.Printf("Dry run: %d repairs found, nothing written", total)
+		return
+	}
+	log.Printf("✅ %d repairs applied", total)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"zodiac-ai-backend/pkg/database/mongotest"
+	"zodiac-ai-backend/services/auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// seedInconsistentGraph writes a friendship graph with one of each kind of drift and returns the user IDs
+//   - a lists b as a friend, b doesn't list a and they have no accepted request
+//   - c and d are friends despite a block
+//   - a's pending request to c is missing from both pending lists
+//   - b's request to d is accepted although they aren't friends
+//   - the friends counts of a, c and d are stale
+func seedInconsistentGraph(t *testing.T, db *mongo.Database) (a, b, c, d primitive.ObjectID) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+
+	a, b, c, d = primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	counts := map[primitive.ObjectID]int{a: 1, b: 0, c: 1, d: 1}
+	friends := map[primitive.ObjectID][]primitive.ObjectID{a: {b}, b: {}, c: {d}, d: {c}}
+
+	for _, id := range []primitive.ObjectID{a, b, c, d} {
+		if _, err := db.Collection("users").InsertOne(ctx, bson.M{"_id": id, "friends_count": counts[id]}); err != nil {
+			t.Fatal(err)
+		}
+		_, err := db.Collection("friendships").InsertOne(ctx, &models.Friendship{
+			UserID:          id,
+			FriendIDs:       friends[id],
+			PendingSent:     []primitive.ObjectID{},
+			PendingReceived: []primitive.ObjectID{},
+			UpdatedAt:       now,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := db.Collection("friend_requests").InsertMany(ctx, []interface{}{
+		&models.FriendRequest{SenderID: a, ReceiverID: c, Status: models.StatusPending, CreatedAt: now, UpdatedAt: now},
+		&models.FriendRequest{SenderID: b, ReceiverID: d, Status: models.StatusAccepted, CreatedAt: now, UpdatedAt: now},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Collection("blocks").InsertOne(ctx, &models.Block{BlockerID: c, BlockedID: d, CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	return a, b, c, d
+}
+
+// snapshot returns every document of the collections the reconciler writes to
+func snapshot(t *testing.T, db *mongo.Database) map[string][]bson.M {
+	t.Helper()
+
+	docs := make(map[string][]bson.M)
+	for _, name := range []string{"users", "friendships", "friend_requests", "blocks"} {
+		cursor, err := db.Collection(name).Find(context.Background(), bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var results []bson.M
+		if err := cursor.All(context.Background(), &results); err != nil {
+			t.Fatal(err)
+		}
+		docs[name] = results
+	}
+	return docs
+}
+
+// reconcile runs the reconciler and returns the number of repairs it found
+func reconcile(t *testing.T, db *mongo.Database, dryRun bool) int {
+	t.Helper()
+
+	r := &reconciler{db: db, dryRun: dryRun, fixes: make(map[string]int)}
+	if err := r.run(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	total := 0
+	for _, count := range r.fixes {
+		total += count
+	}
+	return total
+}
+
+func TestDryRunWritesNothing(t *testing.T) {
+	db := mongotest.NewDatabase(t)
+	seedInconsistentGraph(t, db)
+
+	before := snapshot(t, db)
+	if n := reconcile(t, db, true); n == 0 {
+		t.Fatal("dry run found no repairs in an inconsistent graph")
+	}
+	if after := snapshot(t, db); !reflect.DeepEqual(before, after) {
+		t.Fatalf("dry run changed the database:\nbefore %v\nafter  %v", before, after)
+	}
+}
+
+func TestReconcileRepairsGraph(t *testing.T) {
+	db := mongotest.NewDatabase(t)
+	ctx := context.Background()
+	a, b, c, d := seedInconsistentGraph(t, db)
+
+	if n := reconcile(t, db, false); n == 0 {
+		t.Fatal("no repairs applied to an inconsistent graph")
+	}
+
+	friendship := func(id primitive.ObjectID) models.Friendship {
+		var f models.Friendship
+		if err := db.Collection("friendships").FindOne(ctx, bson.M{"user_id": id}).Decode(&f); err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	for _, id := range []primitive.ObjectID{a, b, c, d} {
+		if f := friendship(id); len(f.FriendIDs) != 0 {
+			t.Fatalf("user %s: friend_ids = %v, want none", id.Hex(), f.FriendIDs)
+		}
+
+		var user struct {
+			FriendsCount int `bson:"friends_count"`
+		}
+		if err := db.Collection("users").FindOne(ctx, bson.M{"_id": id}).Decode(&user); err != nil {
+			t.Fatal(err)
+		}
+		if user.FriendsCount != 0 {
+			t.Fatalf("user %s: friends_count %d, want 0", id.Hex(), user.FriendsCount)
+		}
+	}
+
+	if sent := friendship(a).PendingSent; !reflect.DeepEqual(sent, []primitive.ObjectID{c}) {
+		t.Fatalf("pending_sent = %v, want [%s]", sent, c.Hex())
+	}
+	if received := friendship(c).PendingReceived; !reflect.DeepEqual(received, []primitive.ObjectID{a}) {
+		t.Fatalf("pending_received = %v, want [%s]", received, a.Hex())
+	}
+
+	var accepted models.FriendRequest
+	if err := db.Collection("friend_requests").FindOne(ctx, bson.M{"sender_id": b, "receiver_id": d}).Decode(&accepted); err != nil {
+		t.Fatal(err)
+	}
+	if accepted.Status != models.StatusUnfriended {
+		t.Fatalf("request between non-friends: status %s, want UNFRIENDED", accepted.Status)
+	}
+
+	// Repaired: a second run finds nothing to do
+	if n := reconcile(t, db, false); n != 0 {
+		t.Fatalf("second run found %d repairs, want 0", n)
+	}
+}
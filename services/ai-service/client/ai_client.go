@@ -0,0 +1,407 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"zodiac-ai-backend/pkg/circuitbreaker"
+	"zodiac-ai-backend/pkg/ratelimiter"
+)
+
+var (
+	ErrAIUnavailable = errors.New("AI service unavailable")
+	ErrInvalidPrompt = errors.New("invalid prompt")
+)
+
+// AIClient handles AI interactions for the zodiac companion: persona prompts, fallbacks,
+// and the resilience strategy around whichever LLMProvider generates the text
+// Implements retry strategy with exponential backoff, rate limiting, and circuit breaker
+type AIClient struct {
+	provider       LLMProvider
+	maxRetries     int
+	baseDelay      time.Duration
+	rateLimiter    *ratelimiter.RateLimiter
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+// NewAIClient creates a new AI client on top of an LLM provider
+func NewAIClient(provider LLMProvider) *AIClient {
+	log.Printf("📋 Using LLM provider: %s", provider.Name())
+
+	// Initialize rate limiter (10 requests per second)
+	rateLimiter := ratelimiter.NewRateLimiter(10, time.Second)
+	log.Printf("⚡ Rate limiter initialized: 10 req/s")
+
+	// Initialize circuit breaker (5 failures, 60s timeout)
+	circuitBreaker := circuitbreaker.NewCircuitBreaker(circuitbreaker.Config{
+		MaxFailures:     5,
+		ResetTimeout:    60 * time.Second,
+		HalfOpenMaxReqs: 1,
+	})
+	log.Printf("🔌 Circuit breaker initialized: 5 failures threshold, 60s timeout")
+
+	return &AIClient{
+		provider:       provider,
+		maxRetries:     3,
+		baseDelay:      time.Second,
+		rateLimiter:    rateLimiter,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+// GenerateContent generates content with retry strategy, rate limiting, and circuit breaker
+// Retry strategy: 3 attempts with exponential backoff (1s, 2s, 4s)
+// Rate limiting: 10 requests per second
+// Circuit breaker: Opens after 5 consecutive failures
+func (c *AIClient) GenerateContent(ctx context.Context, prompt string) (string, error) {
+	if prompt == "" {
+		return "", ErrInvalidPrompt
+	}
+
+	return c.generate(ctx, promptRequest(prompt))
+}
+
+// generate runs GenerateContent's retry / rate limit / circuit breaker strategy for any request
+func (c *AIClient) generate(ctx context.Context, req *GenerateRequest) (string, error) {
+	// Check circuit breaker state
+	if c.circuitBreaker.IsOpen() {
+		log.Printf("⚠️ Circuit breaker is OPEN - failing fast")
+		return "", ErrAIUnavailable
+	}
+
+	// Wait for rate limiter
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		log.Printf("⚠️ Rate limiter wait cancelled: %v", err)
+		return "", err
+	}
+
+	var lastErr error
+	
+	for attempt := 0; attempt < c.maxRetries; attempt++ {
+		if attempt > 0 {
+			// Exponential backoff: 1s, 2s, 4s
+			delay := c.baseDelay * time.Duration(1<<uint(attempt-1))
+			log.Printf("Retry attempt %d after %v delay", attempt+1, delay)
+			time.Sleep(delay)
+		}
+
+		// Execute with circuit breaker
+		var result string
+		err := c.circuitBreaker.Execute(func() error {
+			// Set timeout for this attempt
+			attemptCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+			
+			var apiErr error
+			result, apiErr = c.provider.Generate(attemptCtx, req)
+			return apiErr
+		})
+
+		if err == nil {
+			log.Printf("✅ %s API success (attempt %d, circuit: %s)", 
+				c.provider.Name(), attempt+1, c.circuitBreaker.State())
+			return result, nil
+		}
+
+		lastErr = err
+		log.Printf("❌ %s API error (attempt %d/%d, circuit: %s): %v", 
+			c.provider.Name(), attempt+1, c.maxRetries, c.circuitBreaker.State(), err)
+		
+		// If circuit is open, fail fast
+		if c.circuitBreaker.IsOpen() {
+			log.Printf("⚠️ Circuit breaker opened - stopping retries")
+			break
+		}
+	}
+
+	// All retries failed
+	log.Printf("❌ All %s API retries failed (circuit: %s): %v", 
+		c.provider.Name(), c.circuitBreaker.State(), lastErr)
+	return "", ErrAIUnavailable
+}
+
+// GenerateContentStream generates content and calls onChunk for each text chunk as it arrives
+// Uses the same rate limiter and circuit breaker as GenerateContent but does not retry:
+// chunks already delivered can't be taken back. An error from onChunk stops the stream
+// (client went away) and is returned as-is without counting as an API failure.
+func (c *AIClient) GenerateContentStream(ctx context.Context, prompt string, onChunk func(string) error) (string, error) {
+	if prompt == "" {
+		return "", ErrInvalidPrompt
+	}
+
+	return c.generateStream(ctx, promptRequest(prompt), onChunk)
+}
+
+// generateStream runs GenerateContentStream's strategy for any request
+func (c *AIClient) generateStream(ctx context.Context, req *GenerateRequest, onChunk func(string) error) (string, error) {
+	// Check circuit breaker state
+	if c.circuitBreaker.IsOpen() {
+		log.Printf("⚠️ Circuit breaker is OPEN - failing fast")
+		return "", ErrAIUnavailable
+	}
+
+	// Wait for rate limiter
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		log.Printf("⚠️ Rate limiter wait cancelled: %v", err)
+		return "", err
+	}
+
+	var full strings.Builder
+	var chunkErr error
+
+	err := c.circuitBreaker.Execute(func() error {
+		// Whole stream gets a longer budget than a single GenerateContent attempt
+		streamCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		defer cancel()
+
+		_, apiErr := c.provider.Stream(streamCtx, req, func(text string) error {
+			full.WriteString(text)
+			chunkErr = onChunk(text)
+			return chunkErr
+		})
+		if chunkErr != nil {
+			return nil
+		}
+		return apiErr
+	})
+
+	if chunkErr != nil {
+		log.Printf("⚠️ %s stream stopped by client after %d chars", c.provider.Name(), full.Len())
+		return full.String(), chunkErr
+	}
+
+	if err != nil {
+		log.Printf("❌ %s stream error after %d chars (circuit: %s): %v",
+			c.provider.Name(), full.Len(), c.circuitBreaker.State(), err)
+		return full.String(), ErrAIUnavailable
+	}
+
+	log.Printf("✅ %s stream success (%d chars, circuit: %s)", c.provider.Name(), full.Len(), c.circuitBreaker.State())
+	return full.String(), nil
+}
+
+// GenerateChatResponseStream streams an AI chat response with zodiac persona
+// If the API fails before anything was streamed, the fallback response is sent as a single chunk
+func (c *AIClient) GenerateChatResponseStream(ctx context.Context, req *ChatRequest, onChunk func(string) error) (string, error) {
+	if req.ZodiacSign == "" {
+		log.Printf("⚠️ Empty zodiac sign, using default")
+		req.ZodiacSign = "Gemini" // Default fallback
+	}
+
+	if req.UserMessage == "" {
+		log.Printf("❌ Empty user message received")
+		return "", ErrInvalidPrompt
+	}
+
+	log.Printf("🤖 Streaming AI response for zodiac: %s, history: %d turns, message: %.50s...",
+		req.ZodiacSign, len(req.History), req.UserMessage)
+
+	response, err := c.generateStream(ctx, c.chatRequest(req), onChunk)
+	if err == ErrAIUnavailable && response == "" {
+		log.Printf("⚠️ Using fallback response for zodiac: %s", req.ZodiacSign)
+		fallback := c.getFallbackChatResponse(req.ZodiacSign)
+		return fallback, onChunk(fallback)
+	}
+
+	return response, err
+}
+
+// GenerateChatResponse generates AI chat response with zodiac persona
+// History and summary give the model the conversation so far as user/model turns
+func (c *AIClient) GenerateChatResponse(ctx context.Context, req *ChatRequest) (string, error) {
+	// Validate zodiac sign
+	if req.ZodiacSign == "" {
+		log.Printf("⚠️ Empty zodiac sign, using default")
+		req.ZodiacSign = "Gemini" // Default fallback
+	}
+	
+	// Validate user message
+	if req.UserMessage == "" {
+		log.Printf("❌ Empty user message received")
+		return "", ErrInvalidPrompt
+	}
+	
+	log.Printf("🤖 Generating AI response for zodiac: %s, history: %d turns, message: %.50s...",
+		req.ZodiacSign, len(req.History), req.UserMessage)
+	
+	response, err := c.generate(ctx, c.chatRequest(req))
+	if err != nil {
+		// Log the error for debugging with more details
+		if err == ErrAIUnavailable {
+			log.Printf("❌❌❌ %s API UNAVAILABLE - Check the LLM provider configuration! ❌❌❌", c.provider.Name())
+			log.Printf("⚠️ Using fallback response for zodiac: %s", req.ZodiacSign)
+		} else {
+			log.Printf("⚠️ %s API failed with error: %v", c.provider.Name(), err)
+			log.Printf("⚠️ Using fallback response for zodiac: %s", req.ZodiacSign)
+		}
+		// Fallback response if AI fails
+		return c.getFallbackChatResponse(req.ZodiacSign), nil
+	}
+	
+	log.Printf("✅ %s API success for zodiac: %s, response length: %d chars", c.provider.Name(), req.ZodiacSign, len(response))
+	return response, nil
+}
+
+// SummarizeConversation folds older turns into the rolling conversation summary
+// Unlike chat, there's no fallback: on failure the caller keeps the previous summary
+func (c *AIClient) SummarizeConversation(ctx context.Context, previousSummary string, turns []ChatTurn) (string, error) {
+	if len(turns) == 0 {
+		return previousSummary, nil
+	}
+
+	return c.GenerateContent(ctx, c.buildSummaryPrompt(previousSummary, turns))
+}
+
+// GenerateInsight generates life lesson insight from chat history
+func (c *AIClient) GenerateInsight(ctx context.Context, chatHistory string) (string, error) {
+	prompt := c.buildInsightPrompt(chatHistory)
+	
+	response, err := c.GenerateContent(ctx, prompt)
+	if err != nil {
+		// Fallback insight if AI fails
+		return c.getFallbackInsight(), nil
+	}
+	
+	return response, nil
+}
+
+// CountTokens counts the tokens a chat request would use with the configured provider
+func (c *AIClient) CountTokens(ctx context.Context, req *ChatRequest) (int, error) {
+	return c.provider.CountTokens(ctx, c.chatRequest(req))
+}
+
+// chatRequest builds the provider request: persona as system instruction, then the conversation
+func (c *AIClient) chatRequest(req *ChatRequest) *GenerateRequest {
+	turns := make([]ChatTurn, 0, len(req.History)+1)
+	turns = append(turns, req.History...)
+	turns = append(turns, ChatTurn{Role: RoleUser, Text: req.UserMessage})
+
+	return &GenerateRequest{
+		System: c.buildChatSystemPrompt(req.ZodiacSign, req.Summary),
+		Turns:  turns,
+	}
+}
+
+// buildChatSystemPrompt builds the persona instruction for chat responses
+// The conversation itself is sent as multi-turn request turns (see chatRequest)
+func (c *AIClient) buildChatSystemPrompt(zodiacSign, summary string) string {
+	traits := getZodiacTraits(zodiacSign)
+	
+	prompt := fmt.Sprintf(`Kamu adalah AI companion yang ramah dan bisa diajak ngobrol santai.
+Kamu punya sedikit karakteristik zodiak %s (%s), tapi jangan terlalu berlebihan atau alay.
+
+Respon dengan natural seperti teman yang ngobrol biasa:
+- Jangan terlalu formal atau kaku
+- Jangan terlalu dramatis atau puitis
+- Fokus pada apa yang user tanyakan/ceritakan
+- Kasih respon yang relevan dengan pesan mereka, ingat apa yang sudah diceritakan sebelumnya
+- Boleh santai tapi tetap supportive
+
+Respon dalam bahasa Indonesia yang natural dan casual (max 100 kata).`, 
+		zodiacSign, traits)
+
+	if summary != "" {
+		prompt += "\n\nRingkasan percakapan sebelumnya:\n" + summary
+	}
+
+	return prompt
+}
+
+// buildSummaryPrompt builds prompt for rolling conversation summary
+func (c *AIClient) buildSummaryPrompt(previousSummary string, turns []ChatTurn) string {
+	if previousSummary == "" {
+		previousSummary = "(belum ada)"
+	}
+
+	return fmt.Sprintf(`Perbarui ringkasan percakapan antara user dan AI companion berikut.
+
+Ringkasan sebelumnya:
+%s
+
+Percakapan lanjutan:
+%s
+
+Tulis ringkasan baru (max 150 kata) dalam bahasa Indonesia yang menyimpan fakta penting tentang user,
+topik yang dibahas, dan hal yang masih terbuka. Jawab hanya dengan ringkasannya.`,
+		previousSummary, formatTurns(turns))
+}
+
+// buildInsightPrompt builds prompt for insight generation
+func (c *AIClient) buildInsightPrompt(chatHistory string) string {
+	return fmt.Sprintf(`Analyze this conversation and extract a profound life lesson or insight.
+Create a short, inspirational message (max 200 words) that could help others facing similar situations.
+
+Conversation:
+%s
+
+Generate a wisdom-filled insight that:
+1. Identifies the core emotional theme
+2. Offers a universal life lesson
+3. Provides hope and encouragement
+4. Is relatable to others
+
+Format: A single paragraph of wisdom in Bahasa Indonesia. Make it profound and shareable.`, 
+		chatHistory)
+}
+
+// getFallbackChatResponse returns fallback response if AI fails
+func (c *AIClient) getFallbackChatResponse(zodiacSign string) string {
+	fallbacks := map[string]string{
+		"Aries":       "Halo! Maaf nih, lagi ada gangguan sebentar. Tapi aku di sini kok, siap dengerin kamu.",
+		"Taurus":      "Hai! Ada yang bisa aku bantu? Cerita aja, aku dengerin.",
+		"Gemini":      "Halo! Gimana kabarnya? Ada yang mau diobrolin?",
+		"Cancer":      "Hai! Aku di sini kalau kamu mau cerita atau butuh temen ngobrol.",
+		"Leo":         "Halo! Ada yang bisa aku bantu hari ini?",
+		"Virgo":       "Hai! Cerita aja kalau ada yang mau dibahas, aku siap dengerin.",
+		"Libra":       "Halo! Gimana hari ini? Ada yang mau diceritain?",
+		"Scorpio":     "Hai! Aku di sini kalau kamu butuh temen ngobrol.",
+		"Sagittarius": "Halo! Ada yang mau dibahas? Cerita aja santai.",
+		"Capricorn":   "Hai! Gimana kabarnya? Aku siap dengerin kalau ada yang mau diceritain.",
+		"Aquarius":    "Halo! Ada yang bisa aku bantu? Ngobrol aja santai.",
+		"Pisces":      "Hai! Aku di sini kalau kamu butuh temen cerita.",
+	}
+	
+	if response, ok := fallbacks[zodiacSign]; ok {
+		return response
+	}
+	
+	return "Halo! Ada yang bisa aku bantu? Cerita aja santai."
+}
+
+// getFallbackInsight returns fallback insight if AI fails
+func (c *AIClient) getFallbackInsight() string {
+	return "Setiap percakapan adalah cerminan dari perjalanan hidup kita. Dalam berbagi cerita dan perasaan, kita menemukan kekuatan untuk terus maju. Ingatlah bahwa setiap tantangan adalah kesempatan untuk tumbuh, dan setiap emosi yang kita rasakan adalah bagian dari kemanusiaan kita. Teruslah berbicara, teruslah berbagi, dan teruslah percaya bahwa hari esok membawa harapan baru."
+}
+
+// getZodiacTraits returns personality traits for zodiac signs
+func getZodiacTraits(sign string) string {
+	traits := map[string]string{
+		"Aries":       "passionate, confident, determined, and courageous",
+		"Taurus":      "reliable, patient, devoted, and practical",
+		"Gemini":      "adaptable, outgoing, intelligent, and curious",
+		"Cancer":      "intuitive, emotional, protective, and nurturing",
+		"Leo":         "creative, passionate, generous, and warm-hearted",
+		"Virgo":       "loyal, analytical, hardworking, and practical",
+		"Libra":       "diplomatic, gracious, fair-minded, and social",
+		"Scorpio":     "resourceful, brave, passionate, and determined",
+		"Sagittarius": "generous, idealistic, great sense of humor, and adventurous",
+		"Capricorn":   "responsible, disciplined, self-controlled, and ambitious",
+		"Aquarius":    "progressive, original, independent, and humanitarian",
+		"Pisces":      "compassionate, artistic, intuitive, and gentle",
+	}
+	
+	if trait, ok := traits[sign]; ok {
+		return trait
+	}
+	
+	return "empathetic and understanding"
+}
+
+// Close closes the AI client
+func (c *AIClient) Close() error {
+	return c.provider.Close()
+}
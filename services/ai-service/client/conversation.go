@@ -0,0 +1,42 @@
+package client
+
+import "strings"
+
+// Turn roles
+const (
+	RoleUser  = "user"
+	RoleModel = "model"
+)
+
+// ChatTurn is one turn of conversation history
+type ChatTurn struct {
+	Role string `json:"role"` // "user" or "model"
+	Text string `json:"text"`
+}
+
+// ChatRequest is a multi-turn chat request (POST /ai/chat)
+// Requests with only zodiac_sign and user_message still work: history and summary are optional
+type ChatRequest struct {
+	ZodiacSign  string     `json:"zodiac_sign"`
+	Summary     string     `json:"summary,omitempty"` // Rolling summary of turns older than History
+	History     []ChatTurn `json:"history,omitempty"` // Oldest first, without UserMessage
+	UserMessage string     `json:"user_message"`
+}
+
+// ValidRole reports whether role is a supported turn role
+func ValidRole(role string) bool {
+	return role == RoleUser || role == RoleModel
+}
+
+// formatTurns renders turns as a plain transcript
+func formatTurns(turns []ChatTurn) string {
+	var transcript strings.Builder
+	for _, turn := range turns {
+		speaker := "User"
+		if turn.Role == RoleModel {
+			speaker = "AI"
+		}
+		transcript.WriteString(speaker + ": " + turn.Text + "\n")
+	}
+	return transcript.String()
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// FakeRule is one scripted response of the fake provider
+type FakeRule struct {
+	Match    string `json:"match"`    // Case-insensitive substring of the last user turn; empty matches anything
+	Response string `json:"response"` // Reply text, streamed word by word
+	Error    string `json:"error"`    // If set, the call fails with this message (exercises retries and fallbacks)
+}
+
+// FakeProvider is a deterministic, offline provider for tests and local development
+// The first rule matching the last user turn decides the reply; without a match it echoes
+// the message back, so the same input always gives the same output.
+type FakeProvider struct {
+	rules []FakeRule
+}
+
+// NewFakeProvider creates a fake provider from rules
+func NewFakeProvider(rules []FakeRule) *FakeProvider {
+	return &FakeProvider{rules: rules}
+}
+
+// NewFakeProviderFromFile creates a fake provider from a JSON array of rules
+// An empty path gives a provider that only echoes
+func NewFakeProviderFromFile(path string) (*FakeProvider, error) {
+	var rules []FakeRule
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fake LLM script: %w", err)
+		}
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return nil, fmt.Errorf("failed to parse fake LLM script: %w", err)
+		}
+	}
+
+	log.Printf("🧪 Fake LLM provider initialized (%d scripted rules)", len(rules))
+	return NewFakeProvider(rules), nil
+}
+
+// Name identifies the provider in logs
+func (p *FakeProvider) Name() string {
+	return "Fake"
+}
+
+// Generate returns the scripted response
+func (p *FakeProvider) Generate(ctx context.Context, req *GenerateRequest) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return p.respond(req)
+}
+
+// Stream sends the scripted response word by word
+func (p *FakeProvider) Stream(ctx context.Context, req *GenerateRequest, onChunk func(string) error) (string, error) {
+	response, err := p.respond(req)
+	if err != nil {
+		return "", err
+	}
+
+	var full strings.Builder
+	for _, word := range strings.SplitAfter(response, " ") {
+		if err := ctx.Err(); err != nil {
+			return full.String(), err
+		}
+
+		full.WriteString(word)
+		if err := onChunk(word); err != nil {
+			return full.String(), err
+		}
+	}
+
+	return full.String(), nil
+}
+
+// CountTokens estimates the input tokens
+func (p *FakeProvider) CountTokens(ctx context.Context, req *GenerateRequest) (int, error) {
+	return estimateTokens(req), nil
+}
+
+// Close releases provider resources
+func (p *FakeProvider) Close() error {
+	return nil
+}
+
+// respond picks the reply for the last user turn
+func (p *FakeProvider) respond(req *GenerateRequest) (string, error) {
+	var message string
+	for i := len(req.Turns) - 1; i >= 0; i-- {
+		if req.Turns[i].Role == RoleUser {
+			message = req.Turns[i].Text
+			break
+		}
+	}
+
+	lower := strings.ToLower(message)
+	for _, rule := range p.rules {
+		if rule.Match != "" && !strings.Contains(lower, strings.ToLower(rule.Match)) {
+			continue
+		}
+		if rule.Error != "" {
+			return "", errors.New("fake: " + rule.Error)
+		}
+		return rule.Response, nil
+	}
+
+	if runes := []rune(message); len(runes) > 80 {
+		message = string(runes[:80]) + "..."
+	}
+	return "[fake] " + message, nil
+}
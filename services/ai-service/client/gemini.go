@@ -2,296 +2,145 @@ package client
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log"
 	"os"
-	"time"
-
-	"zodiac-ai-backend/pkg/circuitbreaker"
-	"zodiac-ai-backend/pkg/ratelimiter"
+	"strings"
 
 	"google.golang.org/genai"
 )
 
-var (
-	ErrAIUnavailable = errors.New("AI service unavailable")
-	ErrInvalidPrompt = errors.New("invalid prompt")
-)
+// DefaultGeminiModel is used when no model is configured
+const DefaultGeminiModel = "gemini-2.5-flash"
 
-// GeminiClient handles Gemini AI interactions
-// Implements retry strategy with exponential backoff, rate limiting, and circuit breaker
-type GeminiClient struct {
-	client         *genai.Client
-	model          string
-	maxRetries     int
-	baseDelay      time.Duration
-	rateLimiter    *ratelimiter.RateLimiter
-	circuitBreaker *circuitbreaker.CircuitBreaker
+// GeminiProvider generates text with Google Gemini
+type GeminiProvider struct {
+	client *genai.Client
+	model  string
 }
 
-// NewGeminiClient creates a new Gemini AI client
+// NewGeminiProvider creates a new Gemini provider
 // The API key is automatically read from GEMINI_API_KEY environment variable
-func NewGeminiClient(apiKey string) (*GeminiClient, error) {
+func NewGeminiProvider(model string) (*GeminiProvider, error) {
 	ctx := context.Background()
-	
+
 	// Check if GEMINI_API_KEY is set in environment
 	envKey := os.Getenv("GEMINI_API_KEY")
 	if envKey == "" {
 		log.Printf("❌❌❌ WARNING: GEMINI_API_KEY environment variable is NOT set! ❌❌❌")
-		log.Printf("⚠️ AI responses will use fallback messages only")
-		log.Printf("💡 Set GEMINI_API_KEY in your environment to enable real AI responses")
+		log.Printf("💡 Set GEMINI_API_KEY, or LLM_PROVIDER=fake for offline development")
 	} else {
 		log.Printf("✅ GEMINI_API_KEY found in environment (length: %d chars)", len(envKey))
 	}
-	
+
 	// Create Gemini client - it will automatically use GEMINI_API_KEY env var
-	// Note: apiKey parameter is kept for backward compatibility but not used
-	// Make sure GEMINI_API_KEY is set in your environment
 	client, err := genai.NewClient(ctx, nil)
 	if err != nil {
 		log.Printf("❌ Failed to create Gemini client: %v", err)
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
 
-	log.Printf("✅ Gemini client initialized successfully")
-	log.Printf("📋 Using model: gemini-2.5-flash")
-
-	// Initialize rate limiter (10 requests per second)
-	rateLimiter := ratelimiter.NewRateLimiter(10, time.Second)
-	log.Printf("⚡ Rate limiter initialized: 10 req/s")
+	if model == "" {
+		model = DefaultGeminiModel
+	}
 
-	// Initialize circuit breaker (5 failures, 60s timeout)
-	circuitBreaker := circuitbreaker.NewCircuitBreaker(circuitbreaker.Config{
-		MaxFailures:     5,
-		ResetTimeout:    60 * time.Second,
-		HalfOpenMaxReqs: 1,
-	})
-	log.Printf("🔌 Circuit breaker initialized: 5 failures threshold, 60s timeout")
+	log.Printf("✅ Gemini client initialized successfully")
+	log.Printf("📋 Using model: %s", model)
 
-	return &GeminiClient{
-		client:         client,
-		model:          "gemini-2.5-flash",
-		maxRetries:     3,
-		baseDelay:      time.Second,
-		rateLimiter:    rateLimiter,
-		circuitBreaker: circuitBreaker,
+	return &GeminiProvider{
+		client: client,
+		model:  model,
 	}, nil
 }
 
-// GenerateContent generates content with retry strategy, rate limiting, and circuit breaker
-// Retry strategy: 3 attempts with exponential backoff (1s, 2s, 4s)
-// Rate limiting: 10 requests per second
-// Circuit breaker: Opens after 5 consecutive failures
-func (c *GeminiClient) GenerateContent(ctx context.Context, prompt string) (string, error) {
-	if prompt == "" {
-		return "", ErrInvalidPrompt
-	}
-
-	// Check circuit breaker state
-	if c.circuitBreaker.IsOpen() {
-		log.Printf("⚠️ Circuit breaker is OPEN - failing fast")
-		return "", ErrAIUnavailable
-	}
+// Name identifies the provider in logs
+func (p *GeminiProvider) Name() string {
+	return "Gemini"
+}
 
-	// Wait for rate limiter
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		log.Printf("⚠️ Rate limiter wait cancelled: %v", err)
+// Generate returns the complete response
+func (p *GeminiProvider) Generate(ctx context.Context, req *GenerateRequest) (string, error) {
+	result, err := p.client.Models.GenerateContent(ctx, p.model, toGenaiContents(req.Turns), generateConfig(req))
+	if err != nil {
 		return "", err
 	}
+	return result.Text(), nil
+}
 
-	var lastErr error
-	
-	for attempt := 0; attempt < c.maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff: 1s, 2s, 4s
-			delay := c.baseDelay * time.Duration(1<<uint(attempt-1))
-			log.Printf("Retry attempt %d after %v delay", attempt+1, delay)
-			time.Sleep(delay)
-		}
+// Stream calls onChunk for each text chunk as it arrives
+func (p *GeminiProvider) Stream(ctx context.Context, req *GenerateRequest, onChunk func(string) error) (string, error) {
+	var full strings.Builder
 
-		// Execute with circuit breaker
-		var result *genai.GenerateContentResponse
-		err := c.circuitBreaker.Execute(func() error {
-			// Set timeout for this attempt
-			attemptCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-			defer cancel()
-			
-			var apiErr error
-			result, apiErr = c.client.Models.GenerateContent(
-				attemptCtx,
-				c.model,
-				genai.Text(prompt),
-				nil,
-			)
-			return apiErr
-		})
+	for result, err := range p.client.Models.GenerateContentStream(ctx, p.model, toGenaiContents(req.Turns), generateConfig(req)) {
+		if err != nil {
+			return full.String(), err
+		}
 
-		if err == nil && result != nil {
-			log.Printf("✅ Gemini API success (attempt %d, circuit: %s)", 
-				attempt+1, c.circuitBreaker.State())
-			return result.Text(), nil
+		text := result.Text()
+		if text == "" {
+			continue
 		}
 
-		lastErr = err
-		log.Printf("❌ Gemini API error (attempt %d/%d, circuit: %s): %v", 
-			attempt+1, c.maxRetries, c.circuitBreaker.State(), err)
-		
-		// If circuit is open, fail fast
-		if c.circuitBreaker.IsOpen() {
-			log.Printf("⚠️ Circuit breaker opened - stopping retries")
-			break
+		full.WriteString(text)
+		if err := onChunk(text); err != nil {
+			return full.String(), err
 		}
 	}
 
-	// All retries failed
-	log.Printf("❌ All Gemini API retries failed (circuit: %s): %v", 
-		c.circuitBreaker.State(), lastErr)
-	return "", ErrAIUnavailable
+	return full.String(), nil
 }
 
-// GenerateChatResponse generates AI chat response with zodiac persona
-func (c *GeminiClient) GenerateChatResponse(ctx context.Context, zodiacSign, userMessage string) (string, error) {
-	// Validate zodiac sign
-	if zodiacSign == "" {
-		log.Printf("⚠️ Empty zodiac sign, using default")
-		zodiacSign = "Gemini" // Default fallback
-	}
-	
-	// Validate user message
-	if userMessage == "" {
-		log.Printf("❌ Empty user message received")
-		return "", ErrInvalidPrompt
-	}
-	
-	log.Printf("🤖 Generating AI response for zodiac: %s, message: %.50s...", zodiacSign, userMessage)
-	
-	prompt := c.buildChatPrompt(zodiacSign, userMessage)
-	
-	response, err := c.GenerateContent(ctx, prompt)
-	if err != nil {
-		// Log the error for debugging with more details
-		if err == ErrAIUnavailable {
-			log.Printf("❌❌❌ GEMINI API UNAVAILABLE - Check if GEMINI_API_KEY is set correctly! ❌❌❌")
-			log.Printf("⚠️ Using fallback response for zodiac: %s", zodiacSign)
-		} else {
-			log.Printf("⚠️ Gemini API failed with error: %v", err)
-			log.Printf("⚠️ Using fallback response for zodiac: %s", zodiacSign)
-		}
-		// Fallback response if AI fails
-		return c.getFallbackChatResponse(zodiacSign), nil
+// CountTokens returns the number of input tokens the request would use
+// The system instruction isn't accepted by the Gemini API's count, so it's sent as a leading turn
+func (p *GeminiProvider) CountTokens(ctx context.Context, req *GenerateRequest) (int, error) {
+	turns := req.Turns
+	if req.System != "" {
+		turns = append([]ChatTurn{{Role: RoleUser, Text: req.System}}, turns...)
 	}
-	
-	log.Printf("✅ Gemini API success for zodiac: %s, response length: %d chars", zodiacSign, len(response))
-	return response, nil
-}
 
-// GenerateInsight generates life lesson insight from chat history
-func (c *GeminiClient) GenerateInsight(ctx context.Context, chatHistory string) (string, error) {
-	prompt := c.buildInsightPrompt(chatHistory)
-	
-	response, err := c.GenerateContent(ctx, prompt)
+	result, err := p.client.Models.CountTokens(ctx, p.model, toGenaiContents(turns), nil)
 	if err != nil {
-		// Fallback insight if AI fails
-		return c.getFallbackInsight(), nil
+		return 0, err
 	}
-	
-	return response, nil
-}
-
-// buildChatPrompt builds prompt for chat response
-func (c *GeminiClient) buildChatPrompt(zodiacSign, userMessage string) string {
-	traits := getZodiacTraits(zodiacSign)
-	
-	return fmt.Sprintf(`Kamu adalah AI companion yang ramah dan bisa diajak ngobrol santai.
-Kamu punya sedikit karakteristik zodiak %s (%s), tapi jangan terlalu berlebihan atau alay.
-
-Respon dengan natural seperti teman yang ngobrol biasa:
-- Jangan terlalu formal atau kaku
-- Jangan terlalu dramatis atau puitis
-- Fokus pada apa yang user tanyakan/ceritakan
-- Kasih respon yang relevan dengan pesan mereka
-- Boleh santai tapi tetap supportive
-
-Pesan user: %s
-
-Respon dalam bahasa Indonesia yang natural dan casual (max 100 kata).`, 
-		zodiacSign, traits, userMessage)
+	return int(result.TotalTokens), nil
 }
 
-// buildInsightPrompt builds prompt for insight generation
-func (c *GeminiClient) buildInsightPrompt(chatHistory string) string {
-	return fmt.Sprintf(`Analyze this conversation and extract a profound life lesson or insight.
-Create a short, inspirational message (max 200 words) that could help others facing similar situations.
-
-Conversation:
-%s
-
-Generate a wisdom-filled insight that:
-1. Identifies the core emotional theme
-2. Offers a universal life lesson
-3. Provides hope and encouragement
-4. Is relatable to others
-
-Format: A single paragraph of wisdom in Bahasa Indonesia. Make it profound and shareable.`, 
-		chatHistory)
+// Close releases provider resources
+func (p *GeminiProvider) Close() error {
+	// Gemini client doesn't have explicit close method
+	return nil
 }
 
-// getFallbackChatResponse returns fallback response if AI fails
-func (c *GeminiClient) getFallbackChatResponse(zodiacSign string) string {
-	fallbacks := map[string]string{
-		"Aries":       "Halo! Maaf nih, lagi ada gangguan sebentar. Tapi aku di sini kok, siap dengerin kamu.",
-		"Taurus":      "Hai! Ada yang bisa aku bantu? Cerita aja, aku dengerin.",
-		"Gemini":      "Halo! Gimana kabarnya? Ada yang mau diobrolin?",
-		"Cancer":      "Hai! Aku di sini kalau kamu mau cerita atau butuh temen ngobrol.",
-		"Leo":         "Halo! Ada yang bisa aku bantu hari ini?",
-		"Virgo":       "Hai! Cerita aja kalau ada yang mau dibahas, aku siap dengerin.",
-		"Libra":       "Halo! Gimana hari ini? Ada yang mau diceritain?",
-		"Scorpio":     "Hai! Aku di sini kalau kamu butuh temen ngobrol.",
-		"Sagittarius": "Halo! Ada yang mau dibahas? Cerita aja santai.",
-		"Capricorn":   "Hai! Gimana kabarnya? Aku siap dengerin kalau ada yang mau diceritain.",
-		"Aquarius":    "Halo! Ada yang bisa aku bantu? Ngobrol aja santai.",
-		"Pisces":      "Hai! Aku di sini kalau kamu butuh temen cerita.",
+// generateConfig carries the system instruction, if any
+func generateConfig(req *GenerateRequest) *genai.GenerateContentConfig {
+	if req.System == "" {
+		return nil
 	}
-	
-	if response, ok := fallbacks[zodiacSign]; ok {
-		return response
+	return &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText(req.System, genai.RoleUser),
 	}
-	
-	return "Halo! Ada yang bisa aku bantu? Cerita aja santai."
 }
 
-// getFallbackInsight returns fallback insight if AI fails
-func (c *GeminiClient) getFallbackInsight() string {
-	return "Setiap percakapan adalah cerminan dari perjalanan hidup kita. Dalam berbagi cerita dan perasaan, kita menemukan kekuatan untuk terus maju. Ingatlah bahwa setiap tantangan adalah kesempatan untuk tumbuh, dan setiap emosi yang kita rasakan adalah bagian dari kemanusiaan kita. Teruslah berbicara, teruslah berbagi, dan teruslah percaya bahwa hari esok membawa harapan baru."
-}
+// toGenaiContents converts turns into alternating user/model contents
+// Consecutive turns of the same role (e.g. after a failed reply) are merged and a leading
+// model turn is dropped, since a Gemini conversation has to start with the user
+func toGenaiContents(turns []ChatTurn) []*genai.Content {
+	contents := make([]*genai.Content, 0, len(turns))
+	for _, turn := range turns {
+		if turn.Text == "" {
+			continue
+		}
+		if len(contents) == 0 && turn.Role != RoleUser {
+			continue
+		}
 
-// getZodiacTraits returns personality traits for zodiac signs
-func getZodiacTraits(sign string) string {
-	traits := map[string]string{
-		"Aries":       "passionate, confident, determined, and courageous",
-		"Taurus":      "reliable, patient, devoted, and practical",
-		"Gemini":      "adaptable, outgoing, intelligent, and curious",
-		"Cancer":      "intuitive, emotional, protective, and nurturing",
-		"Leo":         "creative, passionate, generous, and warm-hearted",
-		"Virgo":       "loyal, analytical, hardworking, and practical",
-		"Libra":       "diplomatic, gracious, fair-minded, and social",
-		"Scorpio":     "resourceful, brave, passionate, and determined",
-		"Sagittarius": "generous, idealistic, great sense of humor, and adventurous",
-		"Capricorn":   "responsible, disciplined, self-controlled, and ambitious",
-		"Aquarius":    "progressive, original, independent, and humanitarian",
-		"Pisces":      "compassionate, artistic, intuitive, and gentle",
-	}
-	
-	if trait, ok := traits[sign]; ok {
-		return trait
+		if last := len(contents) - 1; last >= 0 && contents[last].Role == turn.Role {
+			contents[last].Parts = append(contents[last].Parts, genai.NewPartFromText(turn.Text))
+			continue
+		}
+
+		contents = append(contents, genai.NewContentFromText(turn.Text, genai.Role(turn.Role)))
 	}
-	
-	return "empathetic and understanding"
-}
 
-// Close closes the Gemini client
-func (c *GeminiClient) Close() error {
-	// Gemini client doesn't have explicit close method
-	return nil
+	return contents
 }
@@ -0,0 +1,192 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"zodiac-ai-backend/pkg/sse"
+)
+
+// DefaultOpenAIBaseURL points at a local Ollama server's OpenAI-compatible API
+const DefaultOpenAIBaseURL = "http://localhost:11434/v1"
+
+// OpenAIProvider generates text with any OpenAI-compatible chat completions API
+// (OpenAI, Ollama, llama.cpp server, vLLM, LM Studio, ...)
+type OpenAIProvider struct {
+	baseURL string
+	model   string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewOpenAIProvider creates a new OpenAI-compatible provider
+func NewOpenAIProvider(baseURL, model, apiKey string) (*OpenAIProvider, error) {
+	if model == "" {
+		return nil, errors.New("LLM_MODEL is required for the openai provider")
+	}
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+
+	log.Printf("✅ OpenAI-compatible provider initialized: %s", baseURL)
+	log.Printf("📋 Using model: %s", model)
+
+	// No overall timeout: AIClient bounds each call with a context deadline, streams run longer
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 60 * time.Second
+
+	return &OpenAIProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		apiKey:  apiKey,
+		client:  &http.Client{Transport: transport},
+	}, nil
+}
+
+// openAIMessage is a chat completions message
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Name identifies the provider in logs
+func (p *OpenAIProvider) Name() string {
+	return "OpenAI-compatible"
+}
+
+// Generate returns the complete response
+func (p *OpenAIProvider) Generate(ctx context.Context, req *GenerateRequest) (string, error) {
+	resp, err := p.post(ctx, req, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Choices []struct {
+			Message openAIMessage `json:"message"`
+		} `json:"choices"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	if len(result.Choices) == 0 {
+		return "", errors.New("openai: response has no choices")
+	}
+
+	return result.Choices[0].Message.Content, nil
+}
+
+// Stream calls onChunk for each text chunk as it arrives
+func (p *OpenAIProvider) Stream(ctx context.Context, req *GenerateRequest, onChunk func(string) error) (string, error) {
+	resp, err := p.post(ctx, req, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var full strings.Builder
+	reader := sse.NewReader(resp.Body)
+
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			return full.String(), errors.New("openai: stream ended without [DONE]")
+		}
+		if err != nil {
+			return full.String(), err
+		}
+
+		if event.Data == "[DONE]" {
+			return full.String(), nil
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta openAIMessage `json:"delta"`
+			} `json:"choices"`
+		}
+		if err := event.Decode(&chunk); err != nil {
+			return full.String(), err
+		}
+
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		text := chunk.Choices[0].Delta.Content
+		full.WriteString(text)
+		if err := onChunk(text); err != nil {
+			return full.String(), err
+		}
+	}
+}
+
+// CountTokens estimates the input tokens
+// The chat completions API has no token counting endpoint
+func (p *OpenAIProvider) CountTokens(ctx context.Context, req *GenerateRequest) (int, error) {
+	return estimateTokens(req), nil
+}
+
+// Close releases provider resources
+func (p *OpenAIProvider) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// post sends a chat completions request
+func (p *OpenAIProvider) post(ctx context.Context, req *GenerateRequest, stream bool) (*http.Response, error) {
+	messages := make([]openAIMessage, 0, len(req.Turns)+1)
+	if req.System != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: req.System})
+	}
+	for _, turn := range req.Turns {
+		role := "user"
+		if turn.Role == RoleModel {
+			role = "assistant"
+		}
+		messages = append(messages, openAIMessage{Role: role, Content: turn.Text})
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"model":    p.model,
+		"messages": messages,
+		"stream":   stream,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("openai: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp, nil
+}
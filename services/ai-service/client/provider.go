@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Provider names (LLM_PROVIDER)
+const (
+	ProviderGemini = "gemini"
+	ProviderOpenAI = "openai"
+	ProviderFake   = "fake"
+)
+
+// LLMProvider generates text from a model backend
+// Implementations only talk to the model; retries, rate limiting, circuit breaking,
+// prompts and fallbacks live in AIClient so every provider gets them.
+type LLMProvider interface {
+	// Name identifies the provider in logs
+	Name() string
+
+	// Generate returns the complete response
+	Generate(ctx context.Context, req *GenerateRequest) (string, error)
+
+	// Stream calls onChunk for each text chunk as it arrives and returns the full response
+	// An error from onChunk stops the stream and is returned as-is
+	Stream(ctx context.Context, req *GenerateRequest, onChunk func(string) error) (string, error)
+
+	// CountTokens returns the number of input tokens the request would use
+	CountTokens(ctx context.Context, req *GenerateRequest) (int, error)
+
+	// Close releases provider resources
+	Close() error
+}
+
+// GenerateRequest is a provider-neutral generation request
+type GenerateRequest struct {
+	System string     // Optional system instruction
+	Turns  []ChatTurn // Conversation, oldest first; the last turn is the user's
+}
+
+// promptRequest wraps a single prompt as a request
+func promptRequest(prompt string) *GenerateRequest {
+	return &GenerateRequest{Turns: []ChatTurn{{Role: RoleUser, Text: prompt}}}
+}
+
+// ProviderConfig selects and configures the LLM provider
+type ProviderConfig struct {
+	Provider   string // gemini (default), openai or fake
+	Model      string // Empty uses the provider default
+	BaseURL    string // openai: API base URL, e.g. http://localhost:11434/v1
+	APIKey     string // openai: bearer token (optional for local servers)
+	FakeScript string // fake: path to a JSON script (optional)
+}
+
+// NewProvider creates the configured LLM provider
+func NewProvider(cfg ProviderConfig) (LLMProvider, error) {
+	switch cfg.Provider {
+	case "", ProviderGemini:
+		return NewGeminiProvider(cfg.Model)
+	case ProviderOpenAI:
+		return NewOpenAIProvider(cfg.BaseURL, cfg.Model, cfg.APIKey)
+	case ProviderFake:
+		return NewFakeProviderFromFile(cfg.FakeScript)
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.Provider)
+	}
+}
+
+// estimateTokens roughly estimates the token count of a request (~4 characters per token)
+// Used by providers without a token counting API
+func estimateTokens(req *GenerateRequest) int {
+	chars := utf8.RuneCountInString(req.System)
+	for _, turn := range req.Turns {
+		chars += utf8.RuneCountInString(turn.Text)
+	}
+	return (chars + 3) / 4
+}
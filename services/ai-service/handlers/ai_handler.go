@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"log"
 	"time"
 	
 	"zodiac-ai-backend/pkg/queue"
 	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/pkg/sse"
 	"zodiac-ai-backend/services/ai-service/client"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,14 +18,14 @@ import (
 
 // AIHandler handles AI-related HTTP requests
 type AIHandler struct {
-	geminiClient *client.GeminiClient
+	aiClient     *client.AIClient
 	requestQueue *queue.RequestQueue
 }
 
 // NewAIHandler creates a new AI handler
-func NewAIHandler(geminiClient *client.GeminiClient, requestQueue *queue.RequestQueue) *AIHandler {
+func NewAIHandler(aiClient *client.AIClient, requestQueue *queue.RequestQueue) *AIHandler {
 	return &AIHandler{
-		geminiClient: geminiClient,
+		aiClient:     aiClient,
 		requestQueue: requestQueue,
 	}
 }
@@ -30,33 +33,24 @@ func NewAIHandler(geminiClient *client.GeminiClient, requestQueue *queue.Request
 // GenerateChatResponse generates AI chat response using request queue
 // POST /ai/chat
 func (h *AIHandler) GenerateChatResponse(c *fiber.Ctx) error {
-	var req struct {
-		ZodiacSign  string `json:"zodiac_sign" validate:"required"`
-		UserMessage string `json:"user_message" validate:"required"`
-	}
-
-	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "Invalid request body", nil)
+	req, err := parseChatRequest(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error(), nil)
 	}
 
-	log.Printf("🎯 AI Handler received request - Zodiac: %s, Message: %.50s...", req.ZodiacSign, req.UserMessage)
+	log.Printf("🎯 AI Handler received request - Zodiac: %s, History: %d turns, Message: %.50s...",
+		req.ZodiacSign, len(req.History), req.UserMessage)
 
 	// Create request ID
 	requestID := uuid.New().String()
 
-	// Create request data
-	requestData := map[string]interface{}{
-		"zodiac_sign":  req.ZodiacSign,
-		"user_message": req.UserMessage,
-	}
-
 	// Create result channel
 	resultChan := make(chan queue.Result, 1)
 
 	// Create queue request
 	queueReq := &queue.Request{
 		ID:        requestID,
-		Data:      requestData,
+		Data:      req,
 		Context:   c.Context(),
 		Result:    resultChan,
 		EnqueueAt: time.Now(),
@@ -112,6 +106,46 @@ func (h *AIHandler) GenerateChatResponse(c *fiber.Ctx) error {
 	}
 }
 
+// StreamChatResponse streams an AI chat response as Server-Sent Events
+// POST /ai/chat/stream
+// Events: chunk {"delta"} per token chunk, then done {"response"} or error {"message"}
+// Streams bypass the request queue (a worker can't be held for the whole stream);
+// the AI client's rate limiter and circuit breaker still apply.
+func (h *AIHandler) StreamChatResponse(c *fiber.Ctx) error {
+	req, err := parseChatRequest(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	log.Printf("🎯 AI Handler received stream request - Zodiac: %s, History: %d turns, Message: %.50s...",
+		req.ZodiacSign, len(req.History), req.UserMessage)
+
+	for key, value := range sse.Headers() {
+		c.Set(key, value)
+	}
+
+	// The writer runs after this handler returns, so it must not touch c
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		aiResponse, err := h.aiClient.GenerateChatResponseStream(ctx, req, func(delta string) error {
+			return sse.Write(w, sse.EventChunk, sse.Chunk{Delta: delta})
+		})
+		if err != nil {
+			log.Printf("❌ AI stream failed after %d chars: %v", len(aiResponse), err)
+			sse.Write(w, sse.EventError, sse.Error{Message: "Failed to generate AI response"})
+			return
+		}
+
+		sse.Write(w, sse.EventDone, fiber.Map{
+			"response": aiResponse,
+		})
+	})
+
+	return nil
+}
+
 // GenerateInsight generates insight from chat history
 // POST /ai/insight
 func (h *AIHandler) GenerateInsight(c *fiber.Ctx) error {
@@ -123,7 +157,7 @@ func (h *AIHandler) GenerateInsight(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body", nil)
 	}
 
-	insight, err := h.geminiClient.GenerateInsight(
+	insight, err := h.aiClient.GenerateInsight(
 		c.Context(),
 		req.ChatHistory,
 	)
@@ -135,3 +169,69 @@ func (h *AIHandler) GenerateInsight(c *fiber.Ctx) error {
 		"insight": insight,
 	})
 }
+
+// Summarize folds older conversation turns into a rolling summary
+// POST /ai/summarize
+func (h *AIHandler) Summarize(c *fiber.Ctx) error {
+	var req struct {
+		Summary string            `json:"summary"`
+		Turns   []client.ChatTurn `json:"turns"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	for _, turn := range req.Turns {
+		if !client.ValidRole(turn.Role) {
+			return response.BadRequest(c, "turn role must be user or model", nil)
+		}
+	}
+
+	summary, err := h.aiClient.SummarizeConversation(c.Context(), req.Summary, req.Turns)
+	if err != nil {
+		return response.ServiceUnavailable(c, "Failed to summarize conversation")
+	}
+
+	return response.Success(c, "Summary generated", fiber.Map{
+		"summary": summary,
+	})
+}
+
+// CountTokens counts the input tokens a chat request would use with the configured provider
+// POST /ai/tokens
+func (h *AIHandler) CountTokens(c *fiber.Ctx) error {
+	req, err := parseChatRequest(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	tokens, err := h.aiClient.CountTokens(c.Context(), req)
+	if err != nil {
+		return response.ServiceUnavailable(c, "Failed to count tokens")
+	}
+
+	return response.Success(c, "Tokens counted", fiber.Map{
+		"tokens": tokens,
+	})
+}
+
+// parseChatRequest parses and validates a chat request body
+func parseChatRequest(c *fiber.Ctx) (*client.ChatRequest, error) {
+	var req client.ChatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, errors.New("Invalid request body")
+	}
+
+	if req.UserMessage == "" {
+		return nil, errors.New("user_message is required")
+	}
+
+	for _, turn := range req.History {
+		if !client.ValidRole(turn.Role) {
+			return nil, errors.New("history role must be user or model")
+		}
+	}
+
+	return &req, nil
+}
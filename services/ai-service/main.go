@@ -23,16 +23,24 @@ func main() {
 	cfg := config.LoadConfig()
 
 	// Validate Gemini API key
-	if cfg.GeminiAPIKey == "" {
-		log.Fatal("GEMINI_API_KEY environment variable is required")
+	if (cfg.LLMProvider == "" || cfg.LLMProvider == client.ProviderGemini) && cfg.GeminiAPIKey == "" {
+		log.Fatal("GEMINI_API_KEY environment variable is required (or set LLM_PROVIDER)")
 	}
 
-	// Initialize Gemini client
-	geminiClient, err := client.NewGeminiClient(cfg.GeminiAPIKey)
+	// Initialize LLM provider (gemini, openai-compatible or fake)
+	provider, err := client.NewProvider(client.ProviderConfig{
+		Provider:   cfg.LLMProvider,
+		Model:      cfg.LLMModel,
+		BaseURL:    cfg.LLMBaseURL,
+		APIKey:     cfg.LLMAPIKey,
+		FakeScript: cfg.LLMFakeScript,
+	})
 	if err != nil {
-		log.Fatalf("Failed to initialize Gemini client: %v", err)
+		log.Fatalf("Failed to initialize LLM provider: %v", err)
 	}
-	defer geminiClient.Close()
+
+	aiClient := client.NewAIClient(provider)
+	defer aiClient.Close()
 
 	// Initialize request queue with processor
 	requestQueue := queue.NewRequestQueue(queue.Config{
@@ -40,12 +48,10 @@ func main() {
 		Workers:   10,   // 10 concurrent workers
 		Processor: func(ctx context.Context, data interface{}) (interface{}, error) {
 			// Extract request data
-			reqData := data.(map[string]interface{})
-			zodiacSign := reqData["zodiac_sign"].(string)
-			userMessage := reqData["user_message"].(string)
+			chatReq := data.(*client.ChatRequest)
 
 			// Generate AI response
-			response, err := geminiClient.GenerateChatResponse(ctx, zodiacSign, userMessage)
+			response, err := aiClient.GenerateChatResponse(ctx, chatReq)
 			return response, err
 		},
 	})
@@ -60,7 +66,7 @@ func main() {
 	}()
 
 	// Initialize handlers
-	aiHandler := handlers.NewAIHandler(geminiClient, requestQueue)
+	aiHandler := handlers.NewAIHandler(aiClient, requestQueue)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
@@ -90,7 +96,10 @@ func main() {
 	// AI routes (internal - called by other services)
 	ai := api.Group("/ai")
 	ai.Post("/chat", aiHandler.GenerateChatResponse)
+	ai.Post("/chat/stream", aiHandler.StreamChatResponse)
 	ai.Post("/insight", aiHandler.GenerateInsight)
+	ai.Post("/summarize", aiHandler.Summarize)
+	ai.Post("/tokens", aiHandler.CountTokens)
 
 	// Start server
 	port := cfg.AIServicePort
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AccountHandler handles password reset and email verification HTTP requests
+type AccountHandler struct {
+	accountService *services.AccountService
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// ForgotPassword emails a password reset link
+// POST /auth/forgot-password
+func (h *AccountHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.accountService.ForgotPassword(c.Context(), &req); err != nil {
+		return accountErrorResponse(c, err, "Failed to send password reset email")
+	}
+
+	// Same answer whether or not the email is registered
+	return response.Success(c, "If the email is registered, a password reset link has been sent", nil)
+}
+
+// ResetPassword sets a new password using a reset token
+// POST /auth/reset-password
+func (h *AccountHandler) ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.accountService.ResetPassword(c.Context(), &req); err != nil {
+		return accountErrorResponse(c, err, "Failed to reset password")
+	}
+
+	return response.Success(c, "Password reset successfully, please log in again", nil)
+}
+
+// VerifyEmail verifies the user's email using a verification token
+// POST /auth/verify-email
+func (h *AccountHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req models.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.accountService.VerifyEmail(c.Context(), &req); err != nil {
+		return accountErrorResponse(c, err, "Failed to verify email")
+	}
+
+	return response.Success(c, "Email verified successfully", nil)
+}
+
+// ResendVerification emails a new verification link
+// POST /auth/resend-verification
+func (h *AccountHandler) ResendVerification(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	if err := h.accountService.ResendVerification(c.Context(), userID); err != nil {
+		return accountErrorResponse(c, err, "Failed to send verification email")
+	}
+
+	return response.Success(c, "Verification email sent", nil)
+}
+
+// accountErrorResponse maps account service errors to HTTP responses
+func accountErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	if details, ok := validationDetails(err); ok {
+		return response.BadRequest(c, "Validation failed", details)
+	}
+
+	switch err {
+	case services.ErrInvalidUserToken:
+		return response.BadRequest(c, "Invalid or expired token", nil)
+	case services.ErrEmailAlreadyVerified:
+		return response.Conflict(c, "Email already verified")
+	case services.ErrResendTooSoon:
+		return response.TooManyRequests(c, "Please wait a minute before requesting another email")
+	}
+
+	return response.InternalServerError(c, fallback)
+}
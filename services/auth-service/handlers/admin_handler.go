@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+	"zodiac-ai-backend/services/auth-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHandler handles user administration HTTP requests
+type AdminHandler struct {
+	adminService *services.AdminService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// ListUsers lists and searches users
+// GET /admin/users?q=&role=&suspended=&cursor=&limit=
+func (h *AdminHandler) ListUsers(c *fiber.Ctx) error {
+	var query models.AdminUserQuery
+	if err := c.QueryParser(&query); err != nil {
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	users, nextCursor, err := h.adminService.ListUsers(c.Context(), &query)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to list users")
+	}
+
+	return response.SuccessWithMeta(c, "Users retrieved successfully", users, &response.MetaData{
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+		Limit:      query.Limit,
+	})
+}
+
+// GetUser gets a user
+// GET /admin/users/:id
+func (h *AdminHandler) GetUser(c *fiber.Ctx) error {
+	user, err := h.adminService.GetUser(c.Context(), c.Params("id"))
+	if err != nil {
+		return adminErrorResponse(c, err, "Failed to get user")
+	}
+
+	return response.Success(c, "User retrieved successfully", user)
+}
+
+// Suspend suspends a user
+// POST /admin/users/:id/suspend
+func (h *AdminHandler) Suspend(c *fiber.Ctx) error {
+	var req models.SuspendUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	user, err := h.adminService.Suspend(c.Context(), admin(c), c.Params("id"), &req)
+	if err != nil {
+		return adminErrorResponse(c, err, "Failed to suspend user")
+	}
+
+	return response.Success(c, "User suspended", user)
+}
+
+// Unsuspend lifts a user's suspension
+// POST /admin/users/:id/unsuspend
+func (h *AdminHandler) Unsuspend(c *fiber.Ctx) error {
+	user, err := h.adminService.Unsuspend(c.Context(), admin(c), c.Params("id"))
+	if err != nil {
+		return adminErrorResponse(c, err, "Failed to unsuspend user")
+	}
+
+	return response.Success(c, "User unsuspended", user)
+}
+
+// ForceLogout ends all of a user's sessions
+// POST /admin/users/:id/logout
+func (h *AdminHandler) ForceLogout(c *fiber.Ctx) error {
+	if err := h.adminService.ForceLogout(c.Context(), admin(c), c.Params("id")); err != nil {
+		return adminErrorResponse(c, err, "Failed to log user out")
+	}
+
+	return response.Success(c, "User logged out of all sessions", nil)
+}
+
+// SetRoles replaces a user's roles
+// PUT /admin/users/:id/roles
+func (h *AdminHandler) SetRoles(c *fiber.Ctx) error {
+	var req models.UpdateRolesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	user, err := h.adminService.SetRoles(c.Context(), admin(c), c.Params("id"), &req)
+	if err != nil {
+		return adminErrorResponse(c, err, "Failed to update roles")
+	}
+
+	return response.Success(c, "Roles updated", user)
+}
+
+// admin identifies the user performing an admin request
+func admin(c *fiber.Ctx) *services.Admin {
+	return &services.Admin{
+		UserID:    middleware.GetUserID(c),
+		Roles:     middleware.GetRoles(c),
+		IPAddress: clientInfo(c).IPAddress,
+	}
+}
+
+// adminErrorResponse maps admin errors to HTTP responses
+func adminErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	if details, ok := validationDetails(err); ok {
+		return response.BadRequest(c, "Validation failed", details)
+	}
+
+	switch err {
+	case repositories.ErrUserNotFound:
+		return response.NotFound(c, "User not found")
+	case services.ErrCannotModerateSelf:
+		return response.BadRequest(c, "You cannot do this to your own account", nil)
+	case services.ErrTargetIsPrivileged:
+		return response.Forbidden(c, "Only admins can act on admins and moderators")
+	case services.ErrCannotRemoveOwnAdmin:
+		return response.BadRequest(c, "You cannot remove your own admin role", nil)
+	}
+
+	return response.InternalServerError(c, fallback)
+}
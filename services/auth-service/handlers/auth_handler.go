@@ -1,6 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
 	"zodiac-ai-backend/pkg/middleware"
 	"zodiac-ai-backend/pkg/response"
 	"zodiac-ai-backend/services/auth-service/models"
@@ -11,13 +17,15 @@ import (
 
 // AuthHandler handles authentication HTTP requests
 type AuthHandler struct {
-	authService *services.AuthService
+	authService    *services.AuthService
+	accountService *services.AccountService
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(authService *services.AuthService) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, accountService *services.AccountService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:    authService,
+		accountService: accountService,
 	}
 }
 
@@ -29,7 +37,7 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body", nil)
 	}
 
-	authResp, err := h.authService.Register(c.Context(), &req)
+	authResp, err := h.authService.Register(c.Context(), &req, clientInfo(c))
 	if err != nil {
 		if err == services.ErrEmailAlreadyExists {
 			return response.Conflict(c, "Email already exists")
@@ -40,6 +48,9 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		return response.InternalServerError(c, "Failed to register user: "+err.Error())
 	}
 
+	// Registration doesn't wait for (or fail on) the verification email; it can be resent
+	h.accountService.SendVerificationAsync(authResp.User)
+
 	return response.Created(c, "User registered successfully", authResp)
 }
 
@@ -51,17 +62,38 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body", nil)
 	}
 
-	authResp, err := h.authService.Login(c.Context(), &req)
+	authResp, err := h.authService.Login(c.Context(), &req, clientInfo(c))
 	if err != nil {
 		if err == services.ErrInvalidCredentials {
 			return response.Unauthorized(c, "Invalid email or password")
 		}
+		if err == services.ErrAccountDeleted {
+			return response.Forbidden(c, "This account has been deleted")
+		}
+		if err == services.ErrAccountSuspended {
+			return response.Forbidden(c, "Account suspended")
+		}
+		var locked *services.LockedError
+		if errors.As(err, &locked) {
+			return lockedOut(c, locked)
+		}
 		return response.InternalServerError(c, "Failed to login")
 	}
 
+	if authResp.MFARequired {
+		return response.Success(c, "Two-factor code required", authResp)
+	}
+
 	return response.Success(c, "Login successful", authResp)
 }
 
+// lockedOut responds to a locked out login with 429 and Retry-After
+func lockedOut(c *fiber.Ctx, locked *services.LockedError) error {
+	retryAfter := int(math.Ceil(time.Until(locked.Until).Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return response.TooManyRequests(c, "Too many failed login attempts. Please try again later.")
+}
+
 // RefreshToken handles access token refresh
 // POST /auth/refresh
 func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
@@ -73,14 +105,53 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body", nil)
 	}
 
-	accessToken, err := h.authService.RefreshAccessToken(c.Context(), req.RefreshToken)
+	// The old refresh token is rotated: clients must store the new one
+	tokens, err := h.authService.RefreshAccessToken(c.Context(), req.RefreshToken, clientInfo(c))
 	if err != nil {
+		if err == services.ErrRefreshTokenReused {
+			return response.Unauthorized(c, "Refresh token has already been used, please log in again")
+		}
+		if err == services.ErrAccountSuspended {
+			return response.Forbidden(c, "Account suspended")
+		}
 		return response.Unauthorized(c, "Invalid or expired refresh token")
 	}
 
-	return response.Success(c, "Token refreshed successfully", fiber.Map{
-		"access_token": accessToken,
-	})
+	return response.Success(c, "Token refreshed successfully", tokens)
+}
+
+// Logout ends the session of a refresh token
+// POST /auth/logout
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req models.LogoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if req.RefreshToken == "" {
+		return response.BadRequest(c, "Refresh token is required", nil)
+	}
+
+	if err := h.authService.Logout(c.Context(), &req); err != nil {
+		return response.InternalServerError(c, "Failed to logout")
+	}
+
+	return response.Success(c, "Logged out successfully", nil)
+}
+
+// LogoutAll ends all of the user's sessions
+// POST /auth/logout-all
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	if err := h.authService.LogoutAll(c.Context(), userID); err != nil {
+		return response.InternalServerError(c, "Failed to logout")
+	}
+
+	return response.Success(c, "Logged out from all sessions", nil)
 }
 
 // GetProfile handles get user profile
@@ -119,3 +190,133 @@ func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 
 	return response.Success(c, "Profile updated successfully", user)
 }
+
+// CompleteProfile sets the date of birth and gender after a provider sign-up
+// POST /users/me/complete-profile
+func (h *AuthHandler) CompleteProfile(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req models.CompleteProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	authResp, err := h.authService.CompleteProfile(c.Context(), userID, middleware.GetSessionID(c), &req)
+	if err != nil {
+		if details, ok := validationDetails(err); ok {
+			return response.BadRequest(c, "Validation failed", details)
+		}
+		switch err {
+		case services.ErrInvalidDateFormat:
+			return response.BadRequest(c, "Invalid date format, expected ISO8601 or YYYY-MM-DD", nil)
+		case services.ErrProfileComplete:
+			return response.Conflict(c, "Profile is already complete")
+		}
+		return response.InternalServerError(c, "Failed to complete profile")
+	}
+
+	// The previous access token has no zodiac sign; clients replace it with this one
+	return response.Success(c, "Profile completed successfully", authResp)
+}
+
+// JWKS serves the public keys used to verify tokens
+// GET /.well-known/jwks.json
+// Plain JWKS (RFC 7517) rather than the response envelope, so standard JWT libraries can consume it
+func (h *AuthHandler) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.authService.PublicKeys())
+}
+
+// GetSessions lists the user's active sessions
+// GET /users/me/sessions
+func (h *AuthHandler) GetSessions(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	sessions, err := h.authService.GetSessions(c.Context(), userID, middleware.GetSessionID(c))
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get sessions")
+	}
+
+	return response.Success(c, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeSession ends one of the user's sessions
+// DELETE /users/me/sessions/:id
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	if err := h.authService.RevokeSession(c.Context(), userID, c.Params("id")); err != nil {
+		if err == services.ErrSessionNotFound {
+			return response.NotFound(c, "Session not found")
+		}
+		return response.InternalServerError(c, "Failed to revoke session")
+	}
+
+	return response.Success(c, "Session revoked successfully", nil)
+}
+
+// clientInfo describes the client making the request
+// The device name comes from the optional X-Device-Name header, falling back to the user agent
+func clientInfo(c *fiber.Ctx) *models.ClientInfo {
+	userAgent := c.Get(fiber.HeaderUserAgent)
+
+	// Behind a trusted proxy (the gateway, a load balancer; see TRUSTED_PROXIES) the client
+	// address is the last X-Forwarded-For entry: that one was added by the proxy, earlier ones
+	// by the client. Anyone else could send a new header with every attempt, so theirs is ignored.
+	ip := c.IP()
+	if c.IsProxyTrusted() {
+		if ips := c.IPs(); len(ips) > 0 {
+			ip = ips[len(ips)-1]
+		}
+	}
+
+	device := c.Get("X-Device-Name")
+	if device == "" {
+		device = deviceFromUserAgent(userAgent)
+	}
+
+	return &models.ClientInfo{
+		Device:    truncate(device, 100),
+		IPAddress: ip,
+		UserAgent: truncate(userAgent, 512),
+	}
+}
+
+// deviceFromUserAgent returns a coarse device label for a user agent
+func deviceFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "":
+		return "Unknown device"
+	case strings.Contains(ua, "iphone"):
+		return "iPhone"
+	case strings.Contains(ua, "ipad"):
+		return "iPad"
+	case strings.Contains(ua, "android"):
+		return "Android"
+	case strings.Contains(ua, "windows"):
+		return "Windows"
+	case strings.Contains(ua, "mac os"):
+		return "Mac"
+	case strings.Contains(ua, "linux"):
+		return "Linux"
+	}
+	return "Unknown device"
+}
+
+// truncate limits client-supplied strings before they are stored
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
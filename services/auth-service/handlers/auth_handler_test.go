@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"zodiac-ai-backend/services/auth-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// clientIP returns the address clientInfo sees for a request with an X-Forwarded-For header
+// Requests made with app.Test come from 0.0.0.0
+func clientIP(t *testing.T, trustedProxies []string, forwardedFor string) string {
+	t.Helper()
+
+	app := fiber.New(fiber.Config{
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          trustedProxies,
+	})
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(clientInfo(c).IPAddress)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	if forwardedFor != "" {
+		req.Header.Set(fiber.HeaderXForwardedFor, forwardedFor)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestClientInfoIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		trusted      []string
+		forwardedFor string
+		want         string
+	}{
+		{name: "trusted proxy", trusted: []string{"0.0.0.0"}, forwardedFor: "203.0.113.7", want: "203.0.113.7"},
+		{name: "trusted proxy range", trusted: []string{"0.0.0.0/8"}, forwardedFor: "203.0.113.7", want: "203.0.113.7"},
+		{name: "client-supplied entries before the proxy's", trusted: []string{"0.0.0.0"}, forwardedFor: "198.51.100.1, 203.0.113.7", want: "203.0.113.7"},
+		{name: "trusted proxy without header", trusted: []string{"0.0.0.0"}, want: "0.0.0.0"},
+		{name: "untrusted peer", trusted: []string{"10.0.0.1"}, forwardedFor: "203.0.113.7", want: "0.0.0.0"},
+		{name: "no trusted proxies", forwardedFor: "203.0.113.7", want: "0.0.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clientIP(t, tt.trusted, tt.forwardedFor); got != tt.want {
+				t.Fatalf("client IP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockedOutRetryAfter(t *testing.T) {
+	tests := []struct {
+		lockedFor time.Duration
+		want      string
+	}{
+		{lockedFor: 90 * time.Second, want: "90"},
+		{lockedFor: 1500 * time.Millisecond, want: "2"}, // Rounded up: retrying earlier is refused
+		{lockedFor: time.Hour, want: "3600"},
+	}
+
+	for _, tt := range tests {
+		app := fiber.New()
+		until := time.Now().Add(tt.lockedFor)
+		app.Get("/", func(c *fiber.Ctx) error {
+			return lockedOut(c, &services.LockedError{Until: until})
+		})
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusTooManyRequests {
+			t.Fatalf("status %d, want 429", resp.StatusCode)
+		}
+		if got := resp.Header.Get(fiber.HeaderRetryAfter); got != tt.want {
+			t.Fatalf("locked for %s: Retry-After %q, want %q", tt.lockedFor, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+	"zodiac-ai-backend/services/auth-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BlockHandler handles user blocking HTTP requests
+type BlockHandler struct {
+	blockService *services.BlockService
+}
+
+// NewBlockHandler creates a new block handler
+func NewBlockHandler(blockService *services.BlockService) *BlockHandler {
+	return &BlockHandler{
+		blockService: blockService,
+	}
+}
+
+// Block blocks a user
+// POST /users/:id/block
+func (h *BlockHandler) Block(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	if err := h.blockService.Block(c.Context(), userID, c.Params("id")); err != nil {
+		switch err {
+		case repositories.ErrUserNotFound:
+			return response.NotFound(c, "User not found")
+		case services.ErrCannotBlockSelf:
+			return response.BadRequest(c, "You cannot block yourself", nil)
+		}
+		return response.InternalServerError(c, "Failed to block user")
+	}
+
+	return response.Success(c, "User blocked", nil)
+}
+
+// Unblock unblocks a user
+// DELETE /users/:id/block
+func (h *BlockHandler) Unblock(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	if err := h.blockService.Unblock(c.Context(), userID, c.Params("id")); err != nil {
+		if err == services.ErrNotBlocked {
+			return response.NotFound(c, "User is not blocked")
+		}
+		return response.InternalServerError(c, "Failed to unblock user")
+	}
+
+	return response.Success(c, "User unblocked", nil)
+}
+
+// GetBlocked lists the users the current user blocked
+// GET /users/me/blocks?cursor=&limit=
+func (h *BlockHandler) GetBlocked(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var query models.BlockListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	blocked, nextCursor, err := h.blockService.ListBlocked(c.Context(), userID, &query)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get blocked users")
+	}
+
+	return response.SuccessWithMeta(c, "Blocked users retrieved successfully", blocked, &response.MetaData{
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+		Limit:      query.Limit,
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"zodiac-ai-backend/pkg/middleware"
 	"zodiac-ai-backend/pkg/response"
 	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
 	"zodiac-ai-backend/services/auth-service/services"
 
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // FriendHandler handles friendship HTTP requests
@@ -34,15 +36,22 @@ func (h *FriendHandler) SendFriendRequest(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body", nil)
 	}
 
-	err := h.friendshipService.SendFriendRequest(c.Context(), userID, req.TargetUserID)
+	request, err := h.friendshipService.SendFriendRequest(c.Context(), userID, req.TargetUserID)
 	if err != nil {
-		if err == services.ErrAlreadyFriends {
+		switch err {
+		case services.ErrAlreadyFriends:
 			return response.Conflict(c, "Already friends")
+		case services.ErrRequestReceived:
+			return response.Conflict(c, "This user already sent you a friend request")
+		case services.ErrCannotFriendSelf:
+			return response.BadRequest(c, "You cannot send a friend request to yourself", nil)
+		case services.ErrUserBlocked:
+			return response.Forbidden(c, "You cannot send a friend request to this user")
 		}
 		return response.InternalServerError(c, "Failed to send friend request")
 	}
 
-	return response.Success(c, "Friend request sent successfully", nil)
+	return response.Success(c, "Friend request sent successfully", request)
 }
 
 // AcceptRejectRequest accepts or rejects a friend request
@@ -80,6 +89,9 @@ func (h *FriendHandler) AcceptRejectRequest(c *fiber.Ctx) error {
 		if err == services.ErrUnauthorized {
 			return response.Unauthorized(c, "Unauthorized action")
 		}
+		if err == services.ErrRequestNotPending {
+			return response.Conflict(c, "Friend request is no longer pending")
+		}
 		return response.InternalServerError(c, "Failed to process friend request")
 	}
 
@@ -91,6 +103,76 @@ func (h *FriendHandler) AcceptRejectRequest(c *fiber.Ctx) error {
 	return response.Success(c, message, nil)
 }
 
+// CancelFriendRequest withdraws a friend request the user sent
+// DELETE /friends/requests/:id
+func (h *FriendHandler) CancelFriendRequest(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	err := h.friendshipService.CancelFriendRequest(c.Context(), c.Params("id"), userID)
+	if err != nil {
+		switch err {
+		case services.ErrRequestNotFound:
+			return response.NotFound(c, "Friend request not found")
+		case services.ErrUnauthorized:
+			return response.Unauthorized(c, "Unauthorized action")
+		case services.ErrRequestNotPending:
+			return response.Conflict(c, "Friend request is no longer pending")
+		}
+		return response.InternalServerError(c, "Failed to cancel friend request")
+	}
+
+	return response.Success(c, "Friend request cancelled", nil)
+}
+
+// GetFriendRequests lists pending friend requests
+// GET /friends/requests?direction=incoming|outgoing&cursor=&limit=
+func (h *FriendHandler) GetFriendRequests(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var query models.FriendRequestQuery
+	if err := c.QueryParser(&query); err != nil {
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	requests, nextCursor, err := h.friendshipService.ListFriendRequests(c.Context(), userID, &query)
+	if err != nil {
+		if details, ok := validationDetails(err); ok {
+			return response.BadRequest(c, "Validation failed", details)
+		}
+		return response.InternalServerError(c, "Failed to get friend requests")
+	}
+
+	return response.SuccessWithMeta(c, "Friend requests retrieved successfully", requests, &response.MetaData{
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+		Limit:      query.Limit,
+	})
+}
+
+// Unfriend removes a friend
+// DELETE /friends/:user_id
+func (h *FriendHandler) Unfriend(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	if err := h.friendshipService.Unfriend(c.Context(), userID, c.Params("user_id")); err != nil {
+		if err == services.ErrNotFriends {
+			return response.NotFound(c, "Not friends with this user")
+		}
+		return response.InternalServerError(c, "Failed to remove friend")
+	}
+
+	return response.Success(c, "Friend removed", nil)
+}
+
 // GetFriends gets list of friends
 // GET /friends
 func (h *FriendHandler) GetFriends(c *fiber.Ctx) error {
@@ -99,14 +181,21 @@ func (h *FriendHandler) GetFriends(c *fiber.Ctx) error {
 		return response.Unauthorized(c, "User not authenticated")
 	}
 
-	friendIDs, err := h.friendshipService.GetFriends(c.Context(), userID)
+	friends, err := h.friendshipService.GetFriends(c.Context(), userID)
 	if err != nil {
 		return response.InternalServerError(c, "Failed to get friends")
 	}
 
+	// friend_ids is kept for clients that predate the friend summaries
+	friendIDs := make([]primitive.ObjectID, 0, len(friends))
+	for _, friend := range friends {
+		friendIDs = append(friendIDs, friend.ID)
+	}
+
 	return response.Success(c, "Friends retrieved successfully", fiber.Map{
+		"friends":    friends,
 		"friend_ids": friendIDs,
-		"count":      len(friendIDs),
+		"count":      len(friends),
 	})
 }
 
@@ -132,3 +221,25 @@ func (h *FriendHandler) CheckFriendshipStatus(c *fiber.Ctx) error {
 		"status": status,
 	})
 }
+
+// GetCompatibility rates the zodiac compatibility with another user
+// GET /friends/:id/compatibility
+func (h *FriendHandler) GetCompatibility(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	compatibility, err := h.friendshipService.GetCompatibility(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		switch err {
+		case repositories.ErrUserNotFound:
+			return response.NotFound(c, "User not found")
+		case services.ErrZodiacSignNotSet:
+			return response.Conflict(c, "Zodiac sign not set; complete the profile first")
+		}
+		return response.InternalServerError(c, "Failed to get compatibility")
+	}
+
+	return response.Success(c, "Compatibility retrieved successfully", compatibility)
+}
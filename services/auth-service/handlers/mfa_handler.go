@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+
+	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MFAHandler handles two-factor authentication HTTP requests
+type MFAHandler struct {
+	authService *services.AuthService
+	mfaService  *services.MFAService
+}
+
+// NewMFAHandler creates a new MFA handler
+func NewMFAHandler(authService *services.AuthService, mfaService *services.MFAService) *MFAHandler {
+	return &MFAHandler{
+		authService: authService,
+		mfaService:  mfaService,
+	}
+}
+
+// Enroll starts two-factor enrollment
+// POST /auth/mfa/enroll
+func (h *MFAHandler) Enroll(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	enrollment, err := h.mfaService.Enroll(c.Context(), userID)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Failed to start two-factor enrollment")
+	}
+
+	return response.Success(c, "Scan the QR code with your authenticator app, then confirm with a code", enrollment)
+}
+
+// Confirm enables two-factor authentication with the first code
+// POST /auth/mfa/confirm
+func (h *MFAHandler) Confirm(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req models.MFAConfirmRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	codes, err := h.mfaService.Confirm(c.Context(), userID, &req)
+	if err != nil {
+		return mfaErrorResponse(c, err, "Failed to enable two-factor authentication")
+	}
+
+	return response.Success(c, "Two-factor authentication enabled. Store the recovery codes somewhere safe", codes)
+}
+
+// Verify completes a two-factor login
+// POST /auth/mfa/verify
+func (h *MFAHandler) Verify(c *fiber.Ctx) error {
+	var req models.MFAVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	authResp, err := h.authService.VerifyMFA(c.Context(), &req, clientInfo(c))
+	if err != nil {
+		return mfaErrorResponse(c, err, "Failed to verify two-factor code")
+	}
+
+	return response.Success(c, "Login successful", authResp)
+}
+
+// Disable turns two-factor authentication off
+// POST /auth/mfa/disable
+func (h *MFAHandler) Disable(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req models.MFADisableRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.mfaService.Disable(c.Context(), userID, clientInfo(c).IPAddress, &req); err != nil {
+		return mfaErrorResponse(c, err, "Failed to disable two-factor authentication")
+	}
+
+	return response.Success(c, "Two-factor authentication disabled", nil)
+}
+
+// mfaErrorResponse maps MFA errors to HTTP responses
+func mfaErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	if details, ok := validationDetails(err); ok {
+		return response.BadRequest(c, "Validation failed", details)
+	}
+
+	var locked *services.LockedError
+	if errors.As(err, &locked) {
+		return lockedOut(c, locked)
+	}
+
+	switch err {
+	case services.ErrInvalidMFACode:
+		return response.Unauthorized(c, "Invalid two-factor code")
+	case services.ErrInvalidMFAToken:
+		return response.Unauthorized(c, "Invalid or expired MFA token, please log in again")
+	case services.ErrInvalidCredentials:
+		return response.Unauthorized(c, "Invalid password")
+	case services.ErrMFAAlreadyEnabled:
+		return response.Conflict(c, "Two-factor authentication is already enabled")
+	case services.ErrMFANotEnabled:
+		return response.BadRequest(c, "Two-factor authentication is not enabled", nil)
+	case services.ErrMFANotEnrolled:
+		return response.BadRequest(c, "Start two-factor enrollment first", nil)
+	case services.ErrAccountSuspended:
+		return response.Forbidden(c, "Account suspended")
+	}
+
+	return response.InternalServerError(c, fallback)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+
+	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/services"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OIDCHandler handles social login HTTP requests
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+}
+
+// NewOIDCHandler creates a new OIDC handler
+func NewOIDCHandler(oidcService *services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// GetProviders lists the configured login providers
+// GET /auth/oidc/providers
+func (h *OIDCHandler) GetProviders(c *fiber.Ctx) error {
+	return response.Success(c, "Providers retrieved successfully", fiber.Map{
+		"providers": h.oidcService.Providers(),
+	})
+}
+
+// Authorize starts a provider login
+// GET /auth/oidc/:provider/authorize
+func (h *OIDCHandler) Authorize(c *fiber.Ctx) error {
+	authorize, err := h.oidcService.Authorize(c.Context(), c.Params("provider"), nil)
+	if err != nil {
+		return oidcErrorResponse(c, err, "Failed to start login")
+	}
+
+	return response.Success(c, "Redirect the user to the authorization URL", authorize)
+}
+
+// Callback completes a provider login
+// POST /auth/oidc/:provider/callback
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	var req models.OIDCCallbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	authResp, err := h.oidcService.Callback(c.Context(), c.Params("provider"), &req, clientInfo(c))
+	if err != nil {
+		return oidcErrorResponse(c, err, "Failed to login")
+	}
+
+	if authResp.MFARequired {
+		return response.Success(c, "Two-factor code required", authResp)
+	}
+
+	return response.Success(c, "Login successful", authResp)
+}
+
+// StartLink starts linking a provider account to the logged in user
+// GET /auth/oidc/:provider/link
+func (h *OIDCHandler) StartLink(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	authorize, err := h.oidcService.Authorize(c.Context(), c.Params("provider"), &id)
+	if err != nil {
+		return oidcErrorResponse(c, err, "Failed to start linking")
+	}
+
+	return response.Success(c, "Redirect the user to the authorization URL", authorize)
+}
+
+// Link completes linking a provider account to the logged in user
+// POST /auth/oidc/:provider/link
+func (h *OIDCHandler) Link(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req models.OIDCCallbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	user, err := h.oidcService.Link(c.Context(), userID, c.Params("provider"), &req)
+	if err != nil {
+		return oidcErrorResponse(c, err, "Failed to link account")
+	}
+
+	return response.Success(c, "Account linked successfully", user)
+}
+
+// oidcErrorResponse maps OIDC errors to HTTP responses
+func oidcErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	if details, ok := validationDetails(err); ok {
+		return response.BadRequest(c, "Validation failed", details)
+	}
+
+	var locked *services.LockedError
+	if errors.As(err, &locked) {
+		return lockedOut(c, locked)
+	}
+
+	switch err {
+	case services.ErrUnknownProvider:
+		return response.NotFound(c, "Unknown login provider")
+	case services.ErrInvalidOIDCState:
+		return response.BadRequest(c, "Invalid or expired login state, please start again", nil)
+	case services.ErrOIDCLoginFailed:
+		return response.Unauthorized(c, "Login with the provider failed")
+	case services.ErrOIDCEmailRequired:
+		return response.BadRequest(c, "The provider did not share an email address", nil)
+	case services.ErrOIDCEmailConflict:
+		return response.Conflict(c, "An account with this email already exists. Log in and link the provider from your account")
+	case services.ErrIdentityLinked:
+		return response.Conflict(c, "This provider account is linked to another user")
+	case services.ErrProviderAlreadyLinked:
+		return response.Conflict(c, "A different account at this provider is already linked")
+	case services.ErrAccountDeleted:
+		return response.Forbidden(c, "This account has been deleted")
+	case services.ErrAccountSuspended:
+		return response.Forbidden(c, "Account suspended")
+	}
+
+	return response.InternalServerError(c, fallback)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PrivacyHandler handles data export and account deletion HTTP requests
+type PrivacyHandler struct {
+	privacyService *services.PrivacyService
+}
+
+// NewPrivacyHandler creates a new privacy handler
+func NewPrivacyHandler(privacyService *services.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+	}
+}
+
+// Export downloads everything stored about the user
+// GET /users/me/export?format=zip|json
+func (h *PrivacyHandler) Export(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	format := c.Query("format", "zip")
+	if format != "zip" && format != "json" {
+		return response.BadRequest(c, "Invalid format, expected zip or json", nil)
+	}
+
+	sections, err := h.privacyService.Export(c.Context(), userID)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to export data")
+	}
+
+	var buf bytes.Buffer
+	write := services.WriteExportZip
+	contentType := "application/zip"
+	if format == "json" {
+		write = services.WriteExportJSON
+		contentType = fiber.MIMEApplicationJSONCharsetUTF8
+	}
+	if err := write(&buf, sections); err != nil {
+		return response.InternalServerError(c, "Failed to export data")
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="zodiac-ai-export-%s.%s"`, time.Now().Format("2006-01-02"), format))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Send(buf.Bytes())
+}
+
+// DeleteAccount deletes the user's account and all their data
+// DELETE /users/me
+func (h *PrivacyHandler) DeleteAccount(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	// The body is optional: accounts without a password send none
+	var req models.DeleteAccountRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body", nil)
+		}
+	}
+
+	deletion, err := h.privacyService.RequestDeletion(c.Context(), userID, clientInfo(c).IPAddress, &req)
+	if err != nil {
+		var locked *services.LockedError
+		if errors.As(err, &locked) {
+			return lockedOut(c, locked)
+		}
+		if err == services.ErrInvalidCredentials {
+			return response.Unauthorized(c, "Invalid password")
+		}
+		return response.InternalServerError(c, "Failed to delete account")
+	}
+
+	return response.Accepted(c, "Account deletion started", deletion)
+}
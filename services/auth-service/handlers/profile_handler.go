@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+	"zodiac-ai-backend/services/auth-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProfileHandler handles public profile, user search and privacy HTTP requests
+type ProfileHandler struct {
+	profileService *services.ProfileService
+}
+
+// NewProfileHandler creates a new profile handler
+func NewProfileHandler(profileService *services.ProfileService) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+	}
+}
+
+// GetProfile gets another user's profile, as far as their privacy settings allow
+// GET /users/:id
+func (h *ProfileHandler) GetProfile(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	profile, err := h.profileService.GetProfile(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		if err == repositories.ErrUserNotFound {
+			return response.NotFound(c, "User not found")
+		}
+		return response.InternalServerError(c, "Failed to get profile")
+	}
+
+	return response.Success(c, "Profile retrieved successfully", profile)
+}
+
+// Search searches users by display name prefix and zodiac sign
+// GET /users/search?q=&zodiac=&cursor=&limit=
+func (h *ProfileHandler) Search(c *fiber.Ctx) error {
+	var query models.UserSearchQuery
+	if err := c.QueryParser(&query); err != nil {
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	users, nextCursor, err := h.profileService.Search(c.Context(), &query)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to search users")
+	}
+
+	return response.SuccessWithMeta(c, "Users retrieved successfully", users, &response.MetaData{
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+		Limit:      query.Limit,
+	})
+}
+
+// Batch gets the summaries of several users
+// POST /users/batch
+func (h *ProfileHandler) Batch(c *fiber.Ctx) error {
+	var req models.BatchUsersRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	users, err := h.profileService.Batch(c.Context(), &req)
+	if err != nil {
+		if details, ok := validationDetails(err); ok {
+			return response.BadRequest(c, "Validation failed", details)
+		}
+		return response.InternalServerError(c, "Failed to get users")
+	}
+
+	return response.Success(c, "Users retrieved successfully", users)
+}
+
+// UpdatePrivacy changes the user's privacy settings
+// PUT /users/me/privacy
+func (h *ProfileHandler) UpdatePrivacy(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req models.UpdatePrivacyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	privacy, err := h.profileService.UpdatePrivacy(c.Context(), userID, &req)
+	if err != nil {
+		if details, ok := validationDetails(err); ok {
+			return response.BadRequest(c, "Validation failed", details)
+		}
+		return response.InternalServerError(c, "Failed to update privacy settings")
+	}
+
+	return response.Success(c, "Privacy settings updated", privacy)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SuggestionHandler handles friend suggestion HTTP requests
+type SuggestionHandler struct {
+	suggestionService *services.SuggestionService
+}
+
+// NewSuggestionHandler creates a new suggestion handler
+func NewSuggestionHandler(suggestionService *services.SuggestionService) *SuggestionHandler {
+	return &SuggestionHandler{
+		suggestionService: suggestionService,
+	}
+}
+
+// GetSuggestions suggests users to befriend, best first
+// GET /friends/suggestions?limit=
+func (h *SuggestionHandler) GetSuggestions(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var query models.FriendSuggestionQuery
+	if err := c.QueryParser(&query); err != nil {
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	suggestions, err := h.suggestionService.GetSuggestions(c.Context(), userID, &query)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get friend suggestions")
+	}
+
+	return response.Success(c, "Friend suggestions retrieved successfully", suggestions)
+}
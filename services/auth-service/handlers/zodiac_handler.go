@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ZodiacHandler handles zodiac reference HTTP requests
+type ZodiacHandler struct{}
+
+// NewZodiacHandler creates a new zodiac handler
+func NewZodiacHandler() *ZodiacHandler {
+	return &ZodiacHandler{}
+}
+
+// GetCompatibility rates the compatibility of two signs
+// GET /zodiac/compatibility?a=Leo&b=Libra
+func (h *ZodiacHandler) GetCompatibility(c *fiber.Ctx) error {
+	signA, okA := utils.ParseZodiacSign(c.Query("a"))
+	signB, okB := utils.ParseZodiacSign(c.Query("b"))
+	if !okA || !okB {
+		return response.BadRequest(c, "Query parameters a and b must be zodiac signs, e.g. a=Leo&b=Libra", nil)
+	}
+
+	compatibility, err := utils.CompareSigns(signA, signB)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get compatibility")
+	}
+
+	return response.Success(c, "Compatibility retrieved successfully", compatibility)
+}
@@ -12,7 +12,9 @@ import (
 	"zodiac-ai-backend/pkg/config"
 	"zodiac-ai-backend/pkg/database"
 	"zodiac-ai-backend/pkg/jwt"
+	"zodiac-ai-backend/pkg/mailer"
 	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/oidc"
 	"zodiac-ai-backend/services/auth-service/handlers"
 	"zodiac-ai-backend/services/auth-service/repositories"
 	"zodiac-ai-backend/services/auth-service/services"
@@ -23,6 +25,9 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Connect to MongoDB
 	_, err := database.Connect(database.MongoConfig{
@@ -37,26 +42,118 @@ func main() {
 	db := database.GetDatabase(cfg.MongoDatabase)
 
 	// Initialize JWT manager
-	jwtManager := jwt.NewManager(
-		cfg.JWTSecret,
-		cfg.JWTAccessExpiry,
-		cfg.JWTRefreshExpiry,
-	)
+	jwtManager, err := jwt.New(jwt.Config{
+		Secret:            cfg.JWTSecret,
+		Keys:              cfg.JWTKeys,
+		ActiveKeyID:       cfg.JWTActiveKeyID,
+		AcceptLegacyHS256: cfg.JWTAcceptHS256,
+		AccessExpiry:      cfg.JWTAccessExpiry,
+		RefreshExpiry:     cfg.JWTRefreshExpiry,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize JWT manager: %v", err)
+	}
+	if !jwtManager.CanSign() {
+		log.Fatalf("JWT manager can't sign tokens: the active key in JWT_KEYS needs its private key")
+	}
 
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
 	refreshTokenRepo := repositories.NewRefreshTokenRepository(db)
+	userTokenRepo := repositories.NewUserTokenRepository(db)
+	loginAttemptRepo := repositories.NewLoginAttemptRepository(db)
+	auditLogRepo := repositories.NewAuditLogRepository(db)
+	oidcStateRepo := repositories.NewOIDCStateRepository(db)
+	accountDeletionRepo := repositories.NewAccountDeletionRepository(db)
+	userDataRepo := repositories.NewUserDataRepository(db)
+	friendshipRepo := repositories.NewFriendshipRepository(db)
+	blockRepo := repositories.NewBlockRepository(db)
+
+	// Access tokens of suspended users and logged out sessions are rejected (cached briefly)
+	suspensionCache := middleware.NewSuspensionCache(userRepo, cfg.SessionCacheTTL)
+	sessionCache := middleware.NewRevocationCache(refreshTokenRepo, cfg.SessionCacheTTL)
+	authMiddleware := middleware.AuthMiddleware(jwtManager, suspensionCache.Validate, sessionCache.Validate)
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo, refreshTokenRepo, jwtManager)
+	loginGuard := services.NewLoginGuard(loginAttemptRepo, auditLogRepo, services.LockoutConfig{
+		MaxAttempts:   cfg.LoginMaxAttempts,
+		IPMaxAttempts: cfg.LoginIPMaxAttempts,
+		Window:        cfg.LoginAttemptWindow,
+		BaseLockout:   cfg.LoginLockoutBase,
+		MaxLockout:    cfg.LoginLockoutMax,
+	})
+	authService := services.NewAuthService(userRepo, refreshTokenRepo, jwtManager, loginGuard)
+	mfaService := services.NewMFAService(userRepo, loginGuard, cfg.MFAIssuer)
+
+	// Social login providers (OIDC_PROVIDERS)
+	oidcProviders := make([]*oidc.Provider, 0, len(cfg.OIDCProviders))
+	for _, providerCfg := range cfg.OIDCProviders {
+		provider, err := oidc.NewProvider(oidc.Config{
+			Name:         providerCfg.Name,
+			Issuer:       providerCfg.Issuer,
+			ClientID:     providerCfg.ClientID,
+			ClientSecret: providerCfg.ClientSecret,
+			RedirectURL:  providerCfg.RedirectURL,
+			Scopes:       providerCfg.Scopes,
+			ResponseMode: providerCfg.ResponseMode,
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize login provider: %v", err)
+		}
+		oidcProviders = append(oidcProviders, provider)
+	}
+	oidcService := services.NewOIDCService(oidcProviders, oidcStateRepo, userRepo, authService)
+
+	mail, err := mailer.New(mailer.Config{
+		Backend:  cfg.Mailer,
+		From:     cfg.MailFrom,
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		Dir:      cfg.MailDir,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+	accountService := services.NewAccountService(userRepo, userTokenRepo, refreshTokenRepo, mail, cfg.AppURL)
+
+	// Account deletions run in the background and resume after a restart
+	deletionWorker := services.NewDeletionWorker(accountDeletionRepo, userDataRepo, cfg.AccountDeletionPollInterval)
+	deletionWorker.Start()
+	defer deletionWorker.Stop()
+	privacyService := services.NewPrivacyService(userRepo, refreshTokenRepo, userDataRepo, accountDeletionRepo, loginGuard, deletionWorker)
+	adminService := services.NewAdminService(userRepo, refreshTokenRepo, auditLogRepo)
+	profileService := services.NewProfileService(userRepo, friendshipRepo)
+	// Friendship and block changes invalidate the suggestions of the users involved
+	suggestionCache := services.NewSuggestionCache(cfg.SuggestionCacheTTL)
+	blockService := services.NewBlockService(blockRepo, friendshipRepo, userRepo, suggestionCache)
+	friendshipService := services.NewFriendshipService(friendshipRepo, userRepo, blockRepo, suggestionCache)
+	suggestionService := services.NewSuggestionService(friendshipRepo, userRepo, blockRepo, suggestionCache, services.SuggestionWeights{
+		MutualFriends: cfg.SuggestionMutualWeight,
+		Zodiac:        cfg.SuggestionZodiacWeight,
+	})
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
+	authHandler := handlers.NewAuthHandler(authService, accountService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	mfaHandler := handlers.NewMFAHandler(authService, mfaService)
+	oidcHandler := handlers.NewOIDCHandler(oidcService)
+	privacyHandler := handlers.NewPrivacyHandler(privacyService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	profileHandler := handlers.NewProfileHandler(profileService)
+	blockHandler := handlers.NewBlockHandler(blockService)
+	friendHandler := handlers.NewFriendHandler(friendshipService)
+	suggestionHandler := handlers.NewSuggestionHandler(suggestionService)
+	zodiacHandler := handlers.NewZodiacHandler()
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:      "Zodiac AI - Auth Service",
 		ErrorHandler: customErrorHandler,
+		// X-Forwarded-For is only believed from these (see clientInfo in the auth handlers)
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxies,
 	})
 
 	// Middleware
@@ -65,6 +162,9 @@ func main() {
 	app.Use(middleware.SetupCORS())
 	app.Use(middleware.RequestID())
 
+	// Token verification keys (public)
+	app.Get("/.well-known/jwks.json", authHandler.JWKS)
+
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -91,12 +191,66 @@ func main() {
 	auth.Post("/register", authHandler.Register)
 	auth.Post("/login", authHandler.Login)
 	auth.Post("/refresh", authHandler.RefreshToken)
+	auth.Post("/logout", authHandler.Logout)
+	auth.Post("/logout-all", authMiddleware, authHandler.LogoutAll)
+	auth.Post("/forgot-password", accountHandler.ForgotPassword)
+	auth.Post("/reset-password", accountHandler.ResetPassword)
+	auth.Post("/verify-email", accountHandler.VerifyEmail)
+	auth.Post("/resend-verification", authMiddleware, accountHandler.ResendVerification)
+	auth.Post("/mfa/enroll", authMiddleware, mfaHandler.Enroll)
+	auth.Post("/mfa/confirm", authMiddleware, mfaHandler.Confirm)
+	auth.Post("/mfa/verify", mfaHandler.Verify)
+	auth.Post("/mfa/disable", authMiddleware, mfaHandler.Disable)
+	auth.Get("/oidc/providers", oidcHandler.GetProviders)
+	auth.Get("/oidc/:provider/authorize", oidcHandler.Authorize)
+	auth.Post("/oidc/:provider/callback", oidcHandler.Callback)
+	auth.Get("/oidc/:provider/link", authMiddleware, oidcHandler.StartLink)
+	auth.Post("/oidc/:provider/link", authMiddleware, oidcHandler.Link)
 
 	// User routes (protected)
 	users := api.Group("/users")
-	users.Use(middleware.AuthMiddleware(jwtManager))
+	users.Use(authMiddleware)
 	users.Get("/me", authHandler.GetProfile)
 	users.Put("/me", authHandler.UpdateProfile)
+	users.Delete("/me", privacyHandler.DeleteAccount)
+	users.Get("/me/export", privacyHandler.Export)
+	users.Post("/me/complete-profile", authHandler.CompleteProfile)
+	users.Get("/me/sessions", authHandler.GetSessions)
+	users.Delete("/me/sessions/:id", authHandler.RevokeSession)
+	users.Put("/me/privacy", profileHandler.UpdatePrivacy)
+	users.Get("/me/blocks", blockHandler.GetBlocked)
+	users.Get("/search", profileHandler.Search)
+	users.Post("/batch", profileHandler.Batch)
+	users.Get("/:id", profileHandler.GetProfile) // After the /me, /search and /batch routes
+	users.Post("/:id/block", blockHandler.Block)
+	users.Delete("/:id/block", blockHandler.Unblock)
+
+	// Friend routes (protected)
+	friends := api.Group("/friends")
+	friends.Use(authMiddleware)
+	friends.Post("/requests", friendHandler.SendFriendRequest)
+	friends.Get("/requests", friendHandler.GetFriendRequests)
+	friends.Put("/requests/:id", friendHandler.AcceptRejectRequest)
+	friends.Delete("/requests/:id", friendHandler.CancelFriendRequest)
+	friends.Get("", friendHandler.GetFriends)
+	friends.Get("/suggestions", suggestionHandler.GetSuggestions)
+	friends.Get("/status/:user_id", friendHandler.CheckFriendshipStatus)
+	friends.Get("/:id/compatibility", friendHandler.GetCompatibility)
+	friends.Delete("/:user_id", friendHandler.Unfriend)
+
+	// Zodiac routes (public)
+	zodiac := api.Group("/zodiac")
+	zodiac.Get("/compatibility", zodiacHandler.GetCompatibility)
+
+	// Admin routes (admins and moderators; roles are admin only)
+	admin := api.Group("/admin/users")
+	admin.Use(authMiddleware, middleware.RequireRole(jwt.RoleAdmin, jwt.RoleModerator))
+	admin.Get("/", adminHandler.ListUsers)
+	admin.Get("/:id", adminHandler.GetUser)
+	admin.Post("/:id/suspend", adminHandler.Suspend)
+	admin.Post("/:id/unsuspend", adminHandler.Unsuspend)
+	admin.Post("/:id/logout", adminHandler.ForceLogout)
+	admin.Put("/:id/roles", middleware.RequireRole(jwt.RoleAdmin), adminHandler.SetRoles)
 
 	// Start server
 	port := cfg.AuthServicePort
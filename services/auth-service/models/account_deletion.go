@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccountDeletion statuses
+const (
+	DeletionPending   = "pending"
+	DeletionCompleted = "completed"
+)
+
+// AccountDeletion is the job removing a deleted account's data from every collection
+// Steps run in order and are recorded as they finish, so a job interrupted by a crash or
+// restart resumes where it stopped. Each step is idempotent.
+// Indexes:
+//   - user_id: unique index (one job per account)
+//   - (status, lease_until): index for claiming jobs
+type AccountDeletion struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Email          string             `bson:"email" json:"-"` // For login_attempts keys; removed on completion
+	Status         string             `bson:"status" json:"status"`
+	CompletedSteps []string           `bson:"completed_steps" json:"completed_steps"`
+
+	// Records whose denormalized counters need recounting, captured before anything is deleted
+	AffectedUserIDs []primitive.ObjectID `bson:"affected_user_ids,omitempty" json:"-"` // friends_count
+	AffectedPostIDs []primitive.ObjectID `bson:"affected_post_ids,omitempty" json:"-"` // likes_count, comments_count
+	AffectedRoomIDs []primitive.ObjectID `bson:"affected_room_ids,omitempty" json:"-"` // member_count
+
+	Attempts    int        `bson:"attempts" json:"attempts"`
+	LastError   string     `bson:"last_error,omitempty" json:"-"`
+	LeaseUntil  *time.Time `bson:"lease_until,omitempty" json:"-"` // A worker owns the job until then
+	RequestedAt time.Time  `bson:"requested_at" json:"requested_at"`
+	CompletedAt *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+// HasCompleted reports whether a step already ran
+func (d *AccountDeletion) HasCompleted(step string) bool {
+	for _, done := range d.CompletedSteps {
+		if done == step {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteAccountRequest confirms an account deletion
+// The password is required for accounts that have one (social login accounts may not)
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"zodiac-ai-backend/pkg/jwt"
+)
+
+// IsSuspended reports whether the user's suspension is in effect
+func (u *User) IsSuspended() bool {
+	return u.Suspended && (u.SuspendedUntil == nil || u.SuspendedUntil.After(time.Now()))
+}
+
+// HasRole reports whether the user has at least one of the roles
+func (u *User) HasRole(roles ...string) bool {
+	return jwt.HasRole(u.Roles, roles...)
+}
+
+// AdminUserQuery filters the admin user list
+type AdminUserQuery struct {
+	Query     string `query:"q"`         // Start of the email, full name or display name
+	Role      string `query:"role"`      // admin or moderator
+	Suspended bool   `query:"suspended"` // Only currently suspended users
+	Cursor    string `query:"cursor"`
+	Limit     int    `query:"limit"`
+}
+
+// SuspendUserRequest suspends a user
+type SuspendUserRequest struct {
+	Reason          string `json:"reason" validate:"required,max=500"`
+	DurationMinutes int    `json:"duration_minutes" validate:"omitempty,min=1"` // Omitted: until lifted
+}
+
+// UpdateRolesRequest replaces a user's roles (an empty list removes them all)
+type UpdateRolesRequest struct {
+	Roles []string `json:"roles" validate:"dive,oneof=admin moderator"`
+}
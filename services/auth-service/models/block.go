@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Block represents a user blocking another user
+// Blocks hide both users from each other: friend requests, room frames, comments and the feed.
+// Indexes:
+//   - {blocker_id: 1, blocked_id: 1}: unique
+//   - blocked_id: index for finding who blocked a user
+type Block struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BlockerID primitive.ObjectID `bson:"blocker_id" json:"blocker_id"`
+	BlockedID primitive.ObjectID `bson:"blocked_id" json:"blocked_id"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// BlockListQuery represents the query parameters for listing blocked users
+type BlockListQuery struct {
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit"`
+}
+
+// BlockedUser is a blocked user's summary with when they were blocked
+type BlockedUser struct {
+	User      *UserSummary `json:"user"`
+	BlockedAt time.Time    `json:"blocked_at"`
+}
@@ -10,9 +10,11 @@ import (
 type FriendshipStatus string
 
 const (
-	StatusPending  FriendshipStatus = "PENDING"
-	StatusAccepted FriendshipStatus = "ACCEPTED"
-	StatusRejected FriendshipStatus = "REJECTED"
+	StatusPending    FriendshipStatus = "PENDING"
+	StatusAccepted   FriendshipStatus = "ACCEPTED"
+	StatusRejected   FriendshipStatus = "REJECTED"
+	StatusCancelled  FriendshipStatus = "CANCELLED"
+	StatusUnfriended FriendshipStatus = "UNFRIENDED" // Accepted, then the friendship was removed
 )
 
 // Friendship represents denormalized friendship graph
@@ -30,6 +32,10 @@ type Friendship struct {
 }
 
 // FriendRequest represents a friend request (for transaction tracking)
+// There is at most one document per sender and receiver; it is reset when a request is sent again
+// Indexes:
+//   - {sender_id: 1, receiver_id: 1}: unique
+//   - {receiver_id: 1, status: 1}: incoming requests
 type FriendRequest struct {
 	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	SenderID   primitive.ObjectID `bson:"sender_id" json:"sender_id"`
@@ -44,6 +50,21 @@ type SendFriendRequestInput struct {
 	TargetUserID string `json:"target_user_id" validate:"required"`
 }
 
+// FriendRequestQuery represents the query parameters for listing pending friend requests
+type FriendRequestQuery struct {
+	Direction string `query:"direction" validate:"omitempty,oneof=incoming outgoing"`
+	Cursor    string `query:"cursor"`
+	Limit     int    `query:"limit"`
+}
+
+// PendingFriendRequest is a pending friend request with the other user's summary
+// (the sender for incoming requests, the receiver for outgoing ones)
+type PendingFriendRequest struct {
+	ID        primitive.ObjectID `json:"id"`
+	User      *UserSummary       `json:"user"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
 // AcceptRejectRequestInput represents accept/reject input
 type AcceptRejectRequestInput struct {
 	Action string `json:"action" validate:"required,oneof=accept reject"`
@@ -53,3 +74,22 @@ type AcceptRejectRequestInput struct {
 type FriendStatusResponse struct {
 	Status string `json:"status"` // ARE_FRIENDS, PENDING, NOT_FRIENDS
 }
+
+// MutualFriendCount is a user who isn't a friend yet and how many friends they share
+type MutualFriendCount struct {
+	UserID        primitive.ObjectID `bson:"_id"`
+	MutualFriends int                `bson:"mutual_friends"`
+}
+
+// FriendSuggestionQuery represents the query parameters for friend suggestions
+type FriendSuggestionQuery struct {
+	Limit int `query:"limit"`
+}
+
+// FriendSuggestion is a suggested friend with why they were suggested
+type FriendSuggestion struct {
+	User          *UserSummary `json:"user"`
+	MutualFriends int          `json:"mutual_friends"`
+	Compatibility float64      `json:"compatibility"` // Zodiac compatibility, 0 to 1
+	Score         float64      `json:"score"`         // Ranking score
+}
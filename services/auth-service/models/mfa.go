@@ -0,0 +1,35 @@
+package models
+
+// MFAEnrollment is returned when two-factor enrollment starts
+// The secret is shown for manual entry, the URI as a QR code
+type MFAEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFARecoveryCodes are shown once, when two-factor authentication is enabled
+type MFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAConfirmRequest represents the first code confirming an enrollment
+type MFAConfirmRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// MFAVerifyRequest represents the second step of a login
+// Either a TOTP code or an unused recovery code is required
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+}
+
+// MFADisableRequest represents a request to turn two-factor authentication off
+// A current code (or recovery code) is required, and the password for accounts that have one
+// (social login accounts may not)
+type MFADisableRequest struct {
+	Password     string `json:"password"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Identity links a user to an account at an OpenID Connect provider
+// Indexes (on users):
+//   - (identities.provider, identities.subject): unique, one user per provider account
+type Identity struct {
+	Provider string    `bson:"provider" json:"provider"`
+	Subject  string    `bson:"subject" json:"-"` // Stable user ID at the provider
+	Email    string    `bson:"email,omitempty" json:"email,omitempty"`
+	LinkedAt time.Time `bson:"linked_at" json:"linked_at"`
+}
+
+// OIDCState is a pending provider login, created when the user is sent to the provider
+// Only the SHA-256 hash of the state parameter is stored; the PKCE verifier never leaves the server.
+// Indexes:
+//   - state_hash: unique index for lookup
+//   - expires_at: TTL index (abandoned logins are removed)
+type OIDCState struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	StateHash string              `bson:"state_hash" json:"-"`
+	Provider  string              `bson:"provider" json:"provider"`
+	Verifier  string              `bson:"verifier" json:"-"` // PKCE code verifier
+	Nonce     string              `bson:"nonce" json:"-"`
+	UserID    *primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"` // Set when linking to a logged in user
+	ExpiresAt time.Time           `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// OIDCAuthorizeResponse tells the client where to send the user
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+// OIDCCallbackRequest carries the provider's redirect parameters
+// Accepted as JSON or as a form post (Apple's response_mode=form_post)
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" form:"code" validate:"required"`
+	State string `json:"state" form:"state" validate:"required"`
+	User  string `json:"user" form:"user"` // Apple only: JSON with the user's name, sent on the first login
+}
+
+// CompleteProfileRequest collects what provider sign-ups lack
+// The zodiac sign is calculated from the date of birth, as at registration
+type CompleteProfileRequest struct {
+	DateOfBirth string `json:"date_of_birth" validate:"required"`
+	Gender      string `json:"gender" validate:"required,oneof=male female other"`
+	FullName    string `json:"full_name"`
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Profile visibility levels
+const (
+	VisibilityPublic  = "public"  // Anyone signed in sees the full profile
+	VisibilityFriends = "friends" // Friends see the full profile, others the summary
+	VisibilityPrivate = "private" // Only the summary, and left out of search
+)
+
+// PrivacySettings controls what other users see of a profile
+type PrivacySettings struct {
+	ProfileVisibility string `bson:"profile_visibility,omitempty" json:"profile_visibility"`
+	HideDateOfBirth   bool   `bson:"hide_date_of_birth,omitempty" json:"hide_date_of_birth"`
+}
+
+// Visibility returns the profile visibility, public for users who never changed it
+func (p PrivacySettings) Visibility() string {
+	if p.ProfileVisibility == "" {
+		return VisibilityPublic
+	}
+	return p.ProfileVisibility
+}
+
+// UserSummary is the part of a profile every signed-in user can see
+// Enough to show a user in lists (friends, search, comments, chats)
+type UserSummary struct {
+	ID          primitive.ObjectID `json:"id"`
+	DisplayName string             `json:"display_name"`
+	AvatarURL   string             `json:"avatar_url"`
+	ZodiacSign  string             `json:"zodiac_sign"`
+}
+
+// ProfileDetails is the part of a profile that follows its visibility setting
+type ProfileDetails struct {
+	Bio          string     `json:"bio"`
+	DateOfBirth  *time.Time `json:"date_of_birth,omitempty"` // Left out when hidden
+	Gender       string     `json:"gender"`
+	TotalPosts   int        `json:"total_posts"`
+	FriendsCount int        `json:"friends_count"`
+	MemberSince  time.Time  `json:"member_since"`
+}
+
+// PublicProfile is a user's profile as another user sees it
+// Restricted profiles (private, or friends only for a non-friend) carry no details
+type PublicProfile struct {
+	UserSummary
+	Restricted bool `json:"restricted"`
+	*ProfileDetails
+}
+
+// Summary returns the user's summary
+func (u *User) Summary() *UserSummary {
+	return &UserSummary{
+		ID:          u.ID,
+		DisplayName: u.DisplayName,
+		AvatarURL:   u.AvatarURL,
+		ZodiacSign:  u.ZodiacSign,
+	}
+}
+
+// PublicProfile returns the user's profile as seen by a viewer
+// isFriend reports whether the viewer is a friend of the user
+func (u *User) PublicProfile(isFriend bool) *PublicProfile {
+	profile := &PublicProfile{UserSummary: *u.Summary()}
+
+	switch u.Privacy.Visibility() {
+	case VisibilityPrivate:
+		profile.Restricted = true
+		return profile
+	case VisibilityFriends:
+		if !isFriend {
+			profile.Restricted = true
+			return profile
+		}
+	}
+
+	profile.ProfileDetails = &ProfileDetails{
+		Bio:          u.Bio,
+		Gender:       u.Gender,
+		TotalPosts:   u.TotalPosts,
+		FriendsCount: u.FriendsCount,
+		MemberSince:  u.CreatedAt,
+	}
+	if !u.Privacy.HideDateOfBirth && !u.DateOfBirth.IsZero() {
+		dateOfBirth := u.DateOfBirth
+		profile.DateOfBirth = &dateOfBirth
+	}
+	return profile
+}
+
+// UserSearchQuery filters the user search
+type UserSearchQuery struct {
+	Query      string `query:"q"`      // Start of the display name
+	ZodiacSign string `query:"zodiac"` // Exact sign, e.g. Aries
+	Cursor     string `query:"cursor"`
+	Limit      int    `query:"limit"`
+}
+
+// BatchUsersRequest looks up several users at once
+type BatchUsersRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=100"`
+}
+
+// UpdatePrivacyRequest changes privacy settings; omitted fields are left unchanged
+type UpdatePrivacyRequest struct {
+	ProfileVisibility *string `json:"profile_visibility" validate:"omitempty,oneof=public friends private"`
+	HideDateOfBirth   *bool   `json:"hide_date_of_birth"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginAttempt tracks failed logins for one key ("email:<address>" or "ip:<address>")
+// Indexes:
+//   - _id: the key
+//   - expires_at: TTL index (forgets the key, including its lockout count, after a quiet period)
+type LoginAttempt struct {
+	Key           string     `bson:"_id" json:"key"`
+	Failures      int        `bson:"failures" json:"failures"` // Failures since the last lockout, within the window
+	Lockouts      int        `bson:"lockouts" json:"lockouts"` // Lockouts so far; each one doubles the next duration
+	LastFailureAt time.Time  `bson:"last_failure_at" json:"last_failure_at"`
+	LockedUntil   *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	ExpiresAt     time.Time  `bson:"expires_at" json:"expires_at"`
+}
+
+// Audit events
+const (
+	AuditLoginLockout    = "login_lockout"
+	AuditUserSuspended   = "user_suspended"
+	AuditUserUnsuspended = "user_unsuspended"
+	AuditForcedLogout    = "forced_logout"
+	AuditRolesChanged    = "roles_changed"
+	AuditPostDeleted     = "post_deleted"    // By a moderator; user_id is the author
+	AuditCommentDeleted  = "comment_deleted" // By a moderator; user_id is the author
+	AuditRoomDeleted     = "room_deleted"    // By a moderator; user_id is the owner
+)
+
+// AuditLog represents a security audit record
+// Indexes:
+//   - (user_id, created_at): a user's history
+//   - (event, created_at): events by type
+type AuditLog struct {
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Event     string                 `bson:"event" json:"event"`
+	UserID    *primitive.ObjectID    `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	Email     string                 `bson:"email,omitempty" json:"email,omitempty"`
+	IPAddress string                 `bson:"ip_address,omitempty" json:"ip_address,omitempty"`
+	Details   map[string]interface{} `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt time.Time              `bson:"created_at" json:"created_at"`
+}
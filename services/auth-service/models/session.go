@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ClientInfo describes the client a session was started or refreshed from
+type ClientInfo struct {
+	Device    string
+	IPAddress string
+	UserAgent string
+}
+
+// Session represents an active login session (GET /users/me/sessions)
+// Its ID is the refresh token family ID, also carried by access tokens as the "sid" claim
+type Session struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	Current    bool      `json:"current"` // Session of the access token making the request
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"` // Last token refresh
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// LogoutRequest represents logout request payload
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
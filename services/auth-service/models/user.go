@@ -22,6 +22,33 @@ type User struct {
 	Bio          string             `bson:"bio" json:"bio"`
 	AvatarURL    string             `bson:"avatar_url" json:"avatar_url"`
 	
+	// Email verification
+	EmailVerified   bool       `bson:"email_verified" json:"email_verified"`
+	EmailVerifiedAt *time.Time `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
+	
+	// Two-factor authentication (TOTP)
+	MFAEnabled       bool     `bson:"mfa_enabled" json:"mfa_enabled"`
+	MFASecret        string   `bson:"mfa_secret,omitempty" json:"-"`         // Base32 TOTP secret, set once enrollment is confirmed
+	MFAPendingSecret string   `bson:"mfa_pending_secret,omitempty" json:"-"` // Secret of an enrollment awaiting its first code
+	MFARecoveryCodes []string `bson:"mfa_recovery_codes,omitempty" json:"-"` // SHA-256 hashes of unused recovery codes
+	MFALastStep      int64    `bson:"mfa_last_step,omitempty" json:"-"`      // Time step of the last accepted code (replay protection)
+	
+	// Social login (OpenID Connect)
+	Identities        []Identity `bson:"identities,omitempty" json:"identities,omitempty"`
+	ProfileIncomplete bool       `bson:"profile_incomplete,omitempty" json:"profile_incomplete,omitempty"` // Signed up via a provider, date of birth and gender still missing
+	
+	// Who can see the profile (see models/profile.go)
+	Privacy PrivacySettings `bson:"privacy" json:"privacy"`
+	
+	// Set when the user deletes their account; the deletion job removes the document
+	DeletionRequestedAt *time.Time `bson:"deletion_requested_at,omitempty" json:"-"`
+	
+	// Access control (see models/admin.go)
+	Roles            []string   `bson:"roles,omitempty" json:"roles,omitempty"` // jwt.RoleAdmin, jwt.RoleModerator
+	Suspended        bool       `bson:"suspended,omitempty" json:"suspended,omitempty"`
+	SuspendedUntil   *time.Time `bson:"suspended_until,omitempty" json:"suspended_until,omitempty"` // nil: until lifted
+	SuspensionReason string     `bson:"suspension_reason,omitempty" json:"suspension_reason,omitempty"`
+	
 	// Stats (denormalized for performance)
 	TotalPosts   int `bson:"total_posts" json:"total_posts"`
 	FriendsCount int `bson:"friends_count" json:"friends_count"`
@@ -53,19 +80,44 @@ type UpdateProfileRequest struct {
 }
 
 // AuthResponse represents authentication response
+// With two-factor authentication enabled, login only returns MFARequired and MFAToken:
+// the tokens and user follow once the code is submitted to /auth/mfa/verify
 type AuthResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	User         *User  `json:"user"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	User         *User  `json:"user,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
 }
 
 // RefreshToken represents a refresh token document
+// Only the SHA-256 hash of the token is stored. Every refresh rotates the token: the old one is
+// revoked (replaced_by points at its successor) and a new one is issued in the same family.
+// Presenting a revoked token again means it was stolen, so the whole family is revoked.
+// A family is a login session: its active token carries the session's device and client details.
 // Indexes:
-//   - token: unique index for fast lookup
+//   - token_hash: unique index for fast lookup
+//   - family_id: index for revoking a family
+//   - user_id: index for listing and revoking a user's sessions
 //   - created_at: TTL index (30 days = 2592000 seconds)
 type RefreshToken struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
-	Token     string             `bson:"token" json:"token"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	ID               primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID           primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	TokenHash        string              `bson:"token_hash,omitempty" json:"-"`
+	FamilyID         string              `bson:"family_id,omitempty" json:"family_id"`
+	FamilyRevoked    bool                `bson:"family_revoked,omitempty" json:"-"` // Set on every token of a logged out session
+	ReplacedBy       *primitive.ObjectID `bson:"replaced_by,omitempty" json:"replaced_by,omitempty"`
+	RevokedAt        *time.Time          `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	Device           string              `bson:"device,omitempty" json:"device,omitempty"`
+	IPAddress        string              `bson:"ip_address,omitempty" json:"ip_address,omitempty"`
+	UserAgent        string              `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	SessionCreatedAt time.Time           `bson:"session_created_at" json:"session_created_at"` // Login time, carried across rotations
+	ExpiresAt        time.Time           `bson:"expires_at" json:"expires_at"`
+	CreatedAt        time.Time           `bson:"created_at" json:"created_at"` // Issue time: the session's last refresh
+}
+
+// TokenPair represents the tokens returned by a refresh
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
 }
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"zodiac-ai-backend/services/auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrNoDeletionJob = errors.New("no account deletion job available")
+)
+
+// AccountDeletionRepository handles account deletion job data access
+type AccountDeletionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAccountDeletionRepository creates a new account deletion repository
+func NewAccountDeletionRepository(db *mongo.Database) *AccountDeletionRepository {
+	return &AccountDeletionRepository{
+		collection: db.Collection("account_deletions"),
+	}
+}
+
+// Create queues a deletion job
+// Idempotent: a second request for the same account keeps the existing job
+func (r *AccountDeletionRepository) Create(ctx context.Context, deletion *models.AccountDeletion) error {
+	deletion.Status = models.DeletionPending
+	deletion.CompletedSteps = []string{}
+	deletion.RequestedAt = time.Now()
+
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"user_id": deletion.UserID},
+		bson.M{"$setOnInsert": deletion},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil && mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// FindByUserID finds the deletion job of an account
+func (r *AccountDeletionRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	err := r.collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&deletion)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNoDeletionJob
+		}
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// Claim leases the next pending job that no worker holds (or whose worker died)
+// Returns ErrNoDeletionJob when there is nothing to do
+func (r *AccountDeletionRepository) Claim(ctx context.Context, lease time.Duration) (*models.AccountDeletion, error) {
+	now := time.Now()
+
+	var deletion models.AccountDeletion
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"status": models.DeletionPending,
+			"$or": bson.A{
+				bson.M{"lease_until": bson.M{"$exists": false}},
+				bson.M{"lease_until": bson.M{"$lte": now}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{"lease_until": now.Add(lease)},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "requested_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&deletion)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNoDeletionJob
+		}
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// CompleteStep records a finished step, with optional extra fields to save alongside it
+func (r *AccountDeletionRepository) CompleteStep(ctx context.Context, id primitive.ObjectID, step string, set bson.M) error {
+	update := bson.M{"$addToSet": bson.M{"completed_steps": step}}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// Fail records an error and releases the job until retryAt
+func (r *AccountDeletionRepository) Fail(ctx context.Context, id primitive.ObjectID, jobErr error, retryAt time.Time) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"last_error": jobErr.Error(), "lease_until": retryAt}},
+	)
+	return err
+}
+
+// Complete marks a job as done
+// The email and affected IDs are dropped: the job document outlives the account only as a record
+func (r *AccountDeletionRepository) Complete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$set": bson.M{"status": models.DeletionCompleted, "completed_at": time.Now()},
+			"$unset": bson.M{
+				"email":             "",
+				"last_error":        "",
+				"lease_until":       "",
+				"affected_user_ids": "",
+				"affected_post_ids": "",
+				"affected_room_ids": "",
+			},
+		},
+	)
+	return err
+}
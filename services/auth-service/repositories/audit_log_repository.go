@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"zodiac-ai-backend/services/auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AuditLogRepository handles security audit records
+type AuditLogRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *mongo.Database) *AuditLogRepository {
+	return &AuditLogRepository{
+		collection: db.Collection("audit_logs"),
+	}
+}
+
+// Create stores an audit record
+func (r *AuditLogRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	entry.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	entry.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
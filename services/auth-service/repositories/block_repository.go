@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"zodiac-ai-backend/services/auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrBlockNotFound = errors.New("block not found")
+)
+
+// BlockRepository handles user block data access
+// The blocks collection is shared with the social and chat services, which hide blocked users
+type BlockRepository struct {
+	collection *mongo.Collection
+}
+
+// NewBlockRepository creates a new block repository
+func NewBlockRepository(db *mongo.Database) *BlockRepository {
+	return &BlockRepository{
+		collection: db.Collection("blocks"),
+	}
+}
+
+// Create blocks a user; blocking the same user again keeps the original block
+func (r *BlockRepository) Create(ctx context.Context, blockerID, blockedID primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"blocker_id": blockerID, "blocked_id": blockedID},
+		bson.M{"$setOnInsert": bson.M{"created_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent block of the same user won the insert
+		return nil
+	}
+	return err
+}
+
+// Delete unblocks a user
+func (r *BlockRepository) Delete(ctx context.Context, blockerID, blockedID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"blocker_id": blockerID, "blocked_id": blockedID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrBlockNotFound
+	}
+	return nil
+}
+
+// ExistsBetween reports whether either user blocked the other
+func (r *BlockRepository) ExistsBetween(ctx context.Context, userID, otherID primitive.ObjectID) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"$or": bson.A{
+		bson.M{"blocker_id": userID, "blocked_id": otherID},
+		bson.M{"blocker_id": otherID, "blocked_id": userID},
+	}}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// HiddenFrom returns the users hidden from a user: the ones they blocked and the ones who blocked them
+func (r *BlockRepository) HiddenFrom(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	hidden, err := r.hiddenAmong(ctx, []primitive.ObjectID{userID})
+	if err != nil {
+		return nil, err
+	}
+	return hidden[userID], nil
+}
+
+// HiddenAmong returns, for each of userIDs (hex), the users hidden from them (hex)
+// Users without blocks either way are left out of the map
+func (r *BlockRepository) HiddenAmong(ctx context.Context, userIDs []string) (map[string][]string, error) {
+	ids := make([]primitive.ObjectID, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if id, err := primitive.ObjectIDFromHex(userID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return map[string][]string{}, nil
+	}
+
+	hidden, err := r.hiddenAmong(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string, len(hidden))
+	for userID, hiddenIDs := range hidden {
+		for _, id := range hiddenIDs {
+			result[userID.Hex()] = append(result[userID.Hex()], id.Hex())
+		}
+	}
+	return result, nil
+}
+
+// hiddenAmong returns, for each of ids, the users they blocked or were blocked by
+func (r *BlockRepository) hiddenAmong(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID][]primitive.ObjectID, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"blocker_id": bson.M{"$in": ids}},
+		bson.M{"blocked_id": bson.M{"$in": ids}},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var blocks []*models.Block
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	hidden := make(map[primitive.ObjectID][]primitive.ObjectID)
+	for _, block := range blocks {
+		if wanted[block.BlockerID] {
+			hidden[block.BlockerID] = append(hidden[block.BlockerID], block.BlockedID)
+		}
+		if wanted[block.BlockedID] {
+			hidden[block.BlockedID] = append(hidden[block.BlockedID], block.BlockerID)
+		}
+	}
+	return hidden, nil
+}
+
+// FindByBlocker lists the users a user blocked with cursor-based pagination (newest first)
+func (r *BlockRepository) FindByBlocker(ctx context.Context, blockerID primitive.ObjectID, cursor string, limit int) ([]*models.Block, string, error) {
+	filter := bson.M{"blocker_id": blockerID}
+
+	if cursor != "" {
+		cursorID, err := primitive.ObjectIDFromHex(cursor)
+		if err == nil {
+			filter["_id"] = bson.M{"$lt": cursorID}
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"_id": -1}).
+		SetLimit(int64(limit + 1)) // Fetch one extra to check if there's more
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	blocks := []*models.Block{}
+	if err := cur.All(ctx, &blocks); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(blocks) > limit {
+		blocks = blocks[:limit]
+		nextCursor = blocks[len(blocks)-1].ID.Hex()
+	}
+
+	return blocks, nextCursor, nil
+}
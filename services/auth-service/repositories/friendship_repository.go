@@ -10,10 +10,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var (
-	ErrFriendshipNotFound = errors.New("friendship not found")
+	ErrFriendshipNotFound      = errors.New("friendship not found")
+	ErrFriendRequestNotPending = errors.New("friend request is not pending")
+	ErrFriendRequestConflict   = errors.New("friend request was created concurrently")
 )
 
 // FriendshipRepository handles friendship data access
@@ -21,6 +24,7 @@ var (
 type FriendshipRepository struct {
 	collection        *mongo.Collection
 	requestCollection *mongo.Collection
+	userCollection    *mongo.Collection // friends_count is kept in step with friend_ids
 }
 
 // NewFriendshipRepository creates a new friendship repository
@@ -28,6 +32,7 @@ func NewFriendshipRepository(db *mongo.Database) *FriendshipRepository {
 	return &FriendshipRepository{
 		collection:        db.Collection("friendships"),
 		requestCollection: db.Collection("friend_requests"),
+		userCollection:    db.Collection("users"),
 	}
 }
 
@@ -104,90 +109,62 @@ func (r *FriendshipRepository) AddPendingReceived(ctx context.Context, userID, s
 }
 
 // AcceptFriendship accepts a friend request (bidirectional)
-// Uses MongoDB session for transaction to prevent race conditions
-// Reference: DDIA Ch. 7 - Transactions for atomicity
+// Run it inside WithTransaction together with the request status update and friends counts,
+// so the graph can't be left half-updated (DDIA Ch. 7 - Transactions for atomicity)
 func (r *FriendshipRepository) AcceptFriendship(ctx context.Context, userID, friendID primitive.ObjectID) error {
-	// Start session for transaction
-	session, err := r.collection.Database().Client().StartSession()
+	// Update user's friendship
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$addToSet": bson.M{"friend_ids": friendID},
+			"$pull":     bson.M{"pending_received": friendID},
+			"$set":      bson.M{"updated_at": time.Now()},
+		},
+	)
 	if err != nil {
 		return err
 	}
-	defer session.EndSession(ctx)
-
-	// Execute transaction
-	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
-		// Update user's friendship
-		_, err := r.collection.UpdateOne(
-			sessCtx,
-			bson.M{"user_id": userID},
-			bson.M{
-				"$addToSet": bson.M{"friend_ids": friendID},
-				"$pull":     bson.M{"pending_received": friendID},
-				"$set":      bson.M{"updated_at": time.Now()},
-			},
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		// Update friend's friendship (bidirectional)
-		_, err = r.collection.UpdateOne(
-			sessCtx,
-			bson.M{"user_id": friendID},
-			bson.M{
-				"$addToSet": bson.M{"friend_ids": userID},
-				"$pull":     bson.M{"pending_sent": userID},
-				"$set":      bson.M{"updated_at": time.Now()},
-			},
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		return nil, nil
-	})
 
+	// Update friend's friendship (bidirectional)
+	_, err = r.collection.UpdateOne(
+		ctx,
+		bson.M{"user_id": friendID},
+		bson.M{
+			"$addToSet": bson.M{"friend_ids": userID},
+			"$pull":     bson.M{"pending_sent": userID},
+			"$set":      bson.M{"updated_at": time.Now()},
+		},
+	)
 	return err
 }
 
-// RejectFriendship rejects a friend request
+// RejectFriendship removes a friend request from both pending lists
+// Also used when the sender withdraws the request. Run it inside WithTransaction
+// together with the request status update.
 func (r *FriendshipRepository) RejectFriendship(ctx context.Context, userID, friendID primitive.ObjectID) error {
-	session, err := r.collection.Database().Client().StartSession()
+	// Remove from user's pending received
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$pull": bson.M{"pending_received": friendID},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
 	if err != nil {
 		return err
 	}
-	defer session.EndSession(ctx)
-
-	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
-		// Remove from user's pending received
-		_, err := r.collection.UpdateOne(
-			sessCtx,
-			bson.M{"user_id": userID},
-			bson.M{
-				"$pull": bson.M{"pending_received": friendID},
-				"$set":  bson.M{"updated_at": time.Now()},
-			},
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		// Remove from friend's pending sent
-		_, err = r.collection.UpdateOne(
-			sessCtx,
-			bson.M{"user_id": friendID},
-			bson.M{
-				"$pull": bson.M{"pending_sent": userID},
-				"$set":  bson.M{"updated_at": time.Now()},
-			},
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		return nil, nil
-	})
 
+	// Remove from friend's pending sent
+	_, err = r.collection.UpdateOne(
+		ctx,
+		bson.M{"user_id": friendID},
+		bson.M{
+			"$pull": bson.M{"pending_sent": userID},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
 	return err
 }
 
@@ -200,30 +177,108 @@ func (r *FriendshipRepository) GetFriends(ctx context.Context, userID primitive.
 	return friendship.FriendIDs, nil
 }
 
-// CreateFriendRequest creates a friend request
+// FindMutualFriendCounts counts the mutual friends of a user and the friends of their friends
+// friendIDs are the user's friends; users in exclude (at least the user and their friends) are left out.
+// Sorted by mutual friends, most first.
+func (r *FriendshipRepository) FindMutualFriendCounts(ctx context.Context, friendIDs, exclude []primitive.ObjectID, limit int) ([]*models.MutualFriendCount, error) {
+	if len(friendIDs) == 0 {
+		return []*models.MutualFriendCount{}, nil
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": bson.M{"$in": friendIDs}}}},
+		{{Key: "$project", Value: bson.M{"friend_ids": 1}}},
+		{{Key: "$unwind", Value: "$friend_ids"}},
+		{{Key: "$match", Value: bson.M{"friend_ids": bson.M{"$nin": exclude}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$friend_ids", "mutual_friends": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "mutual_friends", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	counts := []*models.MutualFriendCount{}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// CreateFriendRequest creates a pending friend request from sender to receiver
+// There is one request document per pair (unique index on sender_id, receiver_id): sending
+// again after a rejection, cancellation or unfriend resets the existing document to pending,
+// and sending again while it is pending returns it unchanged.
+// Returns ErrFriendRequestConflict if a concurrent send of the same request inserted it first.
 func (r *FriendshipRepository) CreateFriendRequest(ctx context.Context, senderID, receiverID primitive.ObjectID) (*models.FriendRequest, error) {
-	request := &models.FriendRequest{
-		SenderID:   senderID,
-		ReceiverID: receiverID,
-		Status:     models.StatusPending,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+	now := time.Now()
+	pair := bson.M{"sender_id": senderID, "receiver_id": receiverID}
+
+	// Reset a request that was answered or withdrawn
+	_, err := r.requestCollection.UpdateOne(
+		ctx,
+		bson.M{"sender_id": senderID, "receiver_id": receiverID, "status": bson.M{"$ne": models.StatusPending}},
+		bson.M{"$set": bson.M{
+			"status":     models.StatusPending,
+			"created_at": now,
+			"updated_at": now,
+		}},
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	result, err := r.requestCollection.InsertOne(ctx, request)
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var request models.FriendRequest
+	err = r.requestCollection.FindOneAndUpdate(
+		ctx,
+		pair,
+		bson.M{"$setOnInsert": bson.M{
+			"status":     models.StatusPending,
+			"created_at": now,
+			"updated_at": now,
+		}},
+		opts,
+	).Decode(&request)
+
+	// A concurrent send of the same request won the insert. The error has aborted the
+	// transaction, so the caller retries it instead of reading the winner here.
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrFriendRequestConflict
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	request.ID = result.InsertedID.(primitive.ObjectID)
-	return request, nil
+	return &request, nil
 }
 
-// UpdateRequestStatus updates friend request status
+// PendingRequestExists checks if sender has a pending friend request to receiver
+func (r *FriendshipRepository) PendingRequestExists(ctx context.Context, senderID, receiverID primitive.ObjectID) (bool, error) {
+	count, err := r.requestCollection.CountDocuments(ctx, bson.M{
+		"sender_id":   senderID,
+		"receiver_id": receiverID,
+		"status":      models.StatusPending,
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// UpdateRequestStatus moves a pending friend request to status
+// Returns ErrFriendRequestNotPending if the request was answered or withdrawn in the meantime,
+// so the same request can't be accepted twice
 func (r *FriendshipRepository) UpdateRequestStatus(ctx context.Context, requestID primitive.ObjectID, status models.FriendshipStatus) error {
-	_, err := r.requestCollection.UpdateOne(
+	result, err := r.requestCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": requestID},
+		bson.M{"_id": requestID, "status": models.StatusPending},
 		bson.M{
 			"$set": bson.M{
 				"status":     status,
@@ -231,7 +286,13 @@ func (r *FriendshipRepository) UpdateRequestStatus(ctx context.Context, requestI
 			},
 		},
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrFriendRequestNotPending
+	}
+	return nil
 }
 
 // FindRequestByID finds a friend request by ID
@@ -246,3 +307,158 @@ func (r *FriendshipRepository) FindRequestByID(ctx context.Context, requestID pr
 	}
 	return &request, nil
 }
+
+// FindPendingRequests lists a user's pending friend requests with cursor-based pagination (newest first)
+// incoming lists the requests the user received, otherwise the ones they sent
+func (r *FriendshipRepository) FindPendingRequests(ctx context.Context, userID primitive.ObjectID, incoming bool, cursor string, limit int) ([]*models.FriendRequest, string, error) {
+	filter := bson.M{"status": models.StatusPending}
+	if incoming {
+		filter["receiver_id"] = userID
+	} else {
+		filter["sender_id"] = userID
+	}
+
+	if cursor != "" {
+		cursorID, err := primitive.ObjectIDFromHex(cursor)
+		if err == nil {
+			filter["_id"] = bson.M{"$lt": cursorID}
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"_id": -1}).
+		SetLimit(int64(limit + 1)) // Fetch one extra to check if there's more
+
+	cur, err := r.requestCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	requests := []*models.FriendRequest{}
+	if err := cur.All(ctx, &requests); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(requests) > limit {
+		requests = requests[:limit]
+		nextCursor = requests[len(requests)-1].ID.Hex()
+	}
+
+	return requests, nextCursor, nil
+}
+
+// RemoveFriendship removes a friendship in both directions and decrements both users' friends count
+// The accepted request between them is marked unfriended, so it no longer vouches for the friendship.
+// Uses a transaction so friend_ids, friends_count and the request can't drift apart
+// Returns ErrFriendshipNotFound if the users aren't friends
+func (r *FriendshipRepository) RemoveFriendship(ctx context.Context, userID, friendID primitive.ObjectID) error {
+	return r.WithTransaction(ctx, func(txCtx context.Context) error {
+		removed, err := r.removeFriendEdges(txCtx, userID, friendID)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return ErrFriendshipNotFound
+		}
+		return r.closeRequests(txCtx, userID, friendID, models.StatusAccepted, models.StatusUnfriended)
+	})
+}
+
+// SeverUsers removes every tie between two users: the friendship in both directions (with
+// friends counts, marking the accepted request unfriended) and pending requests either way. It doesn't start a transaction of its own;
+// run it inside WithTransaction together with the change that requires it (e.g. a block).
+func (r *FriendshipRepository) SeverUsers(ctx context.Context, userID, otherID primitive.ObjectID) error {
+	if _, err := r.removeFriendEdges(ctx, userID, otherID); err != nil {
+		return err
+	}
+
+	for _, pair := range [][2]primitive.ObjectID{{userID, otherID}, {otherID, userID}} {
+		_, err := r.collection.UpdateOne(
+			ctx,
+			bson.M{"user_id": pair[0]},
+			bson.M{
+				"$pull": bson.M{"pending_sent": pair[1], "pending_received": pair[1]},
+				"$set":  bson.M{"updated_at": time.Now()},
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := r.closeRequests(ctx, userID, otherID, models.StatusAccepted, models.StatusUnfriended); err != nil {
+		return err
+	}
+	return r.closeRequests(ctx, userID, otherID, models.StatusPending, models.StatusCancelled)
+}
+
+// WithTransaction runs fn in a MongoDB transaction
+// Repository calls made with the context fn receives take part in the transaction;
+// fn may be retried on transient errors, so it must not have other side effects.
+func (r *FriendshipRepository) WithTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
+
+// closeRequests moves the requests between two users (either way) from status from to status to
+func (r *FriendshipRepository) closeRequests(ctx context.Context, userID, otherID primitive.ObjectID, from, to models.FriendshipStatus) error {
+	_, err := r.requestCollection.UpdateMany(
+		ctx,
+		bson.M{
+			"status": from,
+			"$or": bson.A{
+				bson.M{"sender_id": userID, "receiver_id": otherID},
+				bson.M{"sender_id": otherID, "receiver_id": userID},
+			},
+		},
+		bson.M{"$set": bson.M{
+			"status":     to,
+			"updated_at": time.Now(),
+		}},
+	)
+	return err
+}
+
+// removeFriendEdges removes a friendship in both directions and decrements the friends count
+// of each side whose list changed, never below zero. Reports whether any edge was removed.
+func (r *FriendshipRepository) removeFriendEdges(ctx context.Context, userID, friendID primitive.ObjectID) (bool, error) {
+	removed := false
+	for _, edge := range [][2]primitive.ObjectID{{userID, friendID}, {friendID, userID}} {
+		result, err := r.collection.UpdateOne(
+			ctx,
+			bson.M{"user_id": edge[0], "friend_ids": edge[1]},
+			bson.M{
+				"$pull": bson.M{"friend_ids": edge[1]},
+				"$set":  bson.M{"updated_at": time.Now()},
+			},
+		)
+		if err != nil {
+			return false, err
+		}
+		if result.ModifiedCount == 0 {
+			continue
+		}
+		removed = true
+
+		_, err = r.userCollection.UpdateOne(
+			ctx,
+			bson.M{"_id": edge[0], "friends_count": bson.M{"$gt": 0}},
+			bson.M{"$inc": bson.M{"friends_count": -1}},
+		)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return removed, nil
+}
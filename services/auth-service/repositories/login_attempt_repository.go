@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"zodiac-ai-backend/services/auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// loginAttemptRetention is how long a key is remembered after its last failure or lockout
+const loginAttemptRetention = 24 * time.Hour
+
+// LoginAttemptRepository handles failed login tracking
+// State lives in MongoDB so lockouts survive restarts and are shared by all replicas
+type LoginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository
+func NewLoginAttemptRepository(db *mongo.Database) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		collection: db.Collection("login_attempts"),
+	}
+}
+
+// FindByKeys returns the tracked keys among keys (untracked keys are skipped)
+func (r *LoginAttemptRepository) FindByKeys(ctx context.Context, keys ...string) ([]*models.LoginAttempt, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": keys}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var attempts []*models.LoginAttempt
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// RecordFailure counts a failed login and returns the updated state
+// Failures older than window are forgotten: the count restarts at 1
+// Uses an update pipeline so the check and increment are one atomic operation
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempt, error) {
+	now := time.Now()
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "failures", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{"$last_failure_at", now.Add(-window)}}},
+				bson.D{{Key: "$add", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$failures", 0}}}, 1}}},
+				1,
+			}}}},
+			{Key: "lockouts", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$lockouts", 0}}}},
+			{Key: "last_failure_at", Value: now},
+			{Key: "expires_at", Value: bson.D{{Key: "$max", Value: bson.A{
+				now.Add(loginAttemptRetention),
+				bson.D{{Key: "$ifNull", Value: bson.A{"$expires_at", now}}},
+			}}}},
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempt models.LoginAttempt
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&attempt); err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// Lock locks a key until the given time and starts a new failure count
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": key},
+		bson.M{
+			"$set": bson.M{
+				"locked_until": until,
+				"failures":     0,
+				"expires_at":   until.Add(loginAttemptRetention),
+			},
+			"$inc": bson.M{"lockouts": 1},
+		},
+	)
+	return err
+}
+
+// Delete forgets a key (e.g. after a successful login)
+func (r *LoginAttemptRepository) Delete(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"zodiac-ai-backend/services/auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrOIDCStateInvalid = errors.New("login state is invalid, expired or already used")
+)
+
+// OIDCStateRepository handles pending provider login data access
+type OIDCStateRepository struct {
+	collection *mongo.Collection
+}
+
+// NewOIDCStateRepository creates a new OIDC state repository
+func NewOIDCStateRepository(db *mongo.Database) *OIDCStateRepository {
+	return &OIDCStateRepository{
+		collection: db.Collection("oidc_states"),
+	}
+}
+
+// Create stores a pending login under the hash of its state parameter
+func (r *OIDCStateRepository) Create(ctx context.Context, oidcState *models.OIDCState, state string) error {
+	oidcState.StateHash = HashToken(state)
+	oidcState.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, oidcState)
+	if err != nil {
+		return err
+	}
+
+	oidcState.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// Consume removes and returns an unexpired pending login
+// Atomic, so a state (and its authorization code) can only be redeemed once
+func (r *OIDCStateRepository) Consume(ctx context.Context, provider, state string) (*models.OIDCState, error) {
+	var oidcState models.OIDCState
+	err := r.collection.FindOneAndDelete(
+		ctx,
+		bson.M{
+			"state_hash": HashToken(state),
+			"provider":   provider,
+			"expires_at": bson.M{"$gt": time.Now()},
+		},
+	).Decode(&oidcState)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrOIDCStateInvalid
+		}
+		return nil, err
+	}
+	return &oidcState, nil
+}
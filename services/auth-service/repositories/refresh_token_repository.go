@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var (
@@ -28,22 +31,34 @@ func NewRefreshTokenRepository(db *mongo.Database) *RefreshTokenRepository {
 	}
 }
 
-// Create creates a new refresh token
-func (r *RefreshTokenRepository) Create(ctx context.Context, userID primitive.ObjectID, token string) error {
-	refreshToken := &models.RefreshToken{
-		UserID:    userID,
-		Token:     token,
-		CreatedAt: time.Now(),
+// HashToken returns the stored form of a refresh token (hex SHA-256)
+// Refresh tokens are long random JWTs, so a fast unsalted hash is enough to make a database leak useless
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create stores a new refresh token
+// Only the hash of token is saved; refreshToken.ID is kept if already set
+func (r *RefreshTokenRepository) Create(ctx context.Context, refreshToken *models.RefreshToken, token string) error {
+	if refreshToken.ID.IsZero() {
+		refreshToken.ID = primitive.NewObjectID()
+	}
+	refreshToken.TokenHash = HashToken(token)
+	refreshToken.CreatedAt = time.Now()
+	if refreshToken.SessionCreatedAt.IsZero() {
+		refreshToken.SessionCreatedAt = refreshToken.CreatedAt
 	}
 
 	_, err := r.collection.InsertOne(ctx, refreshToken)
 	return err
 }
 
-// FindByToken finds a refresh token
+// FindByToken finds a refresh token, including revoked ones (needed for reuse detection)
+// Legacy plaintext tokens are hashed by the migration (scripts/migrate.go) and found like any other
 func (r *RefreshTokenRepository) FindByToken(ctx context.Context, token string) (*models.RefreshToken, error) {
 	var refreshToken models.RefreshToken
-	err := r.collection.FindOne(ctx, bson.M{"token": token}).Decode(&refreshToken)
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": HashToken(token)}).Decode(&refreshToken)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, ErrTokenNotFound
@@ -53,9 +68,95 @@ func (r *RefreshTokenRepository) FindByToken(ctx context.Context, token string)
 	return &refreshToken, nil
 }
 
-// Delete deletes a refresh token
-func (r *RefreshTokenRepository) Delete(ctx context.Context, token string) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"token": token})
+// Revoke revokes a token that is still active and records its successor
+// Returns false if the token was already revoked (e.g. a concurrent refresh won the race)
+// Legacy tokens get their family ID set here, so a later reuse can revoke the right family
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, token *models.RefreshToken, replacedBy primitive.ObjectID) (bool, error) {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": token.ID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"revoked_at":  time.Now(),
+			"replaced_by": replacedBy,
+			"family_id":   token.FamilyID,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// RevokeFamily ends a user's session: every token in the family is revoked
+// Returns false if the user has no such session
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, userID primitive.ObjectID, familyID string) (bool, error) {
+	if familyID == "" {
+		return false, nil
+	}
+	return r.revokeFamilies(ctx, bson.M{"user_id": userID, "family_id": familyID})
+}
+
+// RevokeAllByUserID ends all of a user's sessions
+func (r *RefreshTokenRepository) RevokeAllByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.revokeFamilies(ctx, bson.M{"user_id": userID})
+	return err
+}
+
+// revokeFamilies marks every token matching filter as belonging to a revoked family
+func (r *RefreshTokenRepository) revokeFamilies(ctx context.Context, filter bson.M) (bool, error) {
+	result, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"family_revoked": true}})
+	if err != nil {
+		return false, err
+	}
+
+	// Keep the original revocation time of rotated tokens
+	filter["revoked_at"] = bson.M{"$exists": false}
+	if _, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}}); err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// FindActiveByUserID returns the active token of each of a user's sessions, most recently used first
+func (r *RefreshTokenRepository) FindActiveByUserID(ctx context.Context, userID primitive.ObjectID) ([]*models.RefreshToken, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"user_id":    userID,
+		"family_id":  bson.M{"$exists": true},
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tokens := make([]*models.RefreshToken, 0)
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// IsSessionActive reports whether a session (token family) is still logged in
+// Rotation keeps a session active; only logout, session deletion, reuse detection and expiry end it
+func (r *RefreshTokenRepository) IsSessionActive(ctx context.Context, familyID string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"family_id":      familyID,
+		"family_revoked": bson.M{"$ne": true},
+		"expires_at":     bson.M{"$gt": time.Now()},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DeleteByID deletes a refresh token
+func (r *RefreshTokenRepository) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
 
@@ -0,0 +1,432 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UserDataRepository reads and removes everything tied to a user across all services
+// It works on the raw collections of the auth, social and chat services (they share one
+// database), so it has to follow their schemas; see each service's models package.
+type UserDataRepository struct {
+	db *mongo.Database
+}
+
+// NewUserDataRepository creates a new user data repository
+func NewUserDataRepository(db *mongo.Database) *UserDataRepository {
+	return &UserDataRepository{
+		db: db,
+	}
+}
+
+// ExportSection is one collection's documents in a data export
+type ExportSection struct {
+	Name      string
+	Documents []bson.M
+}
+
+// exportSource describes where a section's documents come from
+type exportSource struct {
+	name       string
+	collection string
+	filter     func(userID primitive.ObjectID) bson.M
+	omit       []string // Credentials and secrets, never exported
+}
+
+func byUserID(userID primitive.ObjectID) bson.M { return bson.M{"user_id": userID} }
+
+var exportSources = []exportSource{
+	{
+		name:       "profile",
+		collection: "users",
+		filter:     func(userID primitive.ObjectID) bson.M { return bson.M{"_id": userID} },
+		omit:       []string{"password", "mfa_secret", "mfa_pending_secret", "mfa_recovery_codes", "mfa_last_step"},
+	},
+	{name: "friendships", collection: "friendships", filter: byUserID},
+	{
+		name:       "friend_requests",
+		collection: "friend_requests",
+		filter: func(userID primitive.ObjectID) bson.M {
+			return bson.M{"$or": bson.A{bson.M{"sender_id": userID}, bson.M{"receiver_id": userID}}}
+		},
+	},
+	{
+		name:       "blocks",
+		collection: "blocks",
+		filter:     func(userID primitive.ObjectID) bson.M { return bson.M{"blocker_id": userID} },
+	},
+	{name: "sessions", collection: "refresh_tokens", filter: byUserID, omit: []string{"token_hash", "token"}},
+	{name: "account_tokens", collection: "user_tokens", filter: byUserID, omit: []string{"token_hash"}},
+	{name: "security_events", collection: "audit_logs", filter: byUserID},
+	{name: "posts", collection: "posts", filter: byUserID},
+	{name: "likes", collection: "likes", filter: byUserID},
+	{name: "comments", collection: "comments", filter: byUserID},
+	{name: "ai_chat_sessions", collection: "chat_sessions", filter: byUserID},
+	{name: "ai_chat_messages", collection: "messages", filter: byUserID},
+	{
+		name:       "rooms_created",
+		collection: "rooms",
+		filter:     func(userID primitive.ObjectID) bson.M { return bson.M{"creator_id": userID} },
+	},
+	{name: "room_memberships", collection: "room_members", filter: byUserID},
+	{name: "room_messages", collection: "room_messages", filter: byUserID},
+	{
+		name:       "conversations",
+		collection: "conversations",
+		filter:     func(userID primitive.ObjectID) bson.M { return bson.M{"participants": userID} },
+		omit:       []string{"participant_key"},
+	},
+}
+
+// Export returns every document tied to a user, one section per collection
+// Direct messages include both sides of the user's conversations.
+func (r *UserDataRepository) Export(ctx context.Context, userID primitive.ObjectID) ([]ExportSection, error) {
+	sections := make([]ExportSection, 0, len(exportSources)+1)
+
+	var conversationIDs []primitive.ObjectID
+	for _, source := range exportSources {
+		opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+		if len(source.omit) > 0 {
+			projection := bson.M{}
+			for _, field := range source.omit {
+				projection[field] = 0
+			}
+			opts.SetProjection(projection)
+		}
+
+		docs, err := r.find(ctx, source.collection, source.filter(userID), opts)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", source.name, err)
+		}
+		sections = append(sections, ExportSection{Name: source.name, Documents: docs})
+
+		if source.collection == "conversations" {
+			for _, doc := range docs {
+				if id, ok := doc["_id"].(primitive.ObjectID); ok {
+					conversationIDs = append(conversationIDs, id)
+				}
+			}
+		}
+	}
+
+	messages := []bson.M{}
+	if len(conversationIDs) > 0 {
+		var err error
+		messages, err = r.find(ctx, "direct_messages",
+			bson.M{"conversation_id": bson.M{"$in": conversationIDs}},
+			options.Find().SetSort(bson.D{{Key: "conversation_id", Value: 1}, {Key: "_id", Value: 1}}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("export direct_messages: %w", err)
+		}
+	}
+	sections = append(sections, ExportSection{Name: "direct_messages", Documents: messages})
+
+	return sections, nil
+}
+
+// find returns a collection's matching documents as plain maps
+func (r *UserDataRepository) find(ctx context.Context, collection string, filter bson.M, opts *options.FindOptions) ([]bson.M, error) {
+	cursor, err := r.db.Collection(collection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	docs := []bson.M{}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// distinctIDs returns the distinct ObjectID values of a field
+func (r *UserDataRepository) distinctIDs(ctx context.Context, collection, field string, filter bson.M) ([]primitive.ObjectID, error) {
+	values, err := r.db.Collection(collection).Distinct(ctx, field, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// AffectedRecords returns the records whose denormalized counters change when a user is deleted:
+// their friends (friends_count), the posts they liked or commented on (likes_count,
+// comments_count) and the rooms they are a member of (member_count)
+func (r *UserDataRepository) AffectedRecords(ctx context.Context, userID primitive.ObjectID) (users, posts, rooms []primitive.ObjectID, err error) {
+	users, err = r.distinctIDs(ctx, "friendships", "user_id", bson.M{"friend_ids": userID})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	liked, err := r.distinctIDs(ctx, "likes", "post_id", bson.M{"user_id": userID})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	commented, err := r.distinctIDs(ctx, "comments", "post_id", bson.M{"user_id": userID})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	posts = unionIDs(liked, commented)
+
+	rooms, err = r.distinctIDs(ctx, "room_members", "room_id", bson.M{"user_id": userID, "banned": false})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return users, posts, rooms, nil
+}
+
+// unionIDs merges two ID lists without duplicates
+func unionIDs(a, b []primitive.ObjectID) []primitive.ObjectID {
+	seen := make(map[primitive.ObjectID]bool, len(a)+len(b))
+	ids := make([]primitive.ObjectID, 0, len(a)+len(b))
+	for _, id := range append(a, b...) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// DeleteFriendships removes a user from every friend list and deletes their friend requests and blocks
+func (r *UserDataRepository) DeleteFriendships(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.db.Collection("friendships").UpdateMany(
+		ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"friend_ids": userID},
+			bson.M{"pending_sent": userID},
+			bson.M{"pending_received": userID},
+		}},
+		bson.M{"$pull": bson.M{
+			"friend_ids":       userID,
+			"pending_sent":     userID,
+			"pending_received": userID,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Collection("friendships").DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return err
+	}
+
+	_, err = r.db.Collection("friend_requests").DeleteMany(ctx, bson.M{
+		"$or": bson.A{bson.M{"sender_id": userID}, bson.M{"receiver_id": userID}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Collection("blocks").DeleteMany(ctx, bson.M{
+		"$or": bson.A{bson.M{"blocker_id": userID}, bson.M{"blocked_id": userID}},
+	})
+	return err
+}
+
+// DeleteSocial deletes a user's posts (with their likes and comments) and the user's own likes and comments
+func (r *UserDataRepository) DeleteSocial(ctx context.Context, userID primitive.ObjectID) error {
+	postIDs, err := r.distinctIDs(ctx, "posts", "_id", bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+
+	if len(postIDs) > 0 {
+		onPosts := bson.M{"post_id": bson.M{"$in": postIDs}}
+		if _, err := r.db.Collection("likes").DeleteMany(ctx, onPosts); err != nil {
+			return err
+		}
+		if _, err := r.db.Collection("comments").DeleteMany(ctx, onPosts); err != nil {
+			return err
+		}
+	}
+
+	// Posts last: if this step is retried, the likes and comments of posts already
+	// deleted would otherwise be left behind
+	for _, collection := range []string{"likes", "comments", "posts"} {
+		if _, err := r.db.Collection(collection).DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteChat deletes a user's AI chat sessions and messages, rooms, room memberships,
+// room messages and direct conversations
+// Rooms the user created are deleted with their members and messages, as when the owner deletes them.
+func (r *UserDataRepository) DeleteChat(ctx context.Context, userID primitive.ObjectID) error {
+	for _, collection := range []string{"messages", "chat_sessions"} {
+		if _, err := r.db.Collection(collection).DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+			return err
+		}
+	}
+
+	roomIDs, err := r.distinctIDs(ctx, "rooms", "_id", bson.M{"creator_id": userID})
+	if err != nil {
+		return err
+	}
+	if len(roomIDs) > 0 {
+		inRooms := bson.M{"room_id": bson.M{"$in": roomIDs}}
+		if _, err := r.db.Collection("room_messages").DeleteMany(ctx, inRooms); err != nil {
+			return err
+		}
+		if _, err := r.db.Collection("room_members").DeleteMany(ctx, inRooms); err != nil {
+			return err
+		}
+		if _, err := r.db.Collection("rooms").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": roomIDs}}); err != nil {
+			return err
+		}
+	}
+
+	for _, collection := range []string{"room_messages", "room_members"} {
+		if _, err := r.db.Collection(collection).DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+			return err
+		}
+	}
+
+	// Bans the user issued stay in place, without pointing at the deleted account
+	if _, err := r.db.Collection("room_members").UpdateMany(
+		ctx,
+		bson.M{"banned_by": userID},
+		bson.M{"$unset": bson.M{"banned_by": ""}},
+	); err != nil {
+		return err
+	}
+
+	// Room previews show the last message's content, which was just deleted
+	if _, err := r.db.Collection("rooms").UpdateMany(
+		ctx,
+		bson.M{"last_message.sender_id": userID.Hex()},
+		bson.M{"$set": bson.M{"last_message": nil}},
+	); err != nil {
+		return err
+	}
+
+	conversationIDs, err := r.distinctIDs(ctx, "conversations", "_id", bson.M{"participants": userID})
+	if err != nil {
+		return err
+	}
+	if len(conversationIDs) > 0 {
+		if _, err := r.db.Collection("direct_messages").DeleteMany(ctx, bson.M{
+			"conversation_id": bson.M{"$in": conversationIDs},
+		}); err != nil {
+			return err
+		}
+		if _, err := r.db.Collection("conversations").DeleteMany(ctx, bson.M{
+			"_id": bson.M{"$in": conversationIDs},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteSecurityRecords deletes a user's sessions, account tokens, login states and the lockout
+// counter under attemptKey, and anonymizes the audit log entries about them
+func (r *UserDataRepository) DeleteSecurityRecords(ctx context.Context, userID primitive.ObjectID, email, attemptKey string) error {
+	for _, collection := range []string{"refresh_tokens", "user_tokens", "oidc_states"} {
+		if _, err := r.db.Collection(collection).DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+			return err
+		}
+	}
+
+	if attemptKey != "" {
+		if _, err := r.db.Collection("login_attempts").DeleteOne(ctx, bson.M{"_id": attemptKey}); err != nil {
+			return err
+		}
+	}
+
+	filter := bson.M{"user_id": userID}
+	if email != "" {
+		filter = bson.M{"$or": bson.A{filter, bson.M{"email": email}}}
+	}
+	_, err := r.db.Collection("audit_logs").UpdateMany(
+		ctx,
+		filter,
+		bson.M{"$unset": bson.M{"user_id": "", "email": ""}},
+	)
+	return err
+}
+
+// RecountUsers recalculates friends_count from the users' friend lists
+func (r *UserDataRepository) RecountUsers(ctx context.Context, userIDs []primitive.ObjectID) error {
+	for _, id := range userIDs {
+		var friendship struct {
+			FriendIDs []primitive.ObjectID `bson:"friend_ids"`
+		}
+		err := r.db.Collection("friendships").FindOne(ctx, bson.M{"user_id": id}).Decode(&friendship)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+
+		if _, err := r.db.Collection("users").UpdateOne(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": bson.M{"friends_count": len(friendship.FriendIDs)}},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecountPosts recalculates likes_count and comments_count from the likes and comments
+func (r *UserDataRepository) RecountPosts(ctx context.Context, postIDs []primitive.ObjectID) error {
+	for _, id := range postIDs {
+		likes, err := r.db.Collection("likes").CountDocuments(ctx, bson.M{"post_id": id})
+		if err != nil {
+			return err
+		}
+		comments, err := r.db.Collection("comments").CountDocuments(ctx, bson.M{"post_id": id})
+		if err != nil {
+			return err
+		}
+
+		if _, err := r.db.Collection("posts").UpdateOne(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": bson.M{"likes_count": likes, "comments_count": comments}},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecountRooms recalculates member_count from the rooms' members (bans excluded)
+func (r *UserDataRepository) RecountRooms(ctx context.Context, roomIDs []primitive.ObjectID) error {
+	for _, id := range roomIDs {
+		members, err := r.db.Collection("room_members").CountDocuments(ctx, bson.M{"room_id": id, "banned": false})
+		if err != nil {
+			return err
+		}
+
+		if _, err := r.db.Collection("rooms").UpdateOne(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": bson.M{"member_count": members}},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteUser deletes the user document itself
+func (r *UserDataRepository) DeleteUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.db.Collection("users").DeleteOne(ctx, bson.M{"_id": userID})
+	return err
+}
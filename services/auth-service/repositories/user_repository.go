@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"errors"
+	"regexp"
 	"time"
 
 	"zodiac-ai-backend/services/auth-service/models"
@@ -10,11 +11,15 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
+
+	ErrIdentityAlreadyLinked = errors.New("provider account is linked to another user")
+	ErrProviderAlreadyLinked = errors.New("user already has an account at this provider linked")
 )
 
 // UserRepository handles user data access
@@ -75,6 +80,25 @@ func (r *UserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*
 	return &user, nil
 }
 
+// FindByIDs finds users by IDs (missing users are skipped)
+func (r *UserRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*models.User, error) {
+	if len(ids) == 0 {
+		return []*models.User{}, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []*models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // Update updates user profile
 func (r *UserRepository) Update(ctx context.Context, id primitive.ObjectID, update bson.M) error {
 	update["updated_at"] = time.Now()
@@ -116,3 +140,335 @@ func (r *UserRepository) IncrementFriendsCount(ctx context.Context, userID primi
 	)
 	return err
 }
+
+// ClaimMFAStep records the time step of an accepted TOTP code
+// Returns false if that step (or a later one) was already used, so a code can't be replayed
+func (r *UserRepository) ClaimMFAStep(ctx context.Context, id primitive.ObjectID, step int64) (bool, error) {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id": id,
+			"$or": bson.A{
+				bson.M{"mfa_last_step": bson.M{"$lt": step}},
+				bson.M{"mfa_last_step": bson.M{"$exists": false}},
+			},
+		},
+		bson.M{"$set": bson.M{"mfa_last_step": step}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// ConsumeRecoveryCode removes a recovery code hash
+// Atomic: returns false if the code isn't (or is no longer) one of the user's codes
+func (r *UserRepository) ConsumeRecoveryCode(ctx context.Context, id primitive.ObjectID, codeHash string) (bool, error) {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "mfa_recovery_codes": codeHash},
+		bson.M{
+			"$pull": bson.M{"mfa_recovery_codes": codeHash},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// ClearMFA turns two-factor authentication off and removes its secrets
+func (r *UserRepository) ClearMFA(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$set": bson.M{"mfa_enabled": false, "updated_at": time.Now()},
+			"$unset": bson.M{
+				"mfa_secret":         "",
+				"mfa_pending_secret": "",
+				"mfa_recovery_codes": "",
+				"mfa_last_step":      "",
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// FindByIdentity finds the user linked to a provider account
+func (r *UserRepository) FindByIdentity(ctx context.Context, provider, subject string) (*models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}},
+	}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// AddIdentity links a provider account to a user
+// Returns ErrProviderAlreadyLinked if the user already has an account at that provider,
+// ErrIdentityAlreadyLinked if the provider account belongs to another user
+func (r *UserRepository) AddIdentity(ctx context.Context, id primitive.ObjectID, identity models.Identity) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "identities.provider": bson.M{"$ne": identity.Provider}},
+		bson.M{
+			"$push": bson.M{"identities": identity},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrIdentityAlreadyLinked
+		}
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrProviderAlreadyLinked
+	}
+	return nil
+}
+
+// suspendedFilter matches users whose suspension is in effect at now
+func suspendedFilter(now time.Time) bson.M {
+	return bson.M{
+		"suspended": true,
+		"$or": bson.A{
+			bson.M{"suspended_until": bson.M{"$exists": false}},
+			bson.M{"suspended_until": bson.M{"$gt": now}},
+		},
+	}
+}
+
+// IsSuspended reports whether a user is suspended right now
+// Implements middleware.SuspensionChecker; unknown users aren't suspended
+func (r *UserRepository) IsSuspended(ctx context.Context, userID string) (bool, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, nil
+	}
+
+	filter := suspendedFilter(time.Now())
+	filter["_id"] = id
+
+	count, err := r.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SuspendedAmong returns the users of userIDs (hex) that are suspended right now
+func (r *UserRepository) SuspendedAmong(ctx context.Context, userIDs []string) ([]string, error) {
+	ids := make([]primitive.ObjectID, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if id, err := primitive.ObjectIDFromHex(userID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	filter := suspendedFilter(time.Now())
+	filter["_id"] = bson.M{"$in": ids}
+
+	values, err := r.collection.Distinct(ctx, "_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	suspended := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			suspended = append(suspended, id.Hex())
+		}
+	}
+	return suspended, nil
+}
+
+// Search lists users for the admin API with cursor-based pagination (newest first)
+// query matches the start of the email, full name or display name, case-insensitively
+func (r *UserRepository) Search(ctx context.Context, query, role string, suspended bool, cursor string, limit int) ([]*models.User, string, error) {
+	filter := bson.M{}
+
+	if query != "" {
+		pattern := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(query), Options: "i"}
+		filter["$or"] = bson.A{
+			bson.M{"email": pattern},
+			bson.M{"full_name": pattern},
+			bson.M{"display_name": pattern},
+		}
+	}
+	if role != "" {
+		filter["roles"] = role
+	}
+	if suspended {
+		filter = bson.M{"$and": bson.A{filter, suspendedFilter(time.Now())}}
+	}
+
+	if cursor != "" {
+		cursorID, err := primitive.ObjectIDFromHex(cursor)
+		if err == nil {
+			filter = bson.M{"$and": bson.A{filter, bson.M{"_id": bson.M{"$lt": cursorID}}}}
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"_id": -1}).
+		SetLimit(int64(limit + 1)) // Fetch one extra to check if there's more
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	users := []*models.User{}
+	if err := cur.All(ctx, &users); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(users) > limit {
+		users = users[:limit]
+		nextCursor = users[len(users)-1].ID.Hex()
+	}
+
+	return users, nextCursor, nil
+}
+
+// Suspend suspends a user until the given time (nil: until lifted)
+func (r *UserRepository) Suspend(ctx context.Context, id primitive.ObjectID, until *time.Time, reason string) error {
+	set := bson.M{
+		"suspended":         true,
+		"suspension_reason": reason,
+		"updated_at":        time.Now(),
+	}
+	update := bson.M{"$set": set}
+	if until != nil {
+		set["suspended_until"] = *until
+	} else {
+		update["$unset"] = bson.M{"suspended_until": ""}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// Unsuspend lifts a user's suspension
+func (r *UserRepository) Unsuspend(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$unset": bson.M{"suspended": "", "suspended_until": "", "suspension_reason": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// SearchProfiles searches users by display name and zodiac sign with cursor-based pagination (newest first)
+// query matches the start of the display name, case-insensitively. Private profiles and
+// accounts being deleted are left out.
+func (r *UserRepository) SearchProfiles(ctx context.Context, query, zodiacSign, cursor string, limit int) ([]*models.User, string, error) {
+	filter := bson.M{
+		"privacy.profile_visibility": bson.M{"$ne": models.VisibilityPrivate},
+		"deletion_requested_at":      bson.M{"$exists": false},
+	}
+
+	if query != "" {
+		filter["display_name"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(query), Options: "i"}
+	}
+	if zodiacSign != "" {
+		filter["zodiac_sign"] = zodiacSign
+	}
+
+	if cursor != "" {
+		cursorID, err := primitive.ObjectIDFromHex(cursor)
+		if err == nil {
+			filter["_id"] = bson.M{"$lt": cursorID}
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"_id": -1}).
+		SetLimit(int64(limit + 1)) // Fetch one extra to check if there's more
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	users := []*models.User{}
+	if err := cur.All(ctx, &users); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(users) > limit {
+		users = users[:limit]
+		nextCursor = users[len(users)-1].ID.Hex()
+	}
+
+	return users, nextCursor, nil
+}
+
+// FindByZodiacSigns lists users with one of signs (any sign if signs is empty), newest first
+// Private profiles, suspended users, accounts being deleted and users in exclude are left out.
+func (r *UserRepository) FindByZodiacSigns(ctx context.Context, signs []string, exclude []primitive.ObjectID, limit int) ([]*models.User, error) {
+	filter := bson.M{
+		"_id":                        bson.M{"$nin": exclude},
+		"privacy.profile_visibility": bson.M{"$ne": models.VisibilityPrivate},
+		"deletion_requested_at":      bson.M{"$exists": false},
+		"$nor":                       bson.A{suspendedFilter(time.Now())},
+	}
+	if len(signs) > 0 {
+		filter["zodiac_sign"] = bson.M{"$in": signs}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"_id": -1}).
+		SetLimit(int64(limit))
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	users := []*models.User{}
+	if err := cur.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
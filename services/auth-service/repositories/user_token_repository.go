@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"zodiac-ai-backend/services/auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrUserTokenInvalid = errors.New("token is invalid, expired or already used")
+)
+
+// UserTokenRepository handles single-use email token data access
+type UserTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewUserTokenRepository creates a new user token repository
+func NewUserTokenRepository(db *mongo.Database) *UserTokenRepository {
+	return &UserTokenRepository{
+		collection: db.Collection("user_tokens"),
+	}
+}
+
+// Create stores a new token (hashed)
+func (r *UserTokenRepository) Create(ctx context.Context, userToken *models.UserToken, token string) error {
+	userToken.TokenHash = HashToken(token)
+	userToken.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, userToken)
+	if err != nil {
+		return err
+	}
+
+	userToken.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// Consume marks an unused, unexpired token as used and returns it
+// Atomic, so a token can only be redeemed once even under concurrent requests
+func (r *UserTokenRepository) Consume(ctx context.Context, purpose, token string) (*models.UserToken, error) {
+	now := time.Now()
+
+	var userToken models.UserToken
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"token_hash": HashToken(token),
+			"purpose":    purpose,
+			"used_at":    bson.M{"$exists": false},
+			"expires_at": bson.M{"$gt": now},
+		},
+		bson.M{"$set": bson.M{"used_at": now}},
+	).Decode(&userToken)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrUserTokenInvalid
+		}
+		return nil, err
+	}
+	return &userToken, nil
+}
+
+// InvalidateByUser marks a user's outstanding tokens for a purpose as used
+// Called when a newer token is issued or the purpose has been fulfilled
+func (r *UserTokenRepository) InvalidateByUser(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"user_id": userID, "purpose": purpose, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": time.Now()}},
+	)
+	return err
+}
+
+// IssuedSince reports whether a token for the purpose was issued to the user after since
+func (r *UserTokenRepository) IssuedSince(ctx context.Context, userID primitive.ObjectID, purpose string, since time.Time) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"user_id":    userID,
+		"purpose":    purpose,
+		"created_at": bson.M{"$gt": since},
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
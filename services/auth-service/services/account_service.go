@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"zodiac-ai-backend/pkg/mailer"
+	"zodiac-ai-backend/pkg/utils"
+	"zodiac-ai-backend/pkg/validator"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrInvalidUserToken     = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	ErrResendTooSoon        = errors.New("please wait before requesting another email")
+)
+
+const (
+	passwordResetExpiry     = 1 * time.Hour
+	emailVerificationExpiry = 48 * time.Hour
+	emailResendCooldown     = 1 * time.Minute
+)
+
+// AccountService handles password resets and email verification
+type AccountService struct {
+	userRepo         *repositories.UserRepository
+	userTokenRepo    *repositories.UserTokenRepository
+	refreshTokenRepo *repositories.RefreshTokenRepository
+	mailer           mailer.Mailer
+	appURL           string // Frontend base URL for links in emails
+}
+
+// NewAccountService creates a new account service
+func NewAccountService(
+	userRepo *repositories.UserRepository,
+	userTokenRepo *repositories.UserTokenRepository,
+	refreshTokenRepo *repositories.RefreshTokenRepository,
+	mailer mailer.Mailer,
+	appURL string,
+) *AccountService {
+	return &AccountService{
+		userRepo:         userRepo,
+		userTokenRepo:    userTokenRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		mailer:           mailer,
+		appURL:           appURL,
+	}
+}
+
+// ForgotPassword emails a password reset link
+// Only validation happens before returning: the lookup and the email run in the background, so
+// the endpoint answers the same way in about the same time whether or not the email is registered
+func (s *AccountService) ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error {
+	if err := validator.Validate(req); err != nil {
+		return err
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.sendPasswordReset(ctx, req.Email); err != nil {
+			log.Printf("⚠️ Failed to send password reset email: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// sendPasswordReset emails a password reset link if the email is registered
+func (s *AccountService) sendPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if err == repositories.ErrUserNotFound {
+			return nil
+		}
+		return err
+	}
+
+	// Cooldown is also silent: the previous email is still valid
+	recent, err := s.userTokenRepo.IssuedSince(ctx, user.ID, models.TokenPurposePasswordReset, time.Now().Add(-emailResendCooldown))
+	if err != nil || recent {
+		return err
+	}
+
+	token, err := s.issueToken(ctx, user.ID, models.TokenPurposePasswordReset, passwordResetExpiry)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Zodiac AI password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to reset your password. Open this link to choose a new one:\n\n%s\n\nThe link expires in 1 hour. If you didn't request this, you can ignore this email.\n",
+			user.FullName, s.link("/reset-password", token),
+		),
+	}); err != nil {
+		return fmt.Errorf("user %s: %w", user.ID.Hex(), err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token
+// All refresh tokens are revoked, so every session has to log in again
+func (s *AccountService) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
+	if err := validator.Validate(req); err != nil {
+		return err
+	}
+
+	userToken, err := s.userTokenRepo.Consume(ctx, models.TokenPurposePasswordReset, req.Token)
+	if err != nil {
+		if err == repositories.ErrUserTokenInvalid {
+			return ErrInvalidUserToken
+		}
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	// The reset link proves the user owns the email
+	update := bson.M{"password": hashedPassword, "email_verified": true}
+	user, err := s.userRepo.FindByID(ctx, userToken.UserID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		update["email_verified_at"] = time.Now()
+	}
+
+	if err := s.userRepo.Update(ctx, userToken.UserID, update); err != nil {
+		return err
+	}
+
+	if err := s.refreshTokenRepo.RevokeAllByUserID(ctx, userToken.UserID); err != nil {
+		return err
+	}
+
+	// Other reset links sent earlier are no longer needed
+	return s.userTokenRepo.InvalidateByUser(ctx, userToken.UserID, models.TokenPurposePasswordReset)
+}
+
+// VerifyEmail marks the user's email as verified using a verification token
+func (s *AccountService) VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) error {
+	if err := validator.Validate(req); err != nil {
+		return err
+	}
+
+	userToken, err := s.userTokenRepo.Consume(ctx, models.TokenPurposeEmailVerification, req.Token)
+	if err != nil {
+		if err == repositories.ErrUserTokenInvalid {
+			return ErrInvalidUserToken
+		}
+		return err
+	}
+
+	if err := s.userRepo.Update(ctx, userToken.UserID, bson.M{
+		"email_verified":    true,
+		"email_verified_at": time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	return s.userTokenRepo.InvalidateByUser(ctx, userToken.UserID, models.TokenPurposeEmailVerification)
+}
+
+// ResendVerification emails a new verification link to a logged in user
+func (s *AccountService) ResendVerification(ctx context.Context, userID string) error {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	recent, err := s.userTokenRepo.IssuedSince(ctx, user.ID, models.TokenPurposeEmailVerification, time.Now().Add(-emailResendCooldown))
+	if err != nil {
+		return err
+	}
+	if recent {
+		return ErrResendTooSoon
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// SendVerification emails a verification link, replacing any earlier link
+func (s *AccountService) SendVerification(ctx context.Context, user *models.User) error {
+	token, err := s.issueToken(ctx, user.ID, models.TokenPurposeEmailVerification, emailVerificationExpiry)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Zodiac AI email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening this link:\n\n%s\n\nThe link expires in 48 hours.\n",
+			user.FullName, s.link("/verify-email", token),
+		),
+	})
+}
+
+// SendVerificationAsync sends the verification email without delaying the caller (e.g. registration)
+func (s *AccountService) SendVerificationAsync(user *models.User) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.SendVerification(ctx, user); err != nil {
+			log.Printf("⚠️ Failed to send verification email to user %s: %v", user.ID.Hex(), err)
+		}
+	}()
+}
+
+// issueToken invalidates outstanding tokens for the purpose and stores a new one
+func (s *AccountService) issueToken(ctx context.Context, userID primitive.ObjectID, purpose string, expiry time.Duration) (string, error) {
+	if err := s.userTokenRepo.InvalidateByUser(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	userToken := &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(expiry),
+	}
+	if err := s.userTokenRepo.Create(ctx, userToken, token); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// link builds a frontend link carrying a token
+func (s *AccountService) link(path, token string) string {
+	return s.appURL + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"zodiac-ai-backend/pkg/jwt"
+	"zodiac-ai-backend/pkg/validator"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrCannotModerateSelf   = errors.New("cannot perform this action on your own account")
+	ErrTargetIsPrivileged   = errors.New("only admins can act on admins and moderators")
+	ErrCannotRemoveOwnAdmin = errors.New("cannot remove your own admin role")
+)
+
+// Admin is the user performing an admin action, as identified by their access token
+type Admin struct {
+	UserID    string
+	Roles     []string
+	IPAddress string
+}
+
+func (a *Admin) isAdmin() bool {
+	return jwt.HasRole(a.Roles, jwt.RoleAdmin)
+}
+
+// AdminService handles user administration: listing, suspensions, forced logouts and roles
+// Moderators can act on regular users; admins and moderators themselves can only be
+// acted on by admins. Every action is written to the audit log.
+type AdminService struct {
+	userRepo         *repositories.UserRepository
+	refreshTokenRepo *repositories.RefreshTokenRepository
+	auditRepo        *repositories.AuditLogRepository
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(
+	userRepo *repositories.UserRepository,
+	refreshTokenRepo *repositories.RefreshTokenRepository,
+	auditRepo *repositories.AuditLogRepository,
+) *AdminService {
+	return &AdminService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		auditRepo:        auditRepo,
+	}
+}
+
+// ListUsers searches users, newest first
+func (s *AdminService) ListUsers(ctx context.Context, query *models.AdminUserQuery) ([]*models.User, string, error) {
+	if query.Limit <= 0 || query.Limit > 100 {
+		query.Limit = 20
+	}
+
+	return s.userRepo.Search(ctx, query.Query, query.Role, query.Suspended, query.Cursor, query.Limit)
+}
+
+// GetUser gets a user by ID
+func (s *AdminService) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, repositories.ErrUserNotFound
+	}
+
+	return s.userRepo.FindByID(ctx, id)
+}
+
+// Suspend suspends a user, for a number of minutes or until lifted
+// The user's sessions are kept but can't be used or refreshed while the suspension lasts
+func (s *AdminService) Suspend(ctx context.Context, admin *Admin, userID string, req *models.SuspendUserRequest) (*models.User, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.target(ctx, admin, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var until *time.Time
+	if req.DurationMinutes > 0 {
+		t := time.Now().Add(time.Duration(req.DurationMinutes) * time.Minute)
+		until = &t
+	}
+
+	if err := s.userRepo.Suspend(ctx, user.ID, until, req.Reason); err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{"reason": req.Reason}
+	if until != nil {
+		details["suspended_until"] = *until
+	}
+	s.audit(ctx, admin, models.AuditUserSuspended, user, details)
+
+	return s.userRepo.FindByID(ctx, user.ID)
+}
+
+// Unsuspend lifts a user's suspension
+func (s *AdminService) Unsuspend(ctx context.Context, admin *Admin, userID string) (*models.User, error) {
+	user, err := s.target(ctx, admin, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.Unsuspend(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, admin, models.AuditUserUnsuspended, user, nil)
+
+	return s.userRepo.FindByID(ctx, user.ID)
+}
+
+// ForceLogout revokes all of a user's sessions
+// Access tokens already issued stop working once the session cache of each service expires them
+func (s *AdminService) ForceLogout(ctx context.Context, admin *Admin, userID string) error {
+	user, err := s.target(ctx, admin, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.refreshTokenRepo.RevokeAllByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, admin, models.AuditForcedLogout, user, nil)
+	return nil
+}
+
+// SetRoles replaces a user's roles (admins only)
+// Roles reach the user's access token on its next refresh
+func (s *AdminService) SetRoles(ctx context.Context, admin *Admin, userID string, req *models.UpdateRolesRequest) (*models.User, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, repositories.ErrUserNotFound
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := uniqueRoles(req.Roles)
+	if user.ID.Hex() == admin.UserID && !jwt.HasRole(roles, jwt.RoleAdmin) {
+		return nil, ErrCannotRemoveOwnAdmin
+	}
+
+	if err := s.userRepo.Update(ctx, user.ID, bson.M{"roles": roles}); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, admin, models.AuditRolesChanged, user, map[string]interface{}{
+		"previous_roles": user.Roles,
+		"roles":          roles,
+	})
+
+	user.Roles = roles
+	return user, nil
+}
+
+// target loads the user an action applies to and checks the admin may act on them
+func (s *AdminService) target(ctx context.Context, admin *Admin, userID string) (*models.User, error) {
+	if userID == admin.UserID {
+		return nil, ErrCannotModerateSelf
+	}
+
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, repositories.ErrUserNotFound
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(user.Roles) > 0 && !admin.isAdmin() {
+		return nil, ErrTargetIsPrivileged
+	}
+
+	return user, nil
+}
+
+// audit records an admin action; the action itself already applies, so a failed write is only logged
+func (s *AdminService) audit(ctx context.Context, admin *Admin, event string, user *models.User, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["admin_id"] = admin.UserID
+
+	if err := s.auditRepo.Create(ctx, &models.AuditLog{
+		Event:     event,
+		UserID:    &user.ID,
+		Email:     user.Email,
+		IPAddress: admin.IPAddress,
+		Details:   details,
+	}); err != nil {
+		log.Printf("⚠️ Failed to write %s audit log for user %s: %v", event, user.ID.Hex(), err)
+	}
+}
+
+// uniqueRoles drops duplicate roles, keeping their order
+func uniqueRoles(roles []string) []string {
+	unique := []string{}
+	seen := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		if !seen[role] {
+			seen[role] = true
+			unique = append(unique, role)
+		}
+	}
+	return unique
+}
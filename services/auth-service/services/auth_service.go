@@ -11,6 +11,7 @@ import (
 	"zodiac-ai-backend/services/auth-service/models"
 	"zodiac-ai-backend/services/auth-service/repositories"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -18,6 +19,13 @@ import (
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrEmailAlreadyExists = errors.New("email already exists")
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+	ErrSessionRevoked     = errors.New("session has been revoked")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrInvalidDateFormat  = errors.New("invalid date format, expected ISO8601 or YYYY-MM-DD")
+	ErrProfileComplete    = errors.New("profile is already complete")
+	ErrAccountDeleted     = errors.New("account has been deleted")
+	ErrAccountSuspended   = errors.New("account is suspended")
 )
 
 // AuthService handles authentication business logic
@@ -26,6 +34,7 @@ type AuthService struct {
 	userRepo         *repositories.UserRepository
 	refreshTokenRepo *repositories.RefreshTokenRepository
 	jwtManager       *jwt.Manager
+	loginGuard       *LoginGuard
 }
 
 // NewAuthService creates a new auth service
@@ -33,16 +42,18 @@ func NewAuthService(
 	userRepo *repositories.UserRepository,
 	refreshTokenRepo *repositories.RefreshTokenRepository,
 	jwtManager *jwt.Manager,
+	loginGuard *LoginGuard,
 ) *AuthService {
 	return &AuthService{
 		userRepo:         userRepo,
 		refreshTokenRepo: refreshTokenRepo,
 		jwtManager:       jwtManager,
+		loginGuard:       loginGuard,
 	}
 }
 
 // Register registers a new user
-func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest) (*models.AuthResponse, error) {
+func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest, client *models.ClientInfo) (*models.AuthResponse, error) {
 	// Validate input
 	if err := validator.Validate(req); err != nil {
 		return nil, err
@@ -58,13 +69,9 @@ func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest)
 	}
 
 	// Parse date of birth
-	dateOfBirth, err := time.Parse(time.RFC3339, req.DateOfBirth)
+	dateOfBirth, err := parseDateOfBirth(req.DateOfBirth)
 	if err != nil {
-		// Try parsing without time if RFC3339 fails (fallback)
-		dateOfBirth, err = time.Parse("2006-01-02", req.DateOfBirth)
-		if err != nil {
-			return nil, errors.New("invalid date format, expected ISO8601 or YYYY-MM-DD")
-		}
+		return nil, err
 	}
 
 	// Calculate zodiac sign from date of birth
@@ -94,66 +101,137 @@ func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest)
 		return nil, err
 	}
 
-	// Generate tokens
-	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID.Hex(), user.ZodiacSign)
-	if err != nil {
-		return nil, err
-	}
-
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID.Hex(), user.ZodiacSign)
+	// Generate tokens (a new login starts a new session: a new refresh token family)
+	tokens, err := s.issueTokens(ctx, user, newSession(user.ID, client))
 	if err != nil {
 		return nil, err
 	}
 
-	// Store refresh token
-	if err := s.refreshTokenRepo.Create(ctx, user.ID, refreshToken); err != nil {
-		return nil, err
-	}
-
 	// Clear password before returning
 	user.Password = ""
 
 	return &models.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 		User:         user,
 	}, nil
 }
 
 // Login authenticates a user
-func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*models.AuthResponse, error) {
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, client *models.ClientInfo) (*models.AuthResponse, error) {
 	// Validate input
 	if err := validator.Validate(req); err != nil {
 		return nil, err
 	}
 
+	// Refuse locked out accounts and IP addresses before spending time on bcrypt
+	if err := s.loginGuard.Check(ctx, req.Email, client.IPAddress); err != nil {
+		return nil, err
+	}
+
 	// Find user by email
 	user, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
 		if err == repositories.ErrUserNotFound {
-			return nil, ErrInvalidCredentials
+			// Same bcrypt cost and failure tracking as a wrong password: timing and
+			// lockouts mustn't reveal whether the email is registered
+			utils.CheckPasswordDummy(req.Password)
+			return nil, s.loginFailed(ctx, req.Email, client.IPAddress, nil)
 		}
 		return nil, err
 	}
 
 	// Check password
 	if !utils.CheckPassword(req.Password, user.Password) {
-		return nil, ErrInvalidCredentials
+		return nil, s.loginFailed(ctx, req.Email, client.IPAddress, &user.ID)
+	}
+
+	return s.loginUser(ctx, user, client)
+}
+
+// loginUser continues a login whose first factor (password or provider) succeeded
+// Two-factor accounts get a short-lived mfa_pending token instead of a session.
+// Failures aren't cleared yet then: wrong codes keep counting towards the lockout
+func (s *AuthService) loginUser(ctx context.Context, user *models.User, client *models.ClientInfo) (*models.AuthResponse, error) {
+	if user.DeletionRequestedAt != nil {
+		return nil, ErrAccountDeleted
+	}
+	if user.IsSuspended() {
+		return nil, ErrAccountSuspended
+	}
+
+	if user.MFAEnabled {
+		mfaToken, err := s.jwtManager.GenerateMFAPendingToken(user.ID.Hex(), user.ZodiacSign)
+		if err != nil {
+			return nil, err
+		}
+		return &models.AuthResponse{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	return s.completeLogin(ctx, user, client)
+}
+
+// VerifyMFA completes a two-factor login with a TOTP or recovery code
+func (s *AuthService) VerifyMFA(ctx context.Context, req *models.MFAVerifyRequest, client *models.ClientInfo) (*models.AuthResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.jwtManager.VerifyToken(req.MFAToken)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	if err := s.jwtManager.ValidateTokenType(claims, jwt.MFAPending); err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	id, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
 	}
 
-	// Generate tokens
-	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID.Hex(), user.ZodiacSign)
+	user, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
+		if err == repositories.ErrUserNotFound {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+	if !user.MFAEnabled || user.DeletionRequestedAt != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	if user.IsSuspended() {
+		return nil, ErrAccountSuspended
+	}
+
+	// Codes are guessable without the lockout: 6 digits are only a million combinations
+	if err := s.loginGuard.Check(ctx, user.Email, client.IPAddress); err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID.Hex(), user.ZodiacSign)
+	ok, err := checkSecondFactor(ctx, s.userRepo, user, req.Code, req.RecoveryCode)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		if err := s.loginGuard.RecordFailure(ctx, user.Email, client.IPAddress, &user.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidMFACode
+	}
 
-	// Store refresh token
-	if err := s.refreshTokenRepo.Create(ctx, user.ID, refreshToken); err != nil {
+	return s.completeLogin(ctx, user, client)
+}
+
+// completeLogin clears the account's failed logins and starts a new session
+func (s *AuthService) completeLogin(ctx context.Context, user *models.User, client *models.ClientInfo) (*models.AuthResponse, error) {
+	if err := s.loginGuard.RecordSuccess(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	// Generate tokens (a new login starts a new session: a new refresh token family)
+	tokens, err := s.issueTokens(ctx, user, newSession(user.ID, client))
+	if err != nil {
 		return nil, err
 	}
 
@@ -161,38 +239,230 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 	user.Password = ""
 
 	return &models.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 		User:         user,
 	}, nil
 }
 
-// RefreshAccessToken refreshes access token using refresh token
-func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshTokenString string) (string, error) {
+// loginFailed records a failed login and returns the error for it
+func (s *AuthService) loginFailed(ctx context.Context, email, ip string, userID *primitive.ObjectID) error {
+	if err := s.loginGuard.RecordFailure(ctx, email, ip, userID); err != nil {
+		return err
+	}
+	return ErrInvalidCredentials
+}
+
+// RefreshAccessToken rotates a refresh token
+// The presented token is revoked and a new access + refresh token pair is issued in the same family.
+// Presenting a token that was already rotated or revoked means it leaked: the whole family is
+// revoked and ErrRefreshTokenReused is returned, so both the attacker and the user must log in again.
+func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshTokenString string, client *models.ClientInfo) (*models.TokenPair, error) {
 	// Verify refresh token
 	claims, err := s.jwtManager.VerifyToken(refreshTokenString)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Validate token type
 	if err := s.jwtManager.ValidateTokenType(claims, jwt.RefreshToken); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Check if refresh token exists in database
-	_, err = s.refreshTokenRepo.FindByToken(ctx, refreshTokenString)
+	stored, err := s.refreshTokenRepo.FindByToken(ctx, refreshTokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Session was logged out
+	if stored.FamilyRevoked {
+		return nil, ErrSessionRevoked
+	}
+
+	// Legacy tokens (issued before rotation) start their own family
+	if stored.FamilyID == "" {
+		stored.FamilyID = uuid.New().String()
+	}
+
+	if stored.RevokedAt != nil {
+		if _, err := s.refreshTokenRepo.RevokeFamily(ctx, stored.UserID, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	// Roles and suspensions are read fresh on every rotation, so role changes reach the
+	// access token within one access token lifetime. A suspended user's session is kept
+	// (the token isn't claimed) and can be refreshed again once the suspension ends.
+	user, err := s.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		if err == repositories.ErrUserNotFound {
+			return nil, ErrSessionRevoked
+		}
+		return nil, err
+	}
+	if user.DeletionRequestedAt != nil {
+		return nil, ErrAccountDeleted
+	}
+	if user.IsSuspended() {
+		return nil, ErrAccountSuspended
+	}
+
+	// The session continues with the current client details
+	next := &models.RefreshToken{
+		ID:               primitive.NewObjectID(),
+		UserID:           stored.UserID,
+		FamilyID:         stored.FamilyID,
+		Device:           stored.Device,
+		IPAddress:        client.IPAddress,
+		UserAgent:        client.UserAgent,
+		SessionCreatedAt: stored.SessionCreatedAt,
+	}
+	if client.Device != "" {
+		next.Device = client.Device
+	}
+
+	// Claim the token first so two concurrent refreshes can't both rotate it
+	revoked, err := s.refreshTokenRepo.Revoke(ctx, stored, next.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		if _, err := s.refreshTokenRepo.RevokeFamily(ctx, stored.UserID, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	return s.issueTokens(ctx, user, next)
+}
+
+// Logout ends the session of a refresh token
+// Unknown or already revoked tokens are ignored, so logging out twice is harmless
+func (s *AuthService) Logout(ctx context.Context, req *models.LogoutRequest) error {
+	if err := validator.Validate(req); err != nil {
+		return err
+	}
+
+	stored, err := s.refreshTokenRepo.FindByToken(ctx, req.RefreshToken)
+	if err != nil {
+		if err == repositories.ErrTokenNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if stored.FamilyID == "" {
+		// Legacy token without a session
+		return s.refreshTokenRepo.DeleteByID(ctx, stored.ID)
+	}
+
+	_, err = s.refreshTokenRepo.RevokeFamily(ctx, stored.UserID, stored.FamilyID)
+	return err
+}
+
+// LogoutAll ends all of a user's sessions
+func (s *AuthService) LogoutAll(ctx context.Context, userID string) error {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeAllByUserID(ctx, id)
+}
+
+// GetSessions lists a user's active sessions
+// currentSessionID marks the session making the request
+func (s *AuthService) GetSessions(ctx context.Context, userID, currentSessionID string) ([]*models.Session, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.refreshTokenRepo.FindActiveByUserID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*models.Session, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, &models.Session{
+			ID:         token.FamilyID,
+			Device:     token.Device,
+			IPAddress:  token.IPAddress,
+			UserAgent:  token.UserAgent,
+			Current:    token.FamilyID == currentSessionID,
+			CreatedAt:  token.SessionCreatedAt,
+			LastUsedAt: token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends one of a user's sessions
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	found, err := s.refreshTokenRepo.RevokeFamily(ctx, id, sessionID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// newSession returns the first refresh token of a new session
+func newSession(userID primitive.ObjectID, client *models.ClientInfo) *models.RefreshToken {
+	return &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  uuid.New().String(),
+		Device:    client.Device,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	}
+}
+
+// issueTokens generates an access + refresh token pair for a session and stores the refresh token
+func (s *AuthService) issueTokens(ctx context.Context, user *models.User, session *models.RefreshToken) (*models.TokenPair, error) {
+	userID := session.UserID.Hex()
+
+	accessToken, err := s.jwtManager.GenerateAccessToken(userID, user.ZodiacSign, session.FamilyID, user.Roles)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Generate new access token
-	accessToken, err := s.jwtManager.GenerateAccessToken(claims.UserID, claims.ZodiacSign)
+	refreshToken, err := s.jwtManager.GenerateRefreshToken(userID, user.ZodiacSign)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	// Store refresh token (hashed)
+	session.ExpiresAt = time.Now().Add(s.jwtManager.RefreshExpiry())
+	if err := s.refreshTokenRepo.Create(ctx, session, refreshToken); err != nil {
+		return nil, err
 	}
 
-	return accessToken, nil
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// PublicKeys returns the token verification keys as a JWKS
+// Empty when tokens are signed with the HS256 shared secret
+func (s *AuthService) PublicKeys() jwt.JWKS {
+	if keys := s.jwtManager.KeySet(); keys != nil {
+		return keys.JWKS()
+	}
+	return jwt.JWKS{Keys: []jwt.JWK{}}
 }
 
 // GetProfile gets user profile
@@ -212,6 +482,75 @@ func (s *AuthService) GetProfile(ctx context.Context, userID string) (*models.Us
 	return user, nil
 }
 
+// CompleteProfile sets the date of birth and gender of a user who signed up through a provider
+// The zodiac sign is calculated once, as at registration. Returns a new access token for the
+// current session, since the one in use carries no zodiac sign.
+func (s *AuthService) CompleteProfile(ctx context.Context, userID, sessionID string, req *models.CompleteProfileRequest) (*models.AuthResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.ProfileIncomplete {
+		return nil, ErrProfileComplete
+	}
+
+	dateOfBirth, err := parseDateOfBirth(req.DateOfBirth)
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{
+		"date_of_birth":      dateOfBirth,
+		"gender":             req.Gender,
+		"zodiac_sign":        string(utils.CalculateZodiac(dateOfBirth)),
+		"profile_incomplete": false,
+	}
+	if req.FullName != "" {
+		update["full_name"] = req.FullName
+	}
+
+	if err := s.userRepo.Update(ctx, id, update); err != nil {
+		return nil, err
+	}
+
+	user, err = s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.jwtManager.GenerateAccessToken(userID, user.ZodiacSign, sessionID, user.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthResponse{
+		AccessToken: accessToken,
+		User:        user,
+	}, nil
+}
+
+// parseDateOfBirth accepts ISO8601 or a plain YYYY-MM-DD date
+func parseDateOfBirth(value string) (time.Time, error) {
+	dateOfBirth, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// Try parsing without time if RFC3339 fails (fallback)
+		dateOfBirth, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			return time.Time{}, ErrInvalidDateFormat
+		}
+	}
+	return dateOfBirth, nil
+}
+
 // UpdateProfile updates user profile
 func (s *AuthService) UpdateProfile(ctx context.Context, userID string, req *models.UpdateProfileRequest) (*models.User, error) {
 	id, err := primitive.ObjectIDFromHex(userID)
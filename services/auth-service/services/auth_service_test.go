@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"zodiac-ai-backend/pkg/database/mongotest"
+	"zodiac-ai-backend/pkg/jwt"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// authTestEnv is an AuthService on a throwaway database
+type authTestEnv struct {
+	db         *mongo.Database
+	userRepo   *repositories.UserRepository
+	tokenRepo  *repositories.RefreshTokenRepository
+	jwtManager *jwt.Manager
+	auth       *AuthService
+}
+
+func newAuthTestEnv(t *testing.T) *authTestEnv {
+	t.Helper()
+
+	db := mongotest.NewDatabase(t)
+	env := &authTestEnv{
+		db:         db,
+		userRepo:   repositories.NewUserRepository(db),
+		tokenRepo:  repositories.NewRefreshTokenRepository(db),
+		jwtManager: jwt.NewManager("test-secret", 15*time.Minute, 24*time.Hour),
+	}
+	guard := NewLoginGuard(repositories.NewLoginAttemptRepository(db), repositories.NewAuditLogRepository(db), LockoutConfig{})
+	env.auth = NewAuthService(env.userRepo, env.tokenRepo, env.jwtManager, guard)
+	return env
+}
+
+var testClient = &models.ClientInfo{Device: "Test", IPAddress: "203.0.113.7", UserAgent: "go-test"}
+
+// register creates a user and returns the tokens of their first session
+func (e *authTestEnv) register(t *testing.T, email string) *models.AuthResponse {
+	t.Helper()
+
+	resp, err := e.auth.Register(context.Background(), &models.RegisterRequest{
+		Email:       email,
+		Password:    "correct horse battery",
+		FullName:    "Test User",
+		DateOfBirth: "1990-08-01",
+		Gender:      "other",
+	}, testClient)
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	return resp
+}
+
+// login starts another session
+func (e *authTestEnv) login(t *testing.T, email string) *models.AuthResponse {
+	t.Helper()
+
+	resp, err := e.auth.Login(context.Background(), &models.LoginRequest{Email: email, Password: "correct horse battery"}, testClient)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return resp
+}
+
+// sessionID returns the session an access token belongs to
+func (e *authTestEnv) sessionID(t *testing.T, accessToken string) string {
+	t.Helper()
+
+	claims, err := e.jwtManager.VerifyToken(accessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return claims.SessionID
+}
+
+func (e *authTestEnv) sessionActive(t *testing.T, sessionID string) bool {
+	t.Helper()
+
+	active, err := e.tokenRepo.IsSessionActive(context.Background(), sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return active
+}
+
+func TestLogoutLegacyToken(t *testing.T) {
+	env := newAuthTestEnv(t)
+	ctx := context.Background()
+	user := env.register(t, "legacy@example.com").User
+
+	// A token stored before rotation (hashed by the migration): no family
+	legacy, err := env.jwtManager.GenerateRefreshToken(user.ID.Hex(), user.ZodiacSign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = env.db.Collection("refresh_tokens").InsertOne(ctx, bson.M{
+		"user_id":    user.ID,
+		"token_hash": repositories.HashToken(legacy),
+		"expires_at": time.Now().Add(time.Hour),
+		"created_at": time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := env.auth.Logout(ctx, &models.LogoutRequest{RefreshToken: legacy}); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+
+	if _, err := env.auth.RefreshAccessToken(ctx, legacy, testClient); err != repositories.ErrTokenNotFound {
+		t.Fatalf("refresh after logout: err = %v, want ErrTokenNotFound", err)
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	env := newAuthTestEnv(t)
+	ctx := context.Background()
+
+	first := env.register(t, "sessions@example.com")
+	second := env.login(t, "sessions@example.com")
+	userID := first.User.ID.Hex()
+	firstID, secondID := env.sessionID(t, first.AccessToken), env.sessionID(t, second.AccessToken)
+
+	if err := env.auth.RevokeSession(ctx, userID, firstID); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+
+	if env.sessionActive(t, firstID) {
+		t.Fatal("revoked session is still active")
+	}
+	if !env.sessionActive(t, secondID) {
+		t.Fatal("revoking one session ended the other")
+	}
+	if _, err := env.auth.RefreshAccessToken(ctx, first.RefreshToken, testClient); err != ErrSessionRevoked {
+		t.Fatalf("refresh of a revoked session: err = %v, want ErrSessionRevoked", err)
+	}
+
+	if err := env.auth.RevokeSession(ctx, userID, "no-such-session"); err != ErrSessionNotFound {
+		t.Fatalf("unknown session: err = %v, want ErrSessionNotFound", err)
+	}
+
+	// Another user can't revoke the session
+	other := env.register(t, "other@example.com")
+	if err := env.auth.RevokeSession(ctx, other.User.ID.Hex(), secondID); err != ErrSessionNotFound {
+		t.Fatalf("another user's session: err = %v, want ErrSessionNotFound", err)
+	}
+	if !env.sessionActive(t, secondID) {
+		t.Fatal("another user revoked the session")
+	}
+}
+
+func TestLogoutAll(t *testing.T) {
+	env := newAuthTestEnv(t)
+	ctx := context.Background()
+
+	first := env.register(t, "everywhere@example.com")
+	second := env.login(t, "everywhere@example.com")
+
+	if err := env.auth.LogoutAll(ctx, first.User.ID.Hex()); err != nil {
+		t.Fatalf("LogoutAll: %v", err)
+	}
+
+	for _, resp := range []*models.AuthResponse{first, second} {
+		if env.sessionActive(t, env.sessionID(t, resp.AccessToken)) {
+			t.Fatal("session still active after LogoutAll")
+		}
+		if _, err := env.auth.RefreshAccessToken(ctx, resp.RefreshToken, testClient); err != ErrSessionRevoked {
+			t.Fatalf("refresh after LogoutAll: err = %v, want ErrSessionRevoked", err)
+		}
+	}
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	env := newAuthTestEnv(t)
+	ctx := context.Background()
+	first := env.register(t, "rotate@example.com")
+
+	next, err := env.auth.RefreshAccessToken(ctx, first.RefreshToken, testClient)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken: %v", err)
+	}
+	if next.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh returned the same refresh token")
+	}
+	if env.sessionID(t, next.AccessToken) != env.sessionID(t, first.AccessToken) {
+		t.Fatal("rotation started a new session")
+	}
+
+	// The old token is kept (revoked, pointing at its successor) for reuse detection
+	old, err := env.tokenRepo.FindByToken(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	successor, err := env.tokenRepo.FindByToken(ctx, next.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.RevokedAt == nil || old.ReplacedBy == nil || *old.ReplacedBy != successor.ID {
+		t.Fatalf("rotated token: revoked_at=%v replaced_by=%v, want revoked and replaced by %s", old.RevokedAt, old.ReplacedBy, successor.ID.Hex())
+	}
+
+	// The new token rotates in turn
+	if _, err := env.auth.RefreshAccessToken(ctx, next.RefreshToken, testClient); err != nil {
+		t.Fatalf("refresh with the new token: %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	env := newAuthTestEnv(t)
+	ctx := context.Background()
+	first := env.register(t, "reuse@example.com")
+	sessionID := env.sessionID(t, first.AccessToken)
+
+	second, err := env.auth.RefreshAccessToken(ctx, first.RefreshToken, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	third, err := env.auth.RefreshAccessToken(ctx, second.RefreshToken, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replaying a rotated token: someone else holds a copy
+	if _, err := env.auth.RefreshAccessToken(ctx, first.RefreshToken, testClient); err != ErrRefreshTokenReused {
+		t.Fatalf("replay: err = %v, want ErrRefreshTokenReused", err)
+	}
+
+	// The whole family is revoked, including the newest token
+	if env.sessionActive(t, sessionID) {
+		t.Fatal("session still active after reuse")
+	}
+	if _, err := env.auth.RefreshAccessToken(ctx, third.RefreshToken, testClient); err != ErrSessionRevoked {
+		t.Fatalf("newest token after reuse: err = %v, want ErrSessionRevoked", err)
+	}
+	newest, err := env.tokenRepo.FindByToken(ctx, third.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !newest.FamilyRevoked || newest.RevokedAt == nil {
+		t.Fatalf("newest token: family_revoked=%v revoked_at=%v, want both set", newest.FamilyRevoked, newest.RevokedAt)
+	}
+
+	// Other sessions of the user are untouched
+	other := env.login(t, "reuse@example.com")
+	if _, err := env.auth.RefreshAccessToken(ctx, other.RefreshToken, testClient); err != nil {
+		t.Fatalf("other session: %v", err)
+	}
+}
+
+func TestRefreshTokensStoredHashed(t *testing.T) {
+	env := newAuthTestEnv(t)
+	ctx := context.Background()
+	resp := env.register(t, "hashed@example.com")
+
+	var stored bson.M
+	if err := env.db.Collection("refresh_tokens").FindOne(ctx, bson.M{"user_id": resp.User.ID}).Decode(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored["token_hash"] != repositories.HashToken(resp.RefreshToken) {
+		t.Fatalf("token_hash = %v, want the token's hash", stored["token_hash"])
+	}
+	for key, value := range stored {
+		if value == resp.RefreshToken {
+			t.Fatalf("plaintext token stored in %q", key)
+		}
+	}
+}
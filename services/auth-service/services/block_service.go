@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrCannotBlockSelf = errors.New("cannot block yourself")
+	ErrNotBlocked      = errors.New("user is not blocked")
+)
+
+// BlockService handles blocking users
+// A block hides both users from each other; the social and chat services read the
+// shared blocks collection to leave blocked users out of comments, the feed and rooms.
+type BlockService struct {
+	blockRepo      *repositories.BlockRepository
+	friendshipRepo *repositories.FriendshipRepository
+	userRepo       *repositories.UserRepository
+	suggestions    *SuggestionCache
+}
+
+// NewBlockService creates a new block service
+func NewBlockService(
+	blockRepo *repositories.BlockRepository,
+	friendshipRepo *repositories.FriendshipRepository,
+	userRepo *repositories.UserRepository,
+	suggestions *SuggestionCache,
+) *BlockService {
+	return &BlockService{
+		blockRepo:      blockRepo,
+		friendshipRepo: friendshipRepo,
+		userRepo:       userRepo,
+		suggestions:    suggestions,
+	}
+}
+
+// Block blocks a user
+// An existing friendship and pending requests either way are removed in the same transaction
+func (s *BlockService) Block(ctx context.Context, userID, targetID string) error {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	targetObjID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return repositories.ErrUserNotFound
+	}
+
+	if userObjID == targetObjID {
+		return ErrCannotBlockSelf
+	}
+
+	if _, err := s.userRepo.FindByID(ctx, targetObjID); err != nil {
+		return err
+	}
+
+	err = s.friendshipRepo.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.blockRepo.Create(txCtx, userObjID, targetObjID); err != nil {
+			return err
+		}
+		return s.friendshipRepo.SeverUsers(txCtx, userObjID, targetObjID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.suggestions.Invalidate(userObjID, targetObjID)
+	return nil
+}
+
+// Unblock unblocks a user; a friendship removed by the block isn't restored
+func (s *BlockService) Unblock(ctx context.Context, userID, targetID string) error {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	targetObjID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return ErrNotBlocked
+	}
+
+	if err := s.blockRepo.Delete(ctx, userObjID, targetObjID); err != nil {
+		if err == repositories.ErrBlockNotFound {
+			return ErrNotBlocked
+		}
+		return err
+	}
+
+	s.suggestions.Invalidate(userObjID, targetObjID)
+	return nil
+}
+
+// ListBlocked lists the users a user blocked, newest first
+func (s *BlockService) ListBlocked(ctx context.Context, userID string, query *models.BlockListQuery) ([]*models.BlockedUser, string, error) {
+	if query.Limit <= 0 || query.Limit > 50 {
+		query.Limit = 20
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	blocks, nextCursor, err := s.blockRepo.FindByBlocker(ctx, userObjID, query.Cursor, query.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	blockedIDs := make([]primitive.ObjectID, 0, len(blocks))
+	for _, block := range blocks {
+		blockedIDs = append(blockedIDs, block.BlockedID)
+	}
+
+	users, err := resolveSummaries(ctx, s.userRepo, blockedIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	byID := make(map[primitive.ObjectID]*models.UserSummary, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	// Accounts being deleted are left out
+	blocked := make([]*models.BlockedUser, 0, len(blocks))
+	for _, block := range blocks {
+		if user, ok := byID[block.BlockedID]; ok {
+			blocked = append(blocked, &models.BlockedUser{
+				User:      user,
+				BlockedAt: block.CreatedAt,
+			})
+		}
+	}
+
+	return blocked, nextCursor, nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// deletionLease is how long a worker owns a job; a job whose worker crashed is picked up again after it
+	deletionLease = 10 * time.Minute
+	// deletionMaxRetryDelay caps the delay between attempts of a failing job
+	deletionMaxRetryDelay = 1 * time.Hour
+)
+
+// deletionStep is one idempotent stage of an account deletion
+type deletionStep struct {
+	name string
+	run  func(ctx context.Context, w *DeletionWorker, job *models.AccountDeletion) (bson.M, error)
+}
+
+// deletionSteps run in order. Counters are recounted from the remaining records rather than
+// decremented, so a step that is retried after a partial run can't skew them.
+var deletionSteps = []deletionStep{
+	{"capture", func(ctx context.Context, w *DeletionWorker, job *models.AccountDeletion) (bson.M, error) {
+		users, posts, rooms, err := w.dataRepo.AffectedRecords(ctx, job.UserID)
+		if err != nil {
+			return nil, err
+		}
+		job.AffectedUserIDs, job.AffectedPostIDs, job.AffectedRoomIDs = users, posts, rooms
+		return bson.M{"affected_user_ids": users, "affected_post_ids": posts, "affected_room_ids": rooms}, nil
+	}},
+	{"friendships", func(ctx context.Context, w *DeletionWorker, job *models.AccountDeletion) (bson.M, error) {
+		return nil, w.dataRepo.DeleteFriendships(ctx, job.UserID)
+	}},
+	{"social", func(ctx context.Context, w *DeletionWorker, job *models.AccountDeletion) (bson.M, error) {
+		return nil, w.dataRepo.DeleteSocial(ctx, job.UserID)
+	}},
+	{"chat", func(ctx context.Context, w *DeletionWorker, job *models.AccountDeletion) (bson.M, error) {
+		return nil, w.dataRepo.DeleteChat(ctx, job.UserID)
+	}},
+	{"security", func(ctx context.Context, w *DeletionWorker, job *models.AccountDeletion) (bson.M, error) {
+		return nil, w.dataRepo.DeleteSecurityRecords(ctx, job.UserID, job.Email, emailAttemptKey(job.Email))
+	}},
+	{"counters", func(ctx context.Context, w *DeletionWorker, job *models.AccountDeletion) (bson.M, error) {
+		if err := w.dataRepo.RecountUsers(ctx, job.AffectedUserIDs); err != nil {
+			return nil, err
+		}
+		if err := w.dataRepo.RecountPosts(ctx, job.AffectedPostIDs); err != nil {
+			return nil, err
+		}
+		return nil, w.dataRepo.RecountRooms(ctx, job.AffectedRoomIDs)
+	}},
+	{"user", func(ctx context.Context, w *DeletionWorker, job *models.AccountDeletion) (bson.M, error) {
+		return nil, w.dataRepo.DeleteUser(ctx, job.UserID)
+	}},
+}
+
+// DeletionWorker runs queued account deletions in the background
+// Jobs are claimed with a lease, so several service replicas can run workers side by side.
+type DeletionWorker struct {
+	deletionRepo *repositories.AccountDeletionRepository
+	dataRepo     *repositories.UserDataRepository
+	interval     time.Duration
+
+	wake   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDeletionWorker creates a new deletion worker
+// interval is how often it looks for jobs it wasn't notified about (other replicas, retries)
+func NewDeletionWorker(
+	deletionRepo *repositories.AccountDeletionRepository,
+	dataRepo *repositories.UserDataRepository,
+	interval time.Duration,
+) *DeletionWorker {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &DeletionWorker{
+		deletionRepo: deletionRepo,
+		dataRepo:     dataRepo,
+		interval:     interval,
+		wake:         make(chan struct{}, 1),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Start starts processing jobs
+func (w *DeletionWorker) Start() {
+	w.wg.Add(1)
+	go w.run()
+}
+
+// Stop stops the worker and waits for the current step to finish
+// An interrupted job resumes from its last completed step once its lease expires
+func (w *DeletionWorker) Stop() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// Notify wakes the worker up for a newly queued job
+func (w *DeletionWorker) Notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *DeletionWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.processAll()
+
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// processAll runs jobs until none is left to claim
+func (w *DeletionWorker) processAll() {
+	for w.ctx.Err() == nil {
+		job, err := w.deletionRepo.Claim(w.ctx, deletionLease)
+		if err != nil {
+			if err != repositories.ErrNoDeletionJob && w.ctx.Err() == nil {
+				log.Printf("⚠️ Account deletion: failed to claim a job: %v", err)
+			}
+			return
+		}
+
+		if err := w.process(w.ctx, job); err != nil {
+			log.Printf("⚠️ Account deletion of user %s failed (attempt %d): %v", job.UserID.Hex(), job.Attempts, err)
+
+			// Fail with a fresh context: the worker's may have been cancelled mid-step
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := w.deletionRepo.Fail(ctx, job.ID, err, time.Now().Add(retryDelay(job.Attempts))); err != nil {
+				log.Printf("⚠️ Account deletion: failed to record error: %v", err)
+			}
+			cancel()
+			continue
+		}
+
+		log.Printf("✅ Account deletion of user %s completed", job.UserID.Hex())
+	}
+}
+
+// process runs the steps a job hasn't completed yet
+func (w *DeletionWorker) process(ctx context.Context, job *models.AccountDeletion) error {
+	for _, step := range deletionSteps {
+		if job.HasCompleted(step.name) {
+			continue
+		}
+
+		set, err := step.run(ctx, w, job)
+		if err != nil {
+			return fmt.Errorf("step %s: %w", step.name, err)
+		}
+		if err := w.deletionRepo.CompleteStep(ctx, job.ID, step.name, set); err != nil {
+			return fmt.Errorf("step %s: %w", step.name, err)
+		}
+		job.CompletedSteps = append(job.CompletedSteps, step.name)
+	}
+
+	return w.deletionRepo.Complete(ctx, job.ID)
+}
+
+// retryDelay doubles the wait after each failed attempt, up to deletionMaxRetryDelay
+func retryDelay(attempts int) time.Duration {
+	delay := time.Minute
+	for i := 1; i < attempts && delay < deletionMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > deletionMaxRetryDelay {
+		delay = deletionMaxRetryDelay
+	}
+	return delay
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 
+	"zodiac-ai-backend/pkg/utils"
+	"zodiac-ai-backend/pkg/validator"
 	"zodiac-ai-backend/services/auth-service/models"
 	"zodiac-ai-backend/services/auth-service/repositories"
 
@@ -14,71 +16,123 @@ var (
 	ErrAlreadyFriends    = errors.New("already friends")
 	ErrRequestNotFound   = errors.New("friend request not found")
 	ErrUnauthorized      = errors.New("unauthorized action")
+	ErrCannotFriendSelf  = errors.New("cannot send a friend request to yourself")
+	ErrRequestReceived   = errors.New("this user already sent you a friend request")
+	ErrRequestNotPending = errors.New("friend request is no longer pending")
+	ErrNotFriends        = errors.New("not friends")
+	ErrUserBlocked       = errors.New("a block exists between the users")
+	ErrZodiacSignNotSet  = errors.New("zodiac sign not set")
 )
 
 // FriendshipService handles friendship business logic
 type FriendshipService struct {
 	friendshipRepo *repositories.FriendshipRepository
 	userRepo       *repositories.UserRepository
+	blockRepo      *repositories.BlockRepository
+	suggestions    *SuggestionCache
 }
 
 // NewFriendshipService creates a new friendship service
 func NewFriendshipService(
 	friendshipRepo *repositories.FriendshipRepository,
 	userRepo *repositories.UserRepository,
+	blockRepo *repositories.BlockRepository,
+	suggestions *SuggestionCache,
 ) *FriendshipService {
 	return &FriendshipService{
 		friendshipRepo: friendshipRepo,
 		userRepo:       userRepo,
+		blockRepo:      blockRepo,
+		suggestions:    suggestions,
 	}
 }
 
 // SendFriendRequest sends a friend request
-func (s *FriendshipService) SendFriendRequest(ctx context.Context, senderID, targetID string) error {
+// Sending the same request again is idempotent and returns the pending request
+func (s *FriendshipService) SendFriendRequest(ctx context.Context, senderID, targetID string) (*models.FriendRequest, error) {
 	senderObjID, err := primitive.ObjectIDFromHex(senderID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	targetObjID, err := primitive.ObjectIDFromHex(targetID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if senderObjID == targetObjID {
+		return nil, ErrCannotFriendSelf
+	}
+
+	// Blocked either way: the sender isn't told who blocked whom
+	blocked, err := s.blockRepo.ExistsBetween(ctx, senderObjID, targetObjID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrUserBlocked
 	}
 
 	// Check if already friends
 	areFriends, err := s.friendshipRepo.CheckFriendship(ctx, senderObjID, targetObjID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if areFriends {
-		return ErrAlreadyFriends
+		return nil, ErrAlreadyFriends
 	}
 
 	// Ensure both users have friendship documents
-	_, err = s.friendshipRepo.GetOrCreateFriendship(ctx, senderObjID)
-	if err != nil {
-		return err
+	if _, err := s.friendshipRepo.GetOrCreateFriendship(ctx, senderObjID); err != nil {
+		return nil, err
 	}
-	_, err = s.friendshipRepo.GetOrCreateFriendship(ctx, targetObjID)
-	if err != nil {
-		return err
+	if _, err := s.friendshipRepo.GetOrCreateFriendship(ctx, targetObjID); err != nil {
+		return nil, err
 	}
 
-	// Create friend request
-	_, err = s.friendshipRepo.CreateFriendRequest(ctx, senderObjID, targetObjID)
-	if err != nil {
-		return err
+	// Request and both pending lists are written in one transaction. Requests sent both ways at
+	// once write the same two friendship documents, so one of them hits a write conflict and is
+	// retried, and then sees the other's request.
+	var request *models.FriendRequest
+	send := func(txCtx context.Context) error {
+		// The other direction is pending: the sender should answer it instead
+		received, err := s.friendshipRepo.PendingRequestExists(txCtx, targetObjID, senderObjID)
+		if err != nil {
+			return err
+		}
+		if received {
+			return ErrRequestReceived
+		}
+
+		// Create (or reset) friend request
+		created, err := s.friendshipRepo.CreateFriendRequest(txCtx, senderObjID, targetObjID)
+		if err != nil {
+			return err
+		}
+
+		// Update friendship documents
+		if err := s.friendshipRepo.AddPendingSent(txCtx, senderObjID, targetObjID); err != nil {
+			return err
+		}
+		if err := s.friendshipRepo.AddPendingReceived(txCtx, targetObjID, senderObjID); err != nil {
+			return err
+		}
+
+		request = created
+		return nil
 	}
 
-	// Update friendship documents
-	if err := s.friendshipRepo.AddPendingSent(ctx, senderObjID, targetObjID); err != nil {
-		return err
+	err = s.friendshipRepo.WithTransaction(ctx, send)
+	if err == repositories.ErrFriendRequestConflict {
+		// The same request was sent concurrently; retrying finds it and returns it unchanged
+		err = s.friendshipRepo.WithTransaction(ctx, send)
 	}
-	if err := s.friendshipRepo.AddPendingReceived(ctx, targetObjID, senderObjID); err != nil {
-		return err
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	s.suggestions.Invalidate(senderObjID, targetObjID)
+	return request, nil
 }
 
 // AcceptFriendRequest accepts a friend request
@@ -103,25 +157,33 @@ func (s *FriendshipService) AcceptFriendRequest(ctx context.Context, requestID,
 	if request.ReceiverID != userObjID {
 		return ErrUnauthorized
 	}
-
-	// Accept friendship (transaction)
-	if err := s.friendshipRepo.AcceptFriendship(ctx, userObjID, request.SenderID); err != nil {
-		return err
+	if request.Status != models.StatusPending {
+		return ErrRequestNotPending
 	}
 
-	// Update request status
-	if err := s.friendshipRepo.UpdateRequestStatus(ctx, reqObjID, models.StatusAccepted); err != nil {
-		return err
-	}
+	// Request status, both friend lists and both friends counts change in one transaction
+	err = s.friendshipRepo.WithTransaction(ctx, func(txCtx context.Context) error {
+		// Update request status (fails if accepted concurrently, so counts aren't incremented twice)
+		if err := s.friendshipRepo.UpdateRequestStatus(txCtx, reqObjID, models.StatusAccepted); err != nil {
+			return err
+		}
 
-	// Increment friends count for both users (atomic)
-	if err := s.userRepo.IncrementFriendsCount(ctx, userObjID, 1); err != nil {
-		return err
-	}
-	if err := s.userRepo.IncrementFriendsCount(ctx, request.SenderID, 1); err != nil {
-		return err
+		// Accept friendship
+		if err := s.friendshipRepo.AcceptFriendship(txCtx, userObjID, request.SenderID); err != nil {
+			return err
+		}
+
+		// Increment friends count for both users
+		if err := s.userRepo.IncrementFriendsCount(txCtx, userObjID, 1); err != nil {
+			return err
+		}
+		return s.userRepo.IncrementFriendsCount(txCtx, request.SenderID, 1)
+	})
+	if err != nil {
+		return requestError(err)
 	}
 
+	s.suggestions.Invalidate(userObjID, request.SenderID)
 	return nil
 }
 
@@ -147,28 +209,161 @@ func (s *FriendshipService) RejectFriendRequest(ctx context.Context, requestID,
 	if request.ReceiverID != userObjID {
 		return ErrUnauthorized
 	}
+	if request.Status != models.StatusPending {
+		return ErrRequestNotPending
+	}
+
+	err = s.friendshipRepo.WithTransaction(ctx, func(txCtx context.Context) error {
+		// Update request status
+		if err := s.friendshipRepo.UpdateRequestStatus(txCtx, reqObjID, models.StatusRejected); err != nil {
+			return err
+		}
+
+		// Reject friendship
+		return s.friendshipRepo.RejectFriendship(txCtx, userObjID, request.SenderID)
+	})
+	if err != nil {
+		return requestError(err)
+	}
+
+	s.suggestions.Invalidate(userObjID, request.SenderID)
+	return nil
+}
+
+// CancelFriendRequest withdraws a friend request the user sent
+func (s *FriendshipService) CancelFriendRequest(ctx context.Context, requestID, userID string) error {
+	reqObjID, err := primitive.ObjectIDFromHex(requestID)
+	if err != nil {
+		return ErrRequestNotFound
+	}
 
-	// Reject friendship
-	if err := s.friendshipRepo.RejectFriendship(ctx, userObjID, request.SenderID); err != nil {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
 		return err
 	}
 
-	// Update request status
-	if err := s.friendshipRepo.UpdateRequestStatus(ctx, reqObjID, models.StatusRejected); err != nil {
+	// Find request
+	request, err := s.friendshipRepo.FindRequestByID(ctx, reqObjID)
+	if err != nil {
+		return ErrRequestNotFound
+	}
+
+	// Verify user is the sender
+	if request.SenderID != userObjID {
+		return ErrUnauthorized
+	}
+	if request.Status != models.StatusPending {
+		return ErrRequestNotPending
+	}
+
+	err = s.friendshipRepo.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.friendshipRepo.UpdateRequestStatus(txCtx, reqObjID, models.StatusCancelled); err != nil {
+			return err
+		}
+
+		// Same cleanup of the pending lists as a rejection
+		return s.friendshipRepo.RejectFriendship(txCtx, request.ReceiverID, userObjID)
+	})
+	if err != nil {
+		return requestError(err)
+	}
+
+	s.suggestions.Invalidate(userObjID, request.ReceiverID)
+	return nil
+}
+
+// ListFriendRequests lists the user's pending incoming (default) or outgoing friend requests
+func (s *FriendshipService) ListFriendRequests(ctx context.Context, userID string, query *models.FriendRequestQuery) ([]*models.PendingFriendRequest, string, error) {
+	if err := validator.Validate(query); err != nil {
+		return nil, "", err
+	}
+	if query.Direction == "" {
+		query.Direction = "incoming"
+	}
+	if query.Limit <= 0 || query.Limit > 50 {
+		query.Limit = 20
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	incoming := query.Direction == "incoming"
+	requests, nextCursor, err := s.friendshipRepo.FindPendingRequests(ctx, userObjID, incoming, query.Cursor, query.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	otherIDs := make([]primitive.ObjectID, 0, len(requests))
+	for _, request := range requests {
+		if incoming {
+			otherIDs = append(otherIDs, request.SenderID)
+		} else {
+			otherIDs = append(otherIDs, request.ReceiverID)
+		}
+	}
+
+	users, err := resolveSummaries(ctx, s.userRepo, otherIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	byID := make(map[primitive.ObjectID]*models.UserSummary, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	// Requests from or to accounts being deleted are left out
+	pending := make([]*models.PendingFriendRequest, 0, len(requests))
+	for i, request := range requests {
+		if user, ok := byID[otherIDs[i]]; ok {
+			pending = append(pending, &models.PendingFriendRequest{
+				ID:        request.ID,
+				User:      user,
+				CreatedAt: request.CreatedAt,
+			})
+		}
+	}
+
+	return pending, nextCursor, nil
+}
+
+// Unfriend removes a friendship in both directions
+func (s *FriendshipService) Unfriend(ctx context.Context, userID, friendID string) error {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
 		return err
 	}
 
+	friendObjID, err := primitive.ObjectIDFromHex(friendID)
+	if err != nil {
+		return ErrNotFriends
+	}
+
+	if err := s.friendshipRepo.RemoveFriendship(ctx, userObjID, friendObjID); err != nil {
+		if err == repositories.ErrFriendshipNotFound {
+			return ErrNotFriends
+		}
+		return err
+	}
+
+	s.suggestions.Invalidate(userObjID, friendObjID)
 	return nil
 }
 
-// GetFriends gets list of friends
-func (s *FriendshipService) GetFriends(ctx context.Context, userID string) ([]primitive.ObjectID, error) {
+// GetFriends gets list of friends with their display name, avatar and sign
+func (s *FriendshipService) GetFriends(ctx context.Context, userID string) ([]*models.UserSummary, error) {
 	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.friendshipRepo.GetFriends(ctx, userObjID)
+	friendIDs, err := s.friendshipRepo.GetFriends(ctx, userObjID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveSummaries(ctx, s.userRepo, friendIDs)
 }
 
 // CheckFriendshipStatus checks friendship status between two users
@@ -216,3 +411,55 @@ func (s *FriendshipService) CheckFriendshipStatus(ctx context.Context, userID, t
 
 	return "NOT_FRIENDS", nil
 }
+
+// GetCompatibility rates the zodiac compatibility of the user (sign A) with another user (sign B)
+// Works for any user who isn't blocked either way, e.g. to show it next to a suggestion
+func (s *FriendshipService) GetCompatibility(ctx context.Context, userID, otherID string) (*utils.Compatibility, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	otherObjID, err := primitive.ObjectIDFromHex(otherID)
+	if err != nil {
+		return nil, repositories.ErrUserNotFound
+	}
+
+	// Blocked users are hidden, like in the other friendship endpoints
+	blocked, err := s.blockRepo.ExistsBetween(ctx, userObjID, otherObjID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, repositories.ErrUserNotFound
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userObjID)
+	if err != nil {
+		return nil, err
+	}
+
+	other, err := s.userRepo.FindByID(ctx, otherObjID)
+	if err != nil {
+		return nil, err
+	}
+	if other.DeletionRequestedAt != nil {
+		return nil, repositories.ErrUserNotFound
+	}
+
+	// Users who signed up through a login provider have no sign until they complete their profile
+	compatibility, err := utils.CompareSigns(utils.ZodiacSign(user.ZodiacSign), utils.ZodiacSign(other.ZodiacSign))
+	if err != nil {
+		return nil, ErrZodiacSignNotSet
+	}
+
+	return compatibility, nil
+}
+
+// requestError maps a request that stopped being pending mid-transaction to ErrRequestNotPending
+func requestError(err error) error {
+	if err == repositories.ErrFriendRequestNotPending {
+		return ErrRequestNotPending
+	}
+	return err
+}
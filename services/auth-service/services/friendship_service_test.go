@@ -0,0 +1,365 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"zodiac-ai-backend/pkg/database/mongotest"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// friendshipTestEnv is a FriendshipService on a throwaway database
+type friendshipTestEnv struct {
+	db             *mongo.Database
+	userRepo       *repositories.UserRepository
+	friendshipRepo *repositories.FriendshipRepository
+	friendships    *FriendshipService
+	blocks         *BlockService
+}
+
+func newFriendshipTestEnv(t *testing.T) *friendshipTestEnv {
+	t.Helper()
+
+	db := mongotest.NewDatabase(t)
+
+	// Created by scripts/migrate.go in a real deployment; duplicate sends rely on it
+	_, err := db.Collection("friend_requests").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "sender_id", Value: 1}, {Key: "receiver_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := &friendshipTestEnv{
+		db:             db,
+		userRepo:       repositories.NewUserRepository(db),
+		friendshipRepo: repositories.NewFriendshipRepository(db),
+	}
+	blockRepo := repositories.NewBlockRepository(db)
+	suggestions := NewSuggestionCache(time.Minute)
+	env.friendships = NewFriendshipService(env.friendshipRepo, env.userRepo, blockRepo, suggestions)
+	env.blocks = NewBlockService(blockRepo, env.friendshipRepo, env.userRepo, suggestions)
+	return env
+}
+
+// createUsers creates n users with friendship documents and returns their IDs
+// The friendship documents exist up front so concurrent sends don't both create one
+func (e *friendshipTestEnv) createUsers(t *testing.T, n int) []string {
+	t.Helper()
+
+	ids := make([]string, n)
+	for i := range ids {
+		user := &models.User{
+			Email:       fmt.Sprintf("user%d@example.com", i),
+			FullName:    fmt.Sprintf("User %d", i),
+			DateOfBirth: time.Date(1990, 8, 1, 0, 0, 0, 0, time.UTC),
+			Gender:      "other",
+			ZodiacSign:  "Leo",
+		}
+		if err := e.userRepo.Create(context.Background(), user); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e.friendshipRepo.GetOrCreateFriendship(context.Background(), user.ID); err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = user.ID.Hex()
+	}
+	return ids
+}
+
+// requests returns the friend request documents between two users, either way
+func (e *friendshipTestEnv) requests(t *testing.T, a, b string) []models.FriendRequest {
+	t.Helper()
+
+	aID, _ := primitive.ObjectIDFromHex(a)
+	bID, _ := primitive.ObjectIDFromHex(b)
+	cursor, err := e.db.Collection("friend_requests").Find(context.Background(), bson.M{"$or": bson.A{
+		bson.M{"sender_id": aID, "receiver_id": bID},
+		bson.M{"sender_id": bID, "receiver_id": aID},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests []models.FriendRequest
+	if err := cursor.All(context.Background(), &requests); err != nil {
+		t.Fatal(err)
+	}
+	return requests
+}
+
+func (e *friendshipTestEnv) friendship(t *testing.T, userID string) *models.Friendship {
+	t.Helper()
+
+	id, _ := primitive.ObjectIDFromHex(userID)
+	friendship, err := e.friendshipRepo.GetOrCreateFriendship(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return friendship
+}
+
+func TestSendFriendRequestTwice(t *testing.T) {
+	env := newFriendshipTestEnv(t)
+	ctx := context.Background()
+	users := env.createUsers(t, 2)
+
+	first, err := env.friendships.SendFriendRequest(ctx, users[0], users[1])
+	if err != nil {
+		t.Fatalf("SendFriendRequest: %v", err)
+	}
+	again, err := env.friendships.SendFriendRequest(ctx, users[0], users[1])
+	if err != nil {
+		t.Fatalf("sending again: %v", err)
+	}
+	if again.ID != first.ID {
+		t.Fatalf("sending again returned request %s, want %s", again.ID.Hex(), first.ID.Hex())
+	}
+
+	if n := len(env.requests(t, users[0], users[1])); n != 1 {
+		t.Fatalf("%d request documents, want 1", n)
+	}
+	if sent := env.friendship(t, users[0]).PendingSent; len(sent) != 1 {
+		t.Fatalf("pending_sent = %v, want the receiver once", sent)
+	}
+}
+
+func TestSendFriendRequestConcurrently(t *testing.T) {
+	env := newFriendshipTestEnv(t)
+	users := env.createUsers(t, 2)
+
+	const senders = 5
+	requests := make([]*models.FriendRequest, senders)
+	errs := make([]error, senders)
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			requests[i], errs[i] = env.friendships.SendFriendRequest(context.Background(), users[0], users[1])
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+		if requests[i].ID != requests[0].ID {
+			t.Fatalf("send %d returned request %s, want %s", i, requests[i].ID.Hex(), requests[0].ID.Hex())
+		}
+	}
+
+	stored := env.requests(t, users[0], users[1])
+	if len(stored) != 1 {
+		t.Fatalf("%d request documents, want 1", len(stored))
+	}
+	if stored[0].Status != models.StatusPending {
+		t.Fatalf("status %s, want PENDING", stored[0].Status)
+	}
+	if sent := env.friendship(t, users[0]).PendingSent; len(sent) != 1 {
+		t.Fatalf("pending_sent = %v, want the receiver once", sent)
+	}
+	if received := env.friendship(t, users[1]).PendingReceived; len(received) != 1 {
+		t.Fatalf("pending_received = %v, want the sender once", received)
+	}
+}
+
+func TestSendFriendRequestReverseFound(t *testing.T) {
+	env := newFriendshipTestEnv(t)
+	ctx := context.Background()
+	users := env.createUsers(t, 2)
+
+	request, err := env.friendships.SendFriendRequest(ctx, users[0], users[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.friendships.SendFriendRequest(ctx, users[1], users[0]); err != ErrRequestReceived {
+		t.Fatalf("reverse send: err = %v, want ErrRequestReceived", err)
+	}
+	if n := len(env.requests(t, users[0], users[1])); n != 1 {
+		t.Fatalf("%d request documents, want 1", n)
+	}
+
+	// The receiver answers the request instead
+	if err := env.friendships.AcceptFriendRequest(ctx, request.ID.Hex(), users[1]); err != nil {
+		t.Fatalf("AcceptFriendRequest: %v", err)
+	}
+	if _, err := env.friendships.SendFriendRequest(ctx, users[1], users[0]); err != ErrAlreadyFriends {
+		t.Fatalf("send after accepting: err = %v, want ErrAlreadyFriends", err)
+	}
+}
+
+func TestSendFriendRequestCrossed(t *testing.T) {
+	env := newFriendshipTestEnv(t)
+	users := env.createUsers(t, 2)
+
+	// Both users send at once: one request wins, the other sender is told to answer it
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = env.friendships.SendFriendRequest(context.Background(), users[i], users[1-i])
+		}(i)
+	}
+	wg.Wait()
+
+	sent, received := 0, 0
+	for _, err := range errs {
+		switch err {
+		case nil:
+			sent++
+		case ErrRequestReceived:
+			received++
+		default:
+			t.Fatalf("crossed send: %v", err)
+		}
+	}
+	if sent != 1 || received != 1 {
+		t.Fatalf("%d sent and %d told of the other request, want 1 and 1", sent, received)
+	}
+
+	stored := env.requests(t, users[0], users[1])
+	if len(stored) != 1 || stored[0].Status != models.StatusPending {
+		t.Fatalf("request documents %+v, want one pending", stored)
+	}
+	for _, user := range users {
+		friendship := env.friendship(t, user)
+		if n := len(friendship.PendingSent) + len(friendship.PendingReceived); n != 1 {
+			t.Fatalf("user %s: pending_sent=%v pending_received=%v, want one entry", user, friendship.PendingSent, friendship.PendingReceived)
+		}
+	}
+}
+
+// befriend makes two users friends through a request and returns the request
+func (e *friendshipTestEnv) befriend(t *testing.T, senderID, receiverID string) *models.FriendRequest {
+	t.Helper()
+
+	request, err := e.friendships.SendFriendRequest(context.Background(), senderID, receiverID)
+	if err != nil {
+		t.Fatalf("SendFriendRequest: %v", err)
+	}
+	if err := e.friendships.AcceptFriendRequest(context.Background(), request.ID.Hex(), receiverID); err != nil {
+		t.Fatalf("AcceptFriendRequest: %v", err)
+	}
+	return request
+}
+
+// assertFriends checks that friend_ids and friends_count of each user list exactly the given friends
+func (e *friendshipTestEnv) assertFriends(t *testing.T, userID string, friendIDs ...string) {
+	t.Helper()
+
+	have := make(map[string]bool)
+	for _, id := range e.friendship(t, userID).FriendIDs {
+		have[id.Hex()] = true
+	}
+	for _, id := range friendIDs {
+		if !have[id] {
+			t.Fatalf("user %s: %s missing from friend_ids", userID, id)
+		}
+	}
+	if len(have) != len(friendIDs) {
+		t.Fatalf("user %s: %d friend_ids, want %d", userID, len(have), len(friendIDs))
+	}
+
+	id, _ := primitive.ObjectIDFromHex(userID)
+	user, err := e.userRepo.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.FriendsCount != len(friendIDs) {
+		t.Fatalf("user %s: friends_count %d, want %d", userID, user.FriendsCount, len(friendIDs))
+	}
+}
+
+// requestStatus returns the status of a friend request
+func (e *friendshipTestEnv) requestStatus(t *testing.T, requestID primitive.ObjectID) models.FriendshipStatus {
+	t.Helper()
+
+	request, err := e.friendshipRepo.FindRequestByID(context.Background(), requestID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return request.Status
+}
+
+func TestUnfriendKeepsCountsConsistent(t *testing.T) {
+	env := newFriendshipTestEnv(t)
+	ctx := context.Background()
+	users := env.createUsers(t, 3)
+
+	request := env.befriend(t, users[0], users[1])
+	env.befriend(t, users[2], users[0])
+	env.assertFriends(t, users[0], users[1], users[2])
+	env.assertFriends(t, users[1], users[0])
+
+	if err := env.friendships.Unfriend(ctx, users[1], users[0]); err != nil {
+		t.Fatalf("Unfriend: %v", err)
+	}
+	env.assertFriends(t, users[0], users[2])
+	env.assertFriends(t, users[1])
+	if status := env.requestStatus(t, request.ID); status != models.StatusUnfriended {
+		t.Fatalf("request status %s, want UNFRIENDED", status)
+	}
+
+	// Unfriending again changes nothing
+	if err := env.friendships.Unfriend(ctx, users[0], users[1]); err != ErrNotFriends {
+		t.Fatalf("unfriending again: err = %v, want ErrNotFriends", err)
+	}
+	env.assertFriends(t, users[0], users[2])
+	env.assertFriends(t, users[1])
+
+	// They can become friends again
+	env.befriend(t, users[1], users[0])
+	env.assertFriends(t, users[0], users[1], users[2])
+	env.assertFriends(t, users[1], users[0])
+}
+
+func TestBlockKeepsCountsConsistent(t *testing.T) {
+	env := newFriendshipTestEnv(t)
+	ctx := context.Background()
+	users := env.createUsers(t, 3)
+
+	accepted := env.befriend(t, users[0], users[1])
+	pending, err := env.friendships.SendFriendRequest(ctx, users[2], users[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocking a friend ends the friendship
+	if err := env.blocks.Block(ctx, users[0], users[1]); err != nil {
+		t.Fatalf("Block: %v", err)
+	}
+	env.assertFriends(t, users[0])
+	env.assertFriends(t, users[1])
+	if status := env.requestStatus(t, accepted.ID); status != models.StatusUnfriended {
+		t.Fatalf("accepted request status %s, want UNFRIENDED", status)
+	}
+
+	// Blocking a user with a pending request cancels it
+	if err := env.blocks.Block(ctx, users[0], users[2]); err != nil {
+		t.Fatalf("Block: %v", err)
+	}
+	if status := env.requestStatus(t, pending.ID); status != models.StatusCancelled {
+		t.Fatalf("pending request status %s, want CANCELLED", status)
+	}
+	for _, user := range []string{users[0], users[2]} {
+		friendship := env.friendship(t, user)
+		if len(friendship.PendingSent) != 0 || len(friendship.PendingReceived) != 0 {
+			t.Fatalf("user %s: pending_sent=%v pending_received=%v, want none", user, friendship.PendingSent, friendship.PendingReceived)
+		}
+	}
+	env.assertFriends(t, users[2])
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"zodiac-ai-backend/pkg/database/mongotest"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	guard := &LoginGuard{config: LockoutConfig{BaseLockout: time.Minute, MaxLockout: time.Hour}}
+
+	tests := []struct {
+		previousLockouts int
+		want             time.Duration
+	}{
+		{previousLockouts: 0, want: time.Minute},
+		{previousLockouts: 1, want: 2 * time.Minute},
+		{previousLockouts: 2, want: 4 * time.Minute},
+		{previousLockouts: 5, want: 32 * time.Minute},
+		{previousLockouts: 6, want: time.Hour}, // 64 minutes, capped
+		{previousLockouts: 50, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := guard.lockoutDuration(tt.previousLockouts); got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %s, want %s", tt.previousLockouts, got, tt.want)
+		}
+	}
+}
+
+func TestLockedErrorIsErrLoginLocked(t *testing.T) {
+	var err error = fmt.Errorf("login: %w", &LockedError{Until: time.Now().Add(time.Minute)})
+
+	if !errors.Is(err, ErrLoginLocked) {
+		t.Fatal("errors.Is(LockedError, ErrLoginLocked) = false")
+	}
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.Until.IsZero() {
+		t.Fatal("errors.As didn't find the LockedError")
+	}
+}
+
+// guardTestEnv is a LoginGuard on a throwaway database
+type guardTestEnv struct {
+	db    *mongo.Database
+	guard *LoginGuard
+}
+
+func newGuardTestEnv(t *testing.T, config LockoutConfig) *guardTestEnv {
+	t.Helper()
+
+	db := mongotest.NewDatabase(t)
+	return &guardTestEnv{
+		db:    db,
+		guard: NewLoginGuard(repositories.NewLoginAttemptRepository(db), repositories.NewAuditLogRepository(db), config),
+	}
+}
+
+func (e *guardTestEnv) fail(t *testing.T, email, ip string, times int) {
+	t.Helper()
+
+	for i := 0; i < times; i++ {
+		if err := e.guard.RecordFailure(context.Background(), email, ip, nil); err != nil {
+			t.Fatalf("RecordFailure: %v", err)
+		}
+	}
+}
+
+// lockedFor returns how long a login is locked out, or 0
+func (e *guardTestEnv) lockedFor(t *testing.T, email, ip string) time.Duration {
+	t.Helper()
+
+	err := e.guard.Check(context.Background(), email, ip)
+	if err == nil {
+		return 0
+	}
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("Check: %v", err)
+	}
+	return time.Until(locked.Until)
+}
+
+// expireLockout moves a key's lockout into the past, as if its time had run out
+func (e *guardTestEnv) expireLockout(t *testing.T, key string) {
+	t.Helper()
+
+	_, err := e.db.Collection("login_attempts").UpdateOne(context.Background(),
+		bson.M{"_id": key},
+		bson.M{"$set": bson.M{"locked_until": time.Now().Add(-time.Second)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertLockedAbout fails unless d is within a few seconds of want
+func assertLockedAbout(t *testing.T, d, want time.Duration) {
+	t.Helper()
+
+	if d < want-5*time.Second || d > want {
+		t.Fatalf("locked for %s, want about %s", d, want)
+	}
+}
+
+func TestAccountLockoutBacksOff(t *testing.T) {
+	env := newGuardTestEnv(t, LockoutConfig{MaxAttempts: 3, IPMaxAttempts: 100, BaseLockout: time.Minute, MaxLockout: time.Hour})
+	const email = "victim@example.com"
+
+	env.fail(t, email, "203.0.113.1", 2)
+	if d := env.lockedFor(t, email, "203.0.113.1"); d != 0 {
+		t.Fatalf("locked after 2 of 3 failures (%s)", d)
+	}
+
+	env.fail(t, email, "203.0.113.2", 1)
+	assertLockedAbout(t, env.lockedFor(t, email, "198.51.100.9"), time.Minute) // Any IP address
+	assertLockedAbout(t, env.lockedFor(t, " Victim@Example.com ", ""), time.Minute)
+
+	// The next lockout doubles
+	env.expireLockout(t, emailAttemptKey(email))
+	if d := env.lockedFor(t, email, ""); d != 0 {
+		t.Fatalf("still locked after the lockout ended (%s)", d)
+	}
+	env.fail(t, email, "203.0.113.1", 3)
+	assertLockedAbout(t, env.lockedFor(t, email, ""), 2*time.Minute)
+
+	env.expireLockout(t, emailAttemptKey(email))
+	env.fail(t, email, "203.0.113.1", 3)
+	assertLockedAbout(t, env.lockedFor(t, email, ""), 4*time.Minute)
+}
+
+func TestIPLockout(t *testing.T) {
+	env := newGuardTestEnv(t, LockoutConfig{MaxAttempts: 100, IPMaxAttempts: 3, BaseLockout: time.Minute, MaxLockout: time.Hour})
+	const ip = "203.0.113.66"
+
+	// Credential stuffing: one attempt per account
+	env.fail(t, "a@example.com", ip, 1)
+	env.fail(t, "b@example.com", ip, 1)
+	if d := env.lockedFor(t, "c@example.com", ip); d != 0 {
+		t.Fatalf("locked after 2 of 3 failures (%s)", d)
+	}
+	env.fail(t, "c@example.com", ip, 1)
+
+	assertLockedAbout(t, env.lockedFor(t, "d@example.com", ip), time.Minute)
+
+	// The accounts themselves aren't locked
+	if d := env.lockedFor(t, "a@example.com", "198.51.100.9"); d != 0 {
+		t.Fatalf("account locked by another address's failures (%s)", d)
+	}
+}
+
+func TestSuccessfulLoginResetsAccountFailures(t *testing.T) {
+	env := newGuardTestEnv(t, LockoutConfig{MaxAttempts: 3, IPMaxAttempts: 5, BaseLockout: time.Minute, MaxLockout: time.Hour})
+	const email, ip = "user@example.com", "203.0.113.5"
+
+	env.fail(t, email, ip, 2)
+	if err := env.guard.RecordSuccess(context.Background(), email); err != nil {
+		t.Fatal(err)
+	}
+
+	env.fail(t, email, ip, 2)
+	if d := env.lockedFor(t, email, "198.51.100.9"); d != 0 {
+		t.Fatalf("account locked after a success reset its count (%s)", d)
+	}
+
+	// The address keeps its count: 5 failures from it in total
+	env.fail(t, "other@example.com", ip, 1)
+	assertLockedAbout(t, env.lockedFor(t, "new@example.com", ip), time.Minute)
+}
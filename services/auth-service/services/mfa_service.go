@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"zodiac-ai-backend/pkg/totp"
+	"zodiac-ai-backend/pkg/utils"
+	"zodiac-ai-backend/pkg/validator"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor enrollment has not been started")
+	ErrInvalidMFACode    = errors.New("invalid two-factor code")
+	ErrInvalidMFAToken   = errors.New("invalid or expired MFA token")
+)
+
+const (
+	// mfaSkew accepts codes one time step (30s) either side of the server clock
+	mfaSkew = 1
+
+	recoveryCodeCount = 10
+)
+
+// MFAService handles TOTP two-factor enrollment
+// The login step itself is AuthService.VerifyMFA
+type MFAService struct {
+	userRepo   *repositories.UserRepository
+	loginGuard *LoginGuard
+	issuer     string // Shown in authenticator apps
+}
+
+// NewMFAService creates a new MFA service
+func NewMFAService(userRepo *repositories.UserRepository, loginGuard *LoginGuard, issuer string) *MFAService {
+	return &MFAService{
+		userRepo:   userRepo,
+		loginGuard: loginGuard,
+		issuer:     issuer,
+	}
+}
+
+// Enroll starts two-factor enrollment with a new secret
+// The secret only takes effect once Confirm receives a valid code; enrolling again replaces it
+func (s *MFAService) Enroll(ctx context.Context, userID string) (*models.MFAEnrollment, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.Update(ctx, user.ID, bson.M{"mfa_pending_secret": secret}); err != nil {
+		return nil, err
+	}
+
+	return &models.MFAEnrollment{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication with the first code from the authenticator app
+// Returns the recovery codes; only their hashes are stored, so they are shown this once
+func (s *MFAService) Confirm(ctx context.Context, userID string, req *models.MFAConfirmRequest) (*models.MFARecoveryCodes, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFAPendingSecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+
+	step, ok := totp.Verify(user.MFAPendingSecret, req.Code, time.Now(), mfaSkew)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.Update(ctx, user.ID, bson.M{
+		"mfa_enabled":        true,
+		"mfa_secret":         user.MFAPendingSecret,
+		"mfa_pending_secret": "",
+		"mfa_recovery_codes": hashes,
+		"mfa_last_step":      step,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &models.MFARecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// Disable turns two-factor authentication off
+// Needs the password and a current code; failures count towards the login lockout.
+// Accounts created through a provider may have no password: the code alone confirms it.
+func (s *MFAService) Disable(ctx context.Context, userID, ip string, req *models.MFADisableRequest) error {
+	if err := validator.Validate(req); err != nil {
+		return err
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+
+	if err := s.loginGuard.Check(ctx, user.Email, ip); err != nil {
+		return err
+	}
+
+	if user.Password != "" && !utils.CheckPassword(req.Password, user.Password) {
+		if err := s.loginGuard.RecordFailure(ctx, user.Email, ip, &user.ID); err != nil {
+			return err
+		}
+		return ErrInvalidCredentials
+	}
+
+	ok, err := checkSecondFactor(ctx, s.userRepo, user, req.Code, req.RecoveryCode)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err := s.loginGuard.RecordFailure(ctx, user.Email, ip, &user.ID); err != nil {
+			return err
+		}
+		return ErrInvalidMFACode
+	}
+
+	return s.userRepo.ClearMFA(ctx, user.ID)
+}
+
+// findUser loads a user by hex ID
+func (s *MFAService) findUser(ctx context.Context, userID string) (*models.User, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepo.FindByID(ctx, id)
+}
+
+// checkSecondFactor checks a TOTP code or, if no code is given, a recovery code
+// Accepted codes are claimed atomically: a TOTP code can't be replayed and a recovery code works once
+func checkSecondFactor(ctx context.Context, userRepo *repositories.UserRepository, user *models.User, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		step, ok := totp.Verify(user.MFASecret, code, time.Now(), mfaSkew)
+		if !ok {
+			return false, nil
+		}
+		return userRepo.ClaimMFAStep(ctx, user.ID, step)
+	}
+
+	if recoveryCode == "" {
+		return false, nil
+	}
+	return userRepo.ConsumeRecoveryCode(ctx, user.ID, repositories.HashToken(normalizeRecoveryCode(recoveryCode)))
+}
+
+// recoveryCodeEncoding spells recovery codes in lowercase base32 (no 0/1/8/9 to misread)
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// generateRecoveryCodes returns new recovery codes ("xxxxx-xxxxx", 50 bits each) and their hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	buf := make([]byte, 7)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := recoveryCodeEncoding.EncodeToString(buf)[:10]
+
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = repositories.HashToken(raw)
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode accepts codes typed with or without the dash, in any case
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"zodiac-ai-backend/pkg/database/mongotest"
+	"zodiac-ai-backend/pkg/totp"
+	"zodiac-ai-backend/pkg/utils"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+)
+
+// mfaTestEnv is an MFAService on a throwaway database
+type mfaTestEnv struct {
+	userRepo *repositories.UserRepository
+	mfa      *MFAService
+}
+
+func newMFATestEnv(t *testing.T) *mfaTestEnv {
+	t.Helper()
+
+	db := mongotest.NewDatabase(t)
+	userRepo := repositories.NewUserRepository(db)
+	guard := NewLoginGuard(repositories.NewLoginAttemptRepository(db), repositories.NewAuditLogRepository(db), LockoutConfig{})
+	return &mfaTestEnv{userRepo: userRepo, mfa: NewMFAService(userRepo, guard, "Zodiac AI")}
+}
+
+// createUser creates a user with two-factor authentication enabled and returns the user,
+// the TOTP secret and the recovery codes. An empty password creates a social login account.
+func (e *mfaTestEnv) createUser(t *testing.T, email, password string) (*models.User, string, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &models.User{Email: email, FullName: "Test User"}
+	if password != "" {
+		hashed, err := utils.HashPassword(password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		user.Password = hashed
+	}
+	if err := e.userRepo.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	enrollment, err := e.mfa.Enroll(ctx, user.ID.Hex())
+	if err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+	code, err := totp.Code(enrollment.Secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovery, err := e.mfa.Confirm(ctx, user.ID.Hex(), &models.MFAConfirmRequest{Code: code})
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	return user, enrollment.Secret, recovery.RecoveryCodes
+}
+
+// nextCode returns the code of the next time step: the current one was claimed by Confirm
+func nextCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	code, err := totp.Code(secret, time.Now().Add(30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func (e *mfaTestEnv) mfaEnabled(t *testing.T, user *models.User) bool {
+	t.Helper()
+
+	stored, err := e.userRepo.FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stored.MFAEnabled
+}
+
+func TestDisableMFAWithoutPassword(t *testing.T) {
+	env := newMFATestEnv(t)
+	ctx := context.Background()
+	user, secret, _ := env.createUser(t, "social@example.com", "")
+
+	if err := env.mfa.Disable(ctx, user.ID.Hex(), "203.0.113.7", &models.MFADisableRequest{Code: "000000"}); err != ErrInvalidMFACode {
+		t.Fatalf("wrong code: err = %v, want ErrInvalidMFACode", err)
+	}
+	if !env.mfaEnabled(t, user) {
+		t.Fatal("two-factor authentication disabled with a wrong code")
+	}
+
+	if err := env.mfa.Disable(ctx, user.ID.Hex(), "203.0.113.7", &models.MFADisableRequest{Code: nextCode(t, secret)}); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+	if env.mfaEnabled(t, user) {
+		t.Fatal("two-factor authentication still enabled")
+	}
+}
+
+func TestDisableMFAWithoutPasswordRecoveryCode(t *testing.T) {
+	env := newMFATestEnv(t)
+	user, _, recoveryCodes := env.createUser(t, "social@example.com", "")
+
+	if err := env.mfa.Disable(context.Background(), user.ID.Hex(), "203.0.113.7", &models.MFADisableRequest{RecoveryCode: recoveryCodes[0]}); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+	if env.mfaEnabled(t, user) {
+		t.Fatal("two-factor authentication still enabled")
+	}
+}
+
+func TestDisableMFARequiresPassword(t *testing.T) {
+	env := newMFATestEnv(t)
+	ctx := context.Background()
+	user, secret, _ := env.createUser(t, "password@example.com", "correct horse battery")
+
+	for _, password := range []string{"", "wrong password"} {
+		req := &models.MFADisableRequest{Password: password, Code: nextCode(t, secret)}
+		if err := env.mfa.Disable(ctx, user.ID.Hex(), "203.0.113.7", req); err != ErrInvalidCredentials {
+			t.Fatalf("password %q: err = %v, want ErrInvalidCredentials", password, err)
+		}
+	}
+	if !env.mfaEnabled(t, user) {
+		t.Fatal("two-factor authentication disabled without the password")
+	}
+
+	req := &models.MFADisableRequest{Password: "correct horse battery", Code: nextCode(t, secret)}
+	if err := env.mfa.Disable(ctx, user.ID.Hex(), "203.0.113.7", req); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+	if env.mfaEnabled(t, user) {
+		t.Fatal("two-factor authentication still enabled")
+	}
+}
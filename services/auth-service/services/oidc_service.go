@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"zodiac-ai-backend/pkg/oidc"
+	"zodiac-ai-backend/pkg/validator"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrUnknownProvider       = errors.New("unknown login provider")
+	ErrInvalidOIDCState      = errors.New("invalid or expired login state")
+	ErrOIDCLoginFailed       = errors.New("provider login failed")
+	ErrOIDCEmailRequired     = errors.New("provider did not share an email address")
+	ErrOIDCEmailConflict     = errors.New("an account with this email already exists")
+	ErrIdentityLinked        = errors.New("provider account is linked to another user")
+	ErrProviderAlreadyLinked = errors.New("a different account at this provider is already linked")
+)
+
+// oidcStateExpiry is how long the user has to sign in at the provider
+const oidcStateExpiry = 10 * time.Minute
+
+// OIDCService handles login and account linking through OpenID Connect providers
+// (authorization code flow with PKCE; ID tokens are verified against the provider's JWKS)
+type OIDCService struct {
+	providers   map[string]*oidc.Provider
+	stateRepo   *repositories.OIDCStateRepository
+	userRepo    *repositories.UserRepository
+	authService *AuthService
+}
+
+// NewOIDCService creates a new OIDC service
+func NewOIDCService(
+	providers []*oidc.Provider,
+	stateRepo *repositories.OIDCStateRepository,
+	userRepo *repositories.UserRepository,
+	authService *AuthService,
+) *OIDCService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &OIDCService{
+		providers:   byName,
+		stateRepo:   stateRepo,
+		userRepo:    userRepo,
+		authService: authService,
+	}
+}
+
+// Providers returns the names of the configured providers
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Authorize starts a provider login and returns the URL to send the user to
+// linkUserID is set when a logged in user links the provider to their account
+func (s *OIDCService) Authorize(ctx context.Context, providerName string, linkUserID *primitive.ObjectID) (*models.OIDCAuthorizeResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.GenerateVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.ChallengeS256(verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.stateRepo.Create(ctx, &models.OIDCState{
+		Provider:  providerName,
+		Verifier:  verifier,
+		Nonce:     nonce,
+		UserID:    linkUserID,
+		ExpiresAt: time.Now().Add(oidcStateExpiry),
+	}, state); err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCAuthorizeResponse{
+		AuthorizationURL: authURL,
+		State:            state,
+	}, nil
+}
+
+// Callback completes a provider login
+// The provider account's user is logged in; an unknown account is linked to the user with the
+// same (provider-verified) email, or becomes a new user whose profile still has to be completed.
+func (s *OIDCService) Callback(ctx context.Context, providerName string, req *models.OIDCCallbackRequest, client *models.ClientInfo) (*models.AuthResponse, error) {
+	idToken, oidcState, err := s.exchange(ctx, providerName, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Link states can only be completed through Link, by the user who started them
+	if oidcState.UserID != nil {
+		return nil, ErrInvalidOIDCState
+	}
+
+	user, err := s.findOrCreateUser(ctx, providerName, idToken, appleName(req.User))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authService.loginUser(ctx, user, client)
+}
+
+// Link adds a provider account to a logged in user
+func (s *OIDCService) Link(ctx context.Context, userID, providerName string, req *models.OIDCCallbackRequest) (*models.User, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, oidcState, err := s.exchange(ctx, providerName, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The state must have been started by this user, or anyone could link their
+	// provider account to a victim's session by sending them a callback link
+	if oidcState.UserID == nil || *oidcState.UserID != id {
+		return nil, ErrInvalidOIDCState
+	}
+
+	existing, err := s.userRepo.FindByIdentity(ctx, providerName, idToken.Subject)
+	if err == nil {
+		if existing.ID != id {
+			return nil, ErrIdentityLinked
+		}
+		return s.authService.GetProfile(ctx, userID)
+	}
+	if err != repositories.ErrUserNotFound {
+		return nil, err
+	}
+
+	if err := s.addIdentity(ctx, id, providerName, idToken); err != nil {
+		return nil, err
+	}
+
+	return s.authService.GetProfile(ctx, userID)
+}
+
+// exchange validates the callback, redeems its state and verifies the provider's ID token
+func (s *OIDCService) exchange(ctx context.Context, providerName string, req *models.OIDCCallbackRequest) (*oidc.IDToken, *models.OIDCState, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, nil, err
+	}
+
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, nil, ErrUnknownProvider
+	}
+
+	oidcState, err := s.stateRepo.Consume(ctx, providerName, req.State)
+	if err != nil {
+		if err == repositories.ErrOIDCStateInvalid {
+			return nil, nil, ErrInvalidOIDCState
+		}
+		return nil, nil, err
+	}
+
+	tokens, err := provider.Exchange(ctx, req.Code, oidcState.Verifier)
+	if err != nil {
+		log.Printf("⚠️ %s login: %v", providerName, err)
+		return nil, nil, ErrOIDCLoginFailed
+	}
+
+	idToken, err := provider.VerifyIDToken(ctx, tokens.IDToken, oidcState.Nonce)
+	if err != nil {
+		log.Printf("⚠️ %s login: %v", providerName, err)
+		return nil, nil, ErrOIDCLoginFailed
+	}
+
+	return idToken, oidcState, nil
+}
+
+// findOrCreateUser returns the user of a provider account, linking or creating one if needed
+func (s *OIDCService) findOrCreateUser(ctx context.Context, providerName string, idToken *oidc.IDToken, fallbackName string) (*models.User, error) {
+	user, err := s.userRepo.FindByIdentity(ctx, providerName, idToken.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if err != repositories.ErrUserNotFound {
+		return nil, err
+	}
+
+	if idToken.Email == "" {
+		return nil, ErrOIDCEmailRequired
+	}
+
+	user, err = s.userRepo.FindByEmail(ctx, idToken.Email)
+	if err == nil {
+		// Only link by email when both sides proved ownership of it: otherwise whoever controls
+		// one of the two accounts could take over the other
+		if !idToken.EmailVerified || !user.EmailVerified {
+			return nil, ErrOIDCEmailConflict
+		}
+		if err := s.addIdentity(ctx, user.ID, providerName, idToken); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if err != repositories.ErrUserNotFound {
+		return nil, err
+	}
+
+	return s.createUser(ctx, providerName, idToken, fallbackName)
+}
+
+// createUser signs up a provider account
+// There is no password (it can be set with a password reset) and no date of birth yet:
+// the profile is marked incomplete until CompleteProfile calculates the zodiac sign
+func (s *OIDCService) createUser(ctx context.Context, providerName string, idToken *oidc.IDToken, fallbackName string) (*models.User, error) {
+	name := idToken.Name
+	if name == "" {
+		name = fallbackName
+	}
+	if name == "" {
+		name, _, _ = strings.Cut(idToken.Email, "@")
+	}
+
+	now := time.Now()
+	user := &models.User{
+		Email:             idToken.Email,
+		FullName:          name,
+		DisplayName:       name,
+		AvatarURL:         idToken.Picture,
+		EmailVerified:     idToken.EmailVerified,
+		ProfileIncomplete: true,
+		Identities: []models.Identity{{
+			Provider: providerName,
+			Subject:  idToken.Subject,
+			Email:    idToken.Email,
+			LinkedAt: now,
+		}},
+	}
+	if idToken.EmailVerified {
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		if err == repositories.ErrUserAlreadyExists {
+			// Same email registered concurrently, or the identity was linked meanwhile
+			return nil, ErrOIDCEmailConflict
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// addIdentity links a provider account to a user
+func (s *OIDCService) addIdentity(ctx context.Context, userID primitive.ObjectID, providerName string, idToken *oidc.IDToken) error {
+	err := s.userRepo.AddIdentity(ctx, userID, models.Identity{
+		Provider: providerName,
+		Subject:  idToken.Subject,
+		Email:    idToken.Email,
+		LinkedAt: time.Now(),
+	})
+	switch err {
+	case repositories.ErrIdentityAlreadyLinked:
+		return ErrIdentityLinked
+	case repositories.ErrProviderAlreadyLinked:
+		return ErrProviderAlreadyLinked
+	}
+	return err
+}
+
+// appleName reads the name from Apple's "user" callback parameter
+// Apple leaves the name out of the ID token and only sends it on the first login
+func appleName(raw string) string {
+	if raw == "" {
+		return ""
+	}
+
+	var user struct {
+		Name struct {
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		} `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(raw), &user); err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(user.Name.FirstName + " " + user.Name.LastName)
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"zodiac-ai-backend/pkg/utils"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PrivacyService handles personal data exports and account deletion
+type PrivacyService struct {
+	userRepo         *repositories.UserRepository
+	refreshTokenRepo *repositories.RefreshTokenRepository
+	dataRepo         *repositories.UserDataRepository
+	deletionRepo     *repositories.AccountDeletionRepository
+	loginGuard       *LoginGuard
+	deletionWorker   *DeletionWorker
+}
+
+// NewPrivacyService creates a new privacy service
+func NewPrivacyService(
+	userRepo *repositories.UserRepository,
+	refreshTokenRepo *repositories.RefreshTokenRepository,
+	dataRepo *repositories.UserDataRepository,
+	deletionRepo *repositories.AccountDeletionRepository,
+	loginGuard *LoginGuard,
+	deletionWorker *DeletionWorker,
+) *PrivacyService {
+	return &PrivacyService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		dataRepo:         dataRepo,
+		deletionRepo:     deletionRepo,
+		loginGuard:       loginGuard,
+		deletionWorker:   deletionWorker,
+	}
+}
+
+// Export collects everything tied to a user, one section per collection
+func (s *PrivacyService) Export(ctx context.Context, userID string) ([]repositories.ExportSection, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dataRepo.Export(ctx, id)
+}
+
+// WriteExportJSON writes an export as one JSON object keyed by section
+func WriteExportJSON(w io.Writer, sections []repositories.ExportSection) error {
+	export := make(map[string][]bson.M, len(sections))
+	for _, section := range sections {
+		export[section.Name] = section.Documents
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+// WriteExportZip writes an export as a ZIP archive with one JSON file per section
+func WriteExportZip(w io.Writer, sections []repositories.ExportSection) error {
+	archive := zip.NewWriter(w)
+
+	for _, section := range sections {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     section.Name + ".json",
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(section.Documents); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// RequestDeletion deletes an account
+// The user is logged out and can't log in again right away; their data is removed by the
+// deletion worker in the background. Requesting again returns the existing job.
+func (s *PrivacyService) RequestDeletion(ctx context.Context, userID, ip string, req *models.DeleteAccountRequest) (*models.AccountDeletion, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletionRequestedAt == nil {
+		// Accounts created through a provider may have no password
+		if user.Password != "" {
+			if err := s.loginGuard.Check(ctx, user.Email, ip); err != nil {
+				return nil, err
+			}
+			if !utils.CheckPassword(req.Password, user.Password) {
+				if err := s.loginGuard.RecordFailure(ctx, user.Email, ip, &user.ID); err != nil {
+					return nil, err
+				}
+				return nil, ErrInvalidCredentials
+			}
+		}
+
+		if err := s.userRepo.Update(ctx, id, bson.M{"deletion_requested_at": time.Now()}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.refreshTokenRepo.RevokeAllByUserID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.deletionRepo.Create(ctx, &models.AccountDeletion{
+		UserID: id,
+		Email:  user.Email,
+	}); err != nil {
+		return nil, err
+	}
+	s.deletionWorker.Notify()
+
+	return s.deletionRepo.FindByUserID(ctx, id)
+}
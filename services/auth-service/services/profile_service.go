@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+
+	"zodiac-ai-backend/pkg/validator"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProfileService handles how users see each other: profiles, search, batch lookup and privacy
+type ProfileService struct {
+	userRepo       *repositories.UserRepository
+	friendshipRepo *repositories.FriendshipRepository
+}
+
+// NewProfileService creates a new profile service
+func NewProfileService(
+	userRepo *repositories.UserRepository,
+	friendshipRepo *repositories.FriendshipRepository,
+) *ProfileService {
+	return &ProfileService{
+		userRepo:       userRepo,
+		friendshipRepo: friendshipRepo,
+	}
+}
+
+// GetProfile gets a user's profile as the viewer sees it
+func (s *ProfileService) GetProfile(ctx context.Context, viewerID, userID string) (*models.PublicProfile, error) {
+	viewerObjID, err := primitive.ObjectIDFromHex(viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, repositories.ErrUserNotFound
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userObjID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionRequestedAt != nil {
+		return nil, repositories.ErrUserNotFound
+	}
+
+	// Users always see their own profile in full
+	if viewerObjID == userObjID {
+		self := *user
+		self.Privacy = models.PrivacySettings{}
+		return self.PublicProfile(true), nil
+	}
+
+	isFriend := false
+	if user.Privacy.Visibility() == models.VisibilityFriends {
+		isFriend, err = s.friendshipRepo.CheckFriendship(ctx, viewerObjID, userObjID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return user.PublicProfile(isFriend), nil
+}
+
+// Search searches users by display name prefix and zodiac sign
+func (s *ProfileService) Search(ctx context.Context, query *models.UserSearchQuery) ([]*models.UserSummary, string, error) {
+	if query.Limit <= 0 || query.Limit > 50 {
+		query.Limit = 20
+	}
+
+	users, nextCursor, err := s.userRepo.SearchProfiles(ctx, query.Query, query.ZodiacSign, query.Cursor, query.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return summaries(users), nextCursor, nil
+}
+
+// Batch gets the summaries of several users, in the order requested
+// Unknown IDs and accounts being deleted are skipped
+func (s *ProfileService) Batch(ctx context.Context, req *models.BatchUsersRequest) ([]*models.UserSummary, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			ids = append(ids, objID)
+		}
+	}
+
+	return resolveSummaries(ctx, s.userRepo, ids)
+}
+
+// UpdatePrivacy changes the user's privacy settings
+func (s *ProfileService) UpdatePrivacy(ctx context.Context, userID string, req *models.UpdatePrivacyRequest) (*models.PrivacySettings, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{}
+	if req.ProfileVisibility != nil {
+		update["privacy.profile_visibility"] = *req.ProfileVisibility
+	}
+	if req.HideDateOfBirth != nil {
+		update["privacy.hide_date_of_birth"] = *req.HideDateOfBirth
+	}
+
+	if err := s.userRepo.Update(ctx, id, update); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	privacy := user.Privacy
+	privacy.ProfileVisibility = privacy.Visibility()
+	return &privacy, nil
+}
+
+// resolveSummaries gets the summaries of users, keeping the order of ids
+// Unknown users and accounts being deleted are skipped
+func resolveSummaries(ctx context.Context, userRepo *repositories.UserRepository, ids []primitive.ObjectID) ([]*models.UserSummary, error) {
+	users, err := userRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]*models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	ordered := make([]*models.User, 0, len(ids))
+	seen := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		user, ok := byID[id]
+		if !ok || seen[id] || user.DeletionRequestedAt != nil {
+			continue
+		}
+		seen[id] = true
+		ordered = append(ordered, user)
+	}
+
+	return summaries(ordered), nil
+}
+
+// summaries maps users to their summaries
+func summaries(users []*models.User) []*models.UserSummary {
+	result := make([]*models.UserSummary, 0, len(users))
+	for _, user := range users {
+		result = append(result, user.Summary())
+	}
+	return result
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"zodiac-ai-backend/pkg/utils"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// suggestionPoolSize is how many suggestions are ranked and cached per user
+	suggestionPoolSize = 50
+
+	// mutualFriendsHalfScore is the number of mutual friends that scores 0.5;
+	// the score approaches 1 as mutual friends grow
+	mutualFriendsHalfScore = 3
+
+	// compatibleSignScore is the lowest compatibility of the signs suggested to users
+	// without enough friends of friends: the same sign, sextiles and trines
+	compatibleSignScore = 0.8
+)
+
+// SuggestionWeights weigh the scores friend suggestions are ranked by
+type SuggestionWeights struct {
+	MutualFriends float64
+	Zodiac        float64
+}
+
+// score blends the mutual friends score (0 to 1) with the zodiac compatibility (0 to 1)
+func (w SuggestionWeights) score(mutualFriends int, compatibility float64) float64 {
+	mutualScore := float64(mutualFriends) / float64(mutualFriends+mutualFriendsHalfScore)
+	return w.MutualFriends*mutualScore + w.Zodiac*compatibility
+}
+
+// SuggestionCache caches each user's friend suggestions
+// Friendship and block changes invalidate both users involved; other users (e.g. a friend of
+// a friend who gains a mutual friend) see the change once their entry expires.
+type SuggestionCache struct {
+	ttl        time.Duration
+	entries    map[primitive.ObjectID]suggestionEntry
+	generation uint64 // Bumped by every invalidation
+	mu         sync.RWMutex
+}
+
+type suggestionEntry struct {
+	suggestions []*models.FriendSuggestion
+	expiresAt   time.Time
+}
+
+// NewSuggestionCache creates a new suggestion cache
+func NewSuggestionCache(ttl time.Duration) *SuggestionCache {
+	sc := &SuggestionCache{
+		ttl:     ttl,
+		entries: make(map[primitive.ObjectID]suggestionEntry),
+	}
+
+	// Cleanup goroutine to prevent memory leaks
+	go sc.cleanup()
+
+	return sc
+}
+
+// Invalidate drops the cached suggestions of users
+func (sc *SuggestionCache) Invalidate(userIDs ...primitive.ObjectID) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.generation++
+	for _, userID := range userIDs {
+		delete(sc.entries, userID)
+	}
+}
+
+// get returns a user's cached suggestions, or the current generation to store fresh ones with
+func (sc *SuggestionCache) get(userID primitive.ObjectID) ([]*models.FriendSuggestion, uint64, bool) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	entry, ok := sc.entries[userID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, sc.generation, false
+	}
+	return entry.suggestions, sc.generation, true
+}
+
+// set caches a user's suggestions, unless an invalidation happened since generation was read
+func (sc *SuggestionCache) set(userID primitive.ObjectID, generation uint64, suggestions []*models.FriendSuggestion) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if generation != sc.generation {
+		return
+	}
+	sc.entries[userID] = suggestionEntry{suggestions: suggestions, expiresAt: time.Now().Add(sc.ttl)}
+}
+
+// cleanup removes expired entries to prevent memory leaks
+func (sc *SuggestionCache) cleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sc.mu.Lock()
+		now := time.Now()
+		for userID, entry := range sc.entries {
+			if now.After(entry.expiresAt) {
+				delete(sc.entries, userID)
+			}
+		}
+		sc.mu.Unlock()
+	}
+}
+
+// SuggestionService suggests friends
+// Candidates are friends of friends, ranked by mutual friends blended with zodiac compatibility;
+// users with few friends of friends also get users of compatible signs.
+type SuggestionService struct {
+	friendshipRepo *repositories.FriendshipRepository
+	userRepo       *repositories.UserRepository
+	blockRepo      *repositories.BlockRepository
+	cache          *SuggestionCache
+	weights        SuggestionWeights
+}
+
+// NewSuggestionService creates a new suggestion service
+func NewSuggestionService(
+	friendshipRepo *repositories.FriendshipRepository,
+	userRepo *repositories.UserRepository,
+	blockRepo *repositories.BlockRepository,
+	cache *SuggestionCache,
+	weights SuggestionWeights,
+) *SuggestionService {
+	return &SuggestionService{
+		friendshipRepo: friendshipRepo,
+		userRepo:       userRepo,
+		blockRepo:      blockRepo,
+		cache:          cache,
+		weights:        weights,
+	}
+}
+
+// GetSuggestions suggests users to befriend, best first
+// Friends, users with a pending request either way, blocked and suspended users and private profiles are left out
+func (s *SuggestionService) GetSuggestions(ctx context.Context, userID string, query *models.FriendSuggestionQuery) ([]*models.FriendSuggestion, error) {
+	if query.Limit <= 0 || query.Limit > suggestionPoolSize {
+		query.Limit = 20
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions, generation, ok := s.cache.get(userObjID)
+	if !ok {
+		suggestions, err = s.rank(ctx, userObjID)
+		if err != nil {
+			return nil, err
+		}
+		s.cache.set(userObjID, generation, suggestions)
+	}
+
+	if len(suggestions) > query.Limit {
+		suggestions = suggestions[:query.Limit]
+	}
+	return suggestions, nil
+}
+
+// rank builds a user's suggestions
+func (s *SuggestionService) rank(ctx context.Context, userID primitive.ObjectID) ([]*models.FriendSuggestion, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	friendship, err := s.friendshipRepo.GetOrCreateFriendship(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden, err := s.blockRepo.HiddenFrom(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude := []primitive.ObjectID{userID}
+	exclude = append(exclude, friendship.FriendIDs...)
+	exclude = append(exclude, friendship.PendingSent...)
+	exclude = append(exclude, friendship.PendingReceived...)
+	exclude = append(exclude, hidden...)
+
+	// Fetch extra candidates: private profiles, suspended users and accounts being deleted drop out below
+	counts, err := s.friendshipRepo.FindMutualFriendCounts(ctx, friendship.FriendIDs, exclude, 2*suggestionPoolSize)
+	if err != nil {
+		return nil, err
+	}
+
+	mutual := make(map[primitive.ObjectID]int, len(counts))
+	candidateIDs := make([]primitive.ObjectID, 0, len(counts))
+	for _, count := range counts {
+		mutual[count.UserID] = count.MutualFriends
+		candidateIDs = append(candidateIDs, count.UserID)
+	}
+
+	users, err := s.userRepo.FindByIDs(ctx, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*models.User, 0, len(users))
+	for _, candidate := range users {
+		if candidate.Privacy.Visibility() != models.VisibilityPrivate && candidate.DeletionRequestedAt == nil && !candidate.IsSuspended() {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	// Not enough friends of friends: fill up with users of compatible signs
+	if len(candidates) < suggestionPoolSize {
+		more, err := s.userRepo.FindByZodiacSigns(ctx, compatibleSigns(user.ZodiacSign),
+			append(exclude, candidateIDs...), suggestionPoolSize-len(candidates))
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, more...)
+	}
+
+	suggestions := make([]*models.FriendSuggestion, 0, len(candidates))
+	for _, candidate := range candidates {
+		mutualFriends := mutual[candidate.ID]
+		compatibility := utils.CompatibilityScore(utils.ZodiacSign(user.ZodiacSign), utils.ZodiacSign(candidate.ZodiacSign))
+
+		suggestions = append(suggestions, &models.FriendSuggestion{
+			User:          candidate.Summary(),
+			MutualFriends: mutualFriends,
+			Compatibility: compatibility,
+			Score:         s.weights.score(mutualFriends, compatibility),
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	if len(suggestions) > suggestionPoolSize {
+		suggestions = suggestions[:suggestionPoolSize]
+	}
+
+	return suggestions, nil
+}
+
+// compatibleSigns lists the signs that get along with sign (all signs if sign is unknown)
+func compatibleSigns(sign string) []string {
+	if sign == "" {
+		return nil
+	}
+
+	var signs []string
+	for _, other := range utils.Signs {
+		if utils.CompatibilityScore(utils.ZodiacSign(sign), other) >= compatibleSignScore {
+			signs = append(signs, string(other))
+		}
+	}
+	return signs
+}
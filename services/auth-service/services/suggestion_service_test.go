@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"zodiac-ai-backend/pkg/database/mongotest"
+	"zodiac-ai-backend/pkg/utils"
+	"zodiac-ai-backend/services/auth-service/models"
+	"zodiac-ai-backend/services/auth-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testWeights = SuggestionWeights{MutualFriends: 0.7, Zodiac: 0.3}
+
+func TestSuggestionScore(t *testing.T) {
+	tests := []struct {
+		mutualFriends int
+		compatibility float64
+		want          float64
+	}{
+		{mutualFriends: 0, compatibility: 0.95, want: 0.285},      // Zodiac only
+		{mutualFriends: 3, compatibility: 0, want: 0.35},          // Half the mutual friends score
+		{mutualFriends: 1, compatibility: 0.95, want: 0.46},       // 0.7 × 1/4 + 0.3 × 0.95
+		{mutualFriends: 2, compatibility: 0.55, want: 0.445},      // 0.7 × 2/5 + 0.3 × 0.55
+		{mutualFriends: 1000, compatibility: 1, want: 0.99790628}, // Approaches 1
+	}
+
+	for _, tt := range tests {
+		if got := testWeights.score(tt.mutualFriends, tt.compatibility); math.Abs(got-tt.want) > 1e-6 {
+			t.Fatalf("score(%d, %.2f) = %f, want %f", tt.mutualFriends, tt.compatibility, got, tt.want)
+		}
+	}
+
+	// More mutual friends always rank higher at equal compatibility
+	for n := 0; n < 20; n++ {
+		if testWeights.score(n+1, 0.5) <= testWeights.score(n, 0.5) {
+			t.Fatalf("score with %d mutual friends isn't above %d", n+1, n)
+		}
+	}
+}
+
+func TestCompatibleSigns(t *testing.T) {
+	got := compatibleSigns(string(utils.Leo))
+	sort.Strings(got)
+
+	// Same sign, sextiles and trines
+	want := []string{"Aries", "Gemini", "Leo", "Libra", "Sagittarius"}
+	if len(got) != len(want) {
+		t.Fatalf("compatibleSigns(Leo) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("compatibleSigns(Leo) = %v, want %v", got, want)
+		}
+	}
+
+	for _, sign := range utils.Signs {
+		for _, other := range compatibleSigns(string(sign)) {
+			if score := utils.CompatibilityScore(sign, utils.ZodiacSign(other)); score < compatibleSignScore {
+				t.Fatalf("%s suggested to %s with compatibility %.2f", other, sign, score)
+			}
+		}
+	}
+
+	if signs := compatibleSigns(""); signs != nil {
+		t.Fatalf("compatibleSigns(\"\") = %v, want nil (any sign)", signs)
+	}
+}
+
+func TestGetSuggestions(t *testing.T) {
+	db := mongotest.NewDatabase(t)
+	ctx := context.Background()
+	userRepo := repositories.NewUserRepository(db)
+	friendshipRepo := repositories.NewFriendshipRepository(db)
+	suggestions := NewSuggestionService(friendshipRepo, userRepo, repositories.NewBlockRepository(db), NewSuggestionCache(time.Minute), testWeights)
+
+	create := func(name string, sign utils.ZodiacSign) primitive.ObjectID {
+		user := &models.User{Email: name + "@example.com", FullName: name, DisplayName: name, ZodiacSign: string(sign)}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		return user.ID
+	}
+	suspend := func(id primitive.ObjectID, until *time.Time) {
+		if err := userRepo.Suspend(ctx, id, until, "spam"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	user := create("user", utils.Leo)
+	friend1, friend2 := create("friend1", utils.Taurus), create("friend2", utils.Taurus)
+
+	// Friends of friends
+	twoMutual := create("two-mutual", utils.Virgo) // Semi-sextile: 0.55
+	oneMutual := create("one-mutual", utils.Aries) // Trine: 0.95
+	suspendedMutual := create("suspended-mutual", utils.Aries)
+	suspend(suspendedMutual, nil)
+
+	// Compatible signs without mutual friends
+	compatible := create("compatible", utils.Sagittarius) // Trine: 0.95
+	lapsed := create("lapsed", utils.Libra)               // Sextile: 0.85
+	past := time.Now().Add(-time.Hour)
+	suspend(lapsed, &past)
+	suspendedCompatible := create("suspended-compatible", utils.Gemini)
+	future := time.Now().Add(time.Hour)
+	suspend(suspendedCompatible, &future)
+
+	// Incompatible sign without mutual friends: not suggested
+	create("incompatible", utils.Scorpio) // Square: 0.40
+
+	friendships := [][2]primitive.ObjectID{
+		{user, friend1}, {user, friend2},
+		{friend1, twoMutual}, {friend2, twoMutual},
+		{friend1, oneMutual},
+		{friend1, suspendedMutual}, {friend2, suspendedMutual},
+	}
+	for _, pair := range friendships {
+		for _, id := range pair {
+			if _, err := friendshipRepo.GetOrCreateFriendship(ctx, id); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := friendshipRepo.AcceptFriendship(ctx, pair[0], pair[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := suggestions.GetSuggestions(ctx, user.Hex(), &models.FriendSuggestionQuery{})
+	if err != nil {
+		t.Fatalf("GetSuggestions: %v", err)
+	}
+
+	want := []struct {
+		id            primitive.ObjectID
+		mutualFriends int
+		compatibility float64
+	}{
+		{id: oneMutual, mutualFriends: 1, compatibility: 0.95},  // 0.460
+		{id: twoMutual, mutualFriends: 2, compatibility: 0.55},  // 0.445
+		{id: compatible, mutualFriends: 0, compatibility: 0.95}, // 0.285
+		{id: lapsed, mutualFriends: 0, compatibility: 0.85},     // 0.255
+	}
+	if len(got) != len(want) {
+		names := make([]string, len(got))
+		for i, suggestion := range got {
+			names[i] = suggestion.User.DisplayName
+		}
+		t.Fatalf("suggested %v, want %d users", names, len(want))
+	}
+	for i, w := range want {
+		suggestion := got[i]
+		if suggestion.User.ID != w.id {
+			t.Fatalf("suggestion %d is %s, want %s", i, suggestion.User.DisplayName, w.id.Hex())
+		}
+		if suggestion.MutualFriends != w.mutualFriends || suggestion.Compatibility != w.compatibility {
+			t.Fatalf("%s: mutual_friends=%d compatibility=%.2f, want %d and %.2f",
+				suggestion.User.DisplayName, suggestion.MutualFriends, suggestion.Compatibility, w.mutualFriends, w.compatibility)
+		}
+		if score := testWeights.score(w.mutualFriends, w.compatibility); math.Abs(suggestion.Score-score) > 1e-9 {
+			t.Fatalf("%s: score %f, want %f", suggestion.User.DisplayName, suggestion.Score, score)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"bufio"
+	"context"
+
 	"zodiac-ai-backend/pkg/middleware"
 	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/pkg/sse"
 	"zodiac-ai-backend/services/chat-service/models"
 	"zodiac-ai-backend/services/chat-service/services"
 
@@ -75,6 +79,68 @@ func (h *ChatHandler) SendMessage(c *fiber.Ctx) error {
 	return response.Success(c, "Message sent successfully", messageResp)
 }
 
+// StreamMessage sends a message to AI and streams the reply as Server-Sent Events
+// POST /chat/sessions/:id/messages/stream
+// Events: start {user_message}, chunk {delta} per token chunk, then done {ai_message} or error {message}
+func (h *ChatHandler) StreamMessage(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	zodiacSign := middleware.GetZodiacSign(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return response.BadRequest(c, "Session ID required", nil)
+	}
+
+	var req models.SendMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if req.Message == "" {
+		return response.BadRequest(c, "Message is required", nil)
+	}
+
+	// Session errors are still plain JSON responses; the stream starts once the user message is saved
+	userMessage, err := h.chatService.StartStream(c.Context(), sessionID, userID, req.Message)
+	if err != nil {
+		if err == services.ErrSessionNotFound {
+			return response.NotFound(c, "Chat session not found")
+		}
+		return response.InternalServerError(c, "Failed to send message")
+	}
+
+	for key, value := range sse.Headers() {
+		c.Set(key, value)
+	}
+
+	// The writer runs after this handler returns, so it must not touch c
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := sse.Write(w, sse.EventStart, fiber.Map{"user_message": userMessage}); err != nil {
+			return
+		}
+
+		aiMessage, err := h.chatService.StreamAIResponse(context.Background(), userMessage, zodiacSign, func(delta string) error {
+			return sse.Write(w, sse.EventChunk, sse.Chunk{Delta: delta})
+		})
+		if err != nil {
+			message := "Failed to get AI response"
+			if err == services.ErrAIServiceDown {
+				message = "AI service temporarily unavailable"
+			}
+			// No-op if the client is already gone
+			sse.Write(w, sse.EventError, sse.Error{Message: message})
+			return
+		}
+
+		sse.Write(w, sse.EventDone, fiber.Map{"ai_message": aiMessage})
+	})
+
+	return nil
+}
+
 // GetMessages gets chat history with pagination
 // GET /chat/sessions/:id/messages
 func (h *ChatHandler) GetMessages(c *fiber.Ctx) error {
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"zodiac-ai-backend/pkg/jwt"
+	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/response"
+	authRepos "zodiac-ai-backend/services/auth-service/repositories"
+	"zodiac-ai-backend/services/chat-service/models"
+	"zodiac-ai-backend/services/chat-service/services"
+	"zodiac-ai-backend/services/chat-service/websocket"
+
+	"github.com/gofiber/fiber/v2"
+	ws "github.com/gofiber/websocket/v2"
+)
+
+// DirectMessageHandler handles direct message HTTP and WebSocket requests
+type DirectMessageHandler struct {
+	dmService *services.DirectMessageService
+	userRepo  *authRepos.UserRepository
+	hub       *websocket.Hub
+}
+
+// NewDirectMessageHandler creates a new direct message handler
+func NewDirectMessageHandler(
+	dmService *services.DirectMessageService,
+	userRepo *authRepos.UserRepository,
+	hub *websocket.Hub,
+) *DirectMessageHandler {
+	return &DirectMessageHandler{
+		dmService: dmService,
+		userRepo:  userRepo,
+		hub:       hub,
+	}
+}
+
+// StartConversation gets or creates a conversation with a friend
+// POST /conversations
+func (h *DirectMessageHandler) StartConversation(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req models.StartConversationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	conversation, err := h.dmService.StartConversation(c.Context(), userID, &req)
+	if err != nil {
+		return dmErrorResponse(c, err, "Failed to start conversation")
+	}
+
+	return response.Success(c, "Conversation retrieved successfully", conversation)
+}
+
+// GetConversations gets the user's conversations with unread counts
+// GET /conversations
+func (h *DirectMessageHandler) GetConversations(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	cursor := c.Query("cursor", "")
+	limit := c.QueryInt("limit", 20)
+	if limit <= 0 || limit > 50 {
+		limit = 20 // Default limit
+	}
+
+	conversations, nextCursor, err := h.dmService.GetConversations(c.Context(), userID, cursor, limit)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get conversations")
+	}
+
+	meta := &response.MetaData{
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+		Limit:      limit,
+	}
+
+	return response.SuccessWithMeta(c, "Conversations retrieved successfully", conversations, meta)
+}
+
+// GetMessages gets conversation history with pagination
+// GET /conversations/:id/messages
+func (h *DirectMessageHandler) GetMessages(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	cursor := c.Query("cursor", "")
+	limit := c.QueryInt("limit", 20)
+	if limit <= 0 || limit > 50 {
+		limit = 20 // Default limit
+	}
+
+	messages, nextCursor, err := h.dmService.GetMessages(c.Context(), c.Params("id"), userID, cursor, limit)
+	if err != nil {
+		return dmErrorResponse(c, err, "Failed to get messages")
+	}
+
+	meta := &response.MetaData{
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+		Limit:      limit,
+	}
+
+	return response.SuccessWithMeta(c, "Messages retrieved successfully", messages, meta)
+}
+
+// SendMessage sends a direct message and delivers it in real time
+// POST /conversations/:id/messages
+func (h *DirectMessageHandler) SendMessage(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req models.SendDirectMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	message, recipientID, err := h.dmService.SendMessage(c.Context(), c.Params("id"), userID, &req)
+	if err != nil {
+		return dmErrorResponse(c, err, "Failed to send message")
+	}
+
+	// Deliver to the recipient and to the sender's other open clients
+	frame := models.NewFrame(models.FrameDirectMessage)
+	frame.ID = message.ID.Hex()
+	frame.ConversationID = message.ConversationID.Hex()
+	frame.UserID = userID
+	frame.Content = message.Content
+	frame.Timestamp = message.CreatedAt
+
+	h.hub.SendToUser(recipientID, frame)
+	h.hub.SendToUser(userID, frame)
+
+	return response.Created(c, "Message sent successfully", message)
+}
+
+// MarkRead resets the user's unread count for a conversation
+// POST /conversations/:id/read
+func (h *DirectMessageHandler) MarkRead(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	if err := h.dmService.MarkRead(c.Context(), c.Params("id"), userID); err != nil {
+		return dmErrorResponse(c, err, "Failed to mark conversation as read")
+	}
+
+	return response.Success(c, "Conversation marked as read", nil)
+}
+
+// AuthorizeInbox authenticates a WebSocket inbox connection before the upgrade
+// Failures are reported as typed close frames by Inbox (see RoomHandler.AuthorizeJoin)
+func (h *DirectMessageHandler) AuthorizeInbox(jwtManager *jwt.Manager, validators ...middleware.TokenValidator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !ws.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+
+		user, closeReason := authenticateSocket(c, jwtManager, h.userRepo, validators)
+		if closeReason != nil {
+			return rejectSocket(c, closeReason)
+		}
+
+		username := user.DisplayName
+		if username == "" {
+			username = user.FullName
+		}
+
+		c.Locals("user_id", user.ID.Hex())
+		c.Locals("username", username)
+
+		return c.Next()
+	}
+}
+
+// Inbox handles the receive-only WebSocket for direct messages
+// WS /conversations/ws
+func (h *DirectMessageHandler) Inbox(c *ws.Conn) {
+	// Refused by AuthorizeInbox
+	if closeIfRejected(c) {
+		return
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	username, _ := c.Locals("username").(string)
+
+	if userID == "" {
+		websocket.CloseWithReason(c, websocket.CloseUnauthorized, "not authenticated")
+		return
+	}
+
+	client := h.hub.NewClient(c, websocket.UserChannel(userID), userID, username)
+
+	// Register client
+	h.hub.Register(client)
+
+	// Start read and write pumps
+	go client.WritePump()
+	client.ReadPump()
+}
+
+// dmErrorResponse maps direct message service errors to HTTP responses
+func dmErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	if details, ok := validationDetails(err); ok {
+		return response.BadRequest(c, "Validation failed", details)
+	}
+
+	switch err {
+	case services.ErrConversationNotFound:
+		return response.NotFound(c, "Conversation not found")
+	case services.ErrNotFriends:
+		return response.Forbidden(c, "You can only message friends")
+	case services.ErrConversationReadOnly:
+		return response.Forbidden(c, "Conversation is read-only because you are no longer friends")
+	case services.ErrEmptyMessage:
+		return response.BadRequest(c, "Message content is required", nil)
+	}
+
+	return response.InternalServerError(c, fallback)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/services/chat-service/services"
+	"zodiac-ai-backend/services/chat-service/websocket"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ModerationHandler handles room removal by admins and moderators
+type ModerationHandler struct {
+	moderationService *services.ModerationService
+	hub               *websocket.Hub
+}
+
+// NewModerationHandler creates a new moderation handler
+func NewModerationHandler(moderationService *services.ModerationService, hub *websocket.Hub) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+		hub:               hub,
+	}
+}
+
+// DeleteRoom removes a room
+// DELETE /admin/rooms/:id
+func (h *ModerationHandler) DeleteRoom(c *fiber.Ctx) error {
+	roomID := c.Params("id")
+
+	if err := h.moderationService.DeleteRoom(c.Context(), middleware.GetUserID(c), roomID); err != nil {
+		return roomErrorResponse(c, err, "Failed to delete room")
+	}
+
+	// Disconnect everyone still in the room
+	h.hub.SendControl(roomID, &websocket.RoomControl{Action: websocket.ControlDelete})
+
+	return response.Success(c, "Room deleted successfully", nil)
+}
@@ -1,10 +1,16 @@
 package handlers
 
 import (
+	"strings"
+	"time"
+
+	"zodiac-ai-backend/pkg/jwt"
 	"zodiac-ai-backend/pkg/middleware"
 	"zodiac-ai-backend/pkg/response"
+	authRepos "zodiac-ai-backend/services/auth-service/repositories"
 	"zodiac-ai-backend/services/chat-service/models"
 	"zodiac-ai-backend/services/chat-service/repositories"
+	"zodiac-ai-backend/services/chat-service/services"
 	"zodiac-ai-backend/services/chat-service/websocket"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,15 +20,27 @@ import (
 
 // RoomHandler handles room HTTP and WebSocket requests
 type RoomHandler struct {
-	roomRepo *repositories.RoomRepository
-	hub      *websocket.Hub
+	roomService *services.RoomService
+	roomRepo    *repositories.RoomRepository
+	userRepo    *authRepos.UserRepository
+	blockRepo   *authRepos.BlockRepository
+	hub         *websocket.Hub
 }
 
 // NewRoomHandler creates a new room handler
-func NewRoomHandler(roomRepo *repositories.RoomRepository, hub *websocket.Hub) *RoomHandler {
+func NewRoomHandler(
+	roomService *services.RoomService,
+	roomRepo *repositories.RoomRepository,
+	userRepo *authRepos.UserRepository,
+	blockRepo *authRepos.BlockRepository,
+	hub *websocket.Hub,
+) *RoomHandler {
 	return &RoomHandler{
-		roomRepo: roomRepo,
-		hub:      hub,
+		roomService: roomService,
+		roomRepo:    roomRepo,
+		userRepo:    userRepo,
+		blockRepo:   blockRepo,
+		hub:         hub,
 	}
 }
 
@@ -39,17 +57,9 @@ func (h *RoomHandler) CreateRoom(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body", nil)
 	}
 
-	userObjID, _ := primitive.ObjectIDFromHex(userID)
-
-	room := &models.Room{
-		Name:         req.Name,
-		Topic:        req.Topic,
-		ZodiacFilter: req.ZodiacFilter,
-		CreatorID:    userObjID,
-	}
-
-	if err := h.roomRepo.Create(c.Context(), room); err != nil {
-		return response.InternalServerError(c, "Failed to create room")
+	room, err := h.roomService.CreateRoom(c.Context(), userID, &req)
+	if err != nil {
+		return roomErrorResponse(c, err, "Failed to create room")
 	}
 
 	return response.Created(c, "Room created successfully", room)
@@ -70,34 +80,157 @@ func (h *RoomHandler) GetRooms(c *fiber.Ctx) error {
 	return response.Success(c, "Rooms retrieved successfully", rooms)
 }
 
+// GetMessages gets room message history with pagination (members only)
+// GET /rooms/:id/messages
+func (h *RoomHandler) GetMessages(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	roomID := c.Params("id")
+	if roomID == "" {
+		return response.BadRequest(c, "Room ID is required", nil)
+	}
+
+	roomObjID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return response.BadRequest(c, "Invalid room ID", nil)
+	}
+
+	room, err := h.roomRepo.FindByID(c.Context(), roomObjID)
+	if err != nil {
+		return response.NotFound(c, "Room not found")
+	}
+
+	userObjID, _ := primitive.ObjectIDFromHex(userID)
+	if _, err := h.roomService.Membership(c.Context(), room, userObjID); err != nil {
+		return roomErrorResponse(c, err, "Failed to get room messages")
+	}
+
+	cursor := c.Query("cursor", "")
+	limit := c.QueryInt("limit", 20)
+	if limit <= 0 || limit > 50 {
+		limit = 20 // Default limit
+	}
+
+	messages, nextCursor, err := h.roomRepo.FindMessagesByRoomID(c.Context(), roomObjID, cursor, limit)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get room messages")
+	}
+
+	meta := &response.MetaData{
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+		Limit:      limit,
+	}
+
+	return response.SuccessWithMeta(c, "Room messages retrieved successfully", messages, meta)
+}
+
+// AuthorizeJoin authenticates and authorizes a WebSocket room join before the upgrade
+// Failures don't abort the handshake: browsers can't read HTTP errors on an upgrade,
+// so the reason is stored in locals and JoinRoom sends it as a typed close frame
+func (h *RoomHandler) AuthorizeJoin(jwtManager *jwt.Manager, validators ...middleware.TokenValidator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !ws.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+
+		reject := func(code int, reason string) error {
+			return rejectSocket(c, &websocket.CloseReason{Code: code, Reason: reason})
+		}
+
+		// Verify JWT (Authorization header or ?token=) and load the stored profile
+		user, closeReason := authenticateSocket(c, jwtManager, h.userRepo, validators)
+		if closeReason != nil {
+			return rejectSocket(c, closeReason)
+		}
+
+		// Validate room exists
+		roomObjID, err := primitive.ObjectIDFromHex(c.Params("id"))
+		if err != nil {
+			return reject(websocket.CloseRoomNotFound, "room not found")
+		}
+
+		room, err := h.roomRepo.FindByID(c.Context(), roomObjID)
+		if err != nil {
+			return reject(websocket.CloseRoomNotFound, "room not found")
+		}
+
+		// Enforce zodiac filter against the stored sign (token claims may be stale)
+		if room.ZodiacFilter != "" && !strings.EqualFold(room.ZodiacFilter, user.ZodiacSign) {
+			return reject(websocket.CloseForbidden, "room is restricted to "+room.ZodiacFilter)
+		}
+
+		// Only members may connect (join via POST /rooms/:id/join first)
+		member, err := h.roomService.Membership(c.Context(), room, user.ID)
+		if err != nil {
+			switch err {
+			case services.ErrBannedFromRoom:
+				return reject(websocket.CloseForbidden, "banned from room")
+			case services.ErrNotRoomMember:
+				return reject(websocket.CloseForbidden, "not a room member")
+			}
+			return reject(websocket.CloseInternalError, "failed to load membership")
+		}
+
+		// Frames from users blocked either way are kept from this connection
+		hiddenIDs, err := h.blockRepo.HiddenFrom(c.Context(), user.ID)
+		if err != nil {
+			return reject(websocket.CloseInternalError, "failed to load blocked users")
+		}
+		hiddenUsers := make([]string, 0, len(hiddenIDs))
+		for _, id := range hiddenIDs {
+			hiddenUsers = append(hiddenUsers, id.Hex())
+		}
+
+		username := user.DisplayName
+		if username == "" {
+			username = user.FullName
+		}
+
+		c.Locals("user_id", user.ID.Hex())
+		c.Locals("username", username)
+		c.Locals("zodiac_sign", user.ZodiacSign)
+		c.Locals("muted_until", member.MutedUntil)
+		c.Locals("hidden_users", hiddenUsers)
+
+		return c.Next()
+	}
+}
+
 // JoinRoom handles WebSocket connection to a room
 // WS /rooms/:id/ws
 func (h *RoomHandler) JoinRoom(c *ws.Conn) {
+	// Refused by AuthorizeJoin
+	if closeIfRejected(c) {
+		return
+	}
+
 	// Get room ID from params
 	roomID := c.Params("id")
 	if roomID == "" {
-		c.Close()
+		websocket.CloseWithReason(c, websocket.CloseRoomNotFound, "room not found")
 		return
 	}
 
-	// Get user info from locals (set by middleware)
-	userID := c.Locals("user_id")
-	username := c.Locals("username")
+	// Get user info from locals (set by AuthorizeJoin)
+	userID, _ := c.Locals("user_id").(string)
+	username, _ := c.Locals("username").(string)
 
-	if userID == nil || username == nil {
-		c.Close()
+	if userID == "" || username == "" {
+		websocket.CloseWithReason(c, websocket.CloseUnauthorized, "not authenticated")
 		return
 	}
 
 	// Create client
-	client := &websocket.Client{
-		ID:       userID.(string) + "_" + roomID,
-		RoomID:   roomID,
-		UserID:   userID.(string),
-		Username: username.(string),
-		Conn:     c,
-		Hub:      h.hub,
-		Send:     make(chan *models.WebSocketMessage, 256),
+	client := h.hub.NewClient(c, roomID, userID, username)
+	if mutedUntil, ok := c.Locals("muted_until").(*time.Time); ok {
+		client.SetMutedUntil(mutedUntil)
+	}
+	if hiddenUsers, ok := c.Locals("hidden_users").([]string); ok {
+		client.SetHiddenUsers(hiddenUsers)
 	}
 
 	// Register client
@@ -108,7 +241,7 @@ func (h *RoomHandler) JoinRoom(c *ws.Conn) {
 	client.ReadPump()
 }
 
-// DeleteRoom deletes a room
+// DeleteRoom deletes a room with its members and messages (owner only)
 // DELETE /rooms/:id
 func (h *RoomHandler) DeleteRoom(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
@@ -121,27 +254,228 @@ func (h *RoomHandler) DeleteRoom(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Room ID is required", nil)
 	}
 
-	roomObjID, err := primitive.ObjectIDFromHex(roomID)
+	if err := h.roomService.DeleteRoom(c.Context(), roomID, userID); err != nil {
+		if err == services.ErrInsufficientRole {
+			return response.Forbidden(c, "Only the room owner can delete the room")
+		}
+		return roomErrorResponse(c, err, "Failed to delete room")
+	}
+
+	// Disconnect everyone still in the room
+	h.hub.SendControl(roomID, &websocket.RoomControl{Action: websocket.ControlDelete})
+
+	return response.Success(c, "Room deleted successfully", nil)
+}
+
+// Join joins a room as a member
+// POST /rooms/:id/join
+func (h *RoomHandler) Join(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	member, err := h.roomService.JoinRoom(c.Context(), c.Params("id"), userID)
 	if err != nil {
-		return response.BadRequest(c, "Invalid room ID", nil)
+		return roomErrorResponse(c, err, "Failed to join room")
 	}
 
-	// Check if room exists and user is the creator
-	room, err := h.roomRepo.FindByID(c.Context(), roomObjID)
+	return response.Created(c, "Joined room successfully", member)
+}
+
+// Leave leaves a room
+// POST /rooms/:id/leave
+func (h *RoomHandler) Leave(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	roomID := c.Params("id")
+	if err := h.roomService.LeaveRoom(c.Context(), roomID, userID); err != nil {
+		return roomErrorResponse(c, err, "Failed to leave room")
+	}
+
+	// Close the user's other open connections to the room
+	h.hub.SendControl(roomID, &websocket.RoomControl{Action: websocket.ControlLeave, UserID: userID})
+
+	return response.Success(c, "Left room successfully", nil)
+}
+
+// GetMembers gets room members with pagination
+// GET /rooms/:id/members?banned=true lists bans (moderators only)
+func (h *RoomHandler) GetMembers(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	cursor := c.Query("cursor", "")
+	banned := c.QueryBool("banned", false)
+	limit := c.QueryInt("limit", 20)
+	if limit <= 0 || limit > 50 {
+		limit = 20 // Default limit
+	}
+
+	members, nextCursor, err := h.roomService.GetMembers(c.Context(), c.Params("id"), userID, banned, cursor, limit)
 	if err != nil {
-		return response.NotFound(c, "Room not found")
+		return roomErrorResponse(c, err, "Failed to get room members")
 	}
 
-	userObjID, _ := primitive.ObjectIDFromHex(userID)
-	if room.CreatorID != userObjID {
-		return response.Forbidden(c, "Only room creator can delete the room")
+	meta := &response.MetaData{
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+		Limit:      limit,
 	}
 
-	// Delete the room
-	if err := h.roomRepo.Delete(c.Context(), roomObjID); err != nil {
-		return response.InternalServerError(c, "Failed to delete room")
+	return response.SuccessWithMeta(c, "Room members retrieved successfully", members, meta)
+}
+
+// KickMember removes a member and closes their live connections
+// POST /rooms/:id/members/:userId/kick
+func (h *RoomHandler) KickMember(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
 	}
 
-	return response.Success(c, "Room deleted successfully", nil)
+	roomID, targetID := c.Params("id"), c.Params("userId")
+	if err := h.roomService.KickMember(c.Context(), roomID, userID, targetID); err != nil {
+		return roomErrorResponse(c, err, "Failed to kick member")
+	}
+
+	h.hub.SendControl(roomID, &websocket.RoomControl{Action: websocket.ControlKick, UserID: targetID})
+
+	return response.Success(c, "Member kicked successfully", nil)
+}
+
+// BanMember bans a user and closes their live connections
+// POST /rooms/:id/members/:userId/ban
+func (h *RoomHandler) BanMember(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	roomID, targetID := c.Params("id"), c.Params("userId")
+	if err := h.roomService.BanMember(c.Context(), roomID, userID, targetID); err != nil {
+		return roomErrorResponse(c, err, "Failed to ban member")
+	}
+
+	h.hub.SendControl(roomID, &websocket.RoomControl{Action: websocket.ControlBan, UserID: targetID})
+
+	return response.Success(c, "Member banned successfully", nil)
+}
+
+// UnbanMember lifts a ban
+// DELETE /rooms/:id/members/:userId/ban
+func (h *RoomHandler) UnbanMember(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	if err := h.roomService.UnbanMember(c.Context(), c.Params("id"), userID, c.Params("userId")); err != nil {
+		return roomErrorResponse(c, err, "Failed to unban member")
+	}
+
+	return response.Success(c, "Member unbanned successfully", nil)
+}
+
+// MuteMember prevents a member from sending messages for a while
+// POST /rooms/:id/members/:userId/mute
+func (h *RoomHandler) MuteMember(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req models.MuteMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	roomID, targetID := c.Params("id"), c.Params("userId")
+	until, err := h.roomService.MuteMember(c.Context(), roomID, userID, targetID, &req)
+	if err != nil {
+		return roomErrorResponse(c, err, "Failed to mute member")
+	}
+
+	h.hub.SendControl(roomID, &websocket.RoomControl{Action: websocket.ControlMute, UserID: targetID, MutedUntil: &until})
+
+	return response.Success(c, "Member muted successfully", fiber.Map{
+		"muted_until": until,
+	})
 }
 
+// UnmuteMember lifts a mute
+// DELETE /rooms/:id/members/:userId/mute
+func (h *RoomHandler) UnmuteMember(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	roomID, targetID := c.Params("id"), c.Params("userId")
+	if err := h.roomService.UnmuteMember(c.Context(), roomID, userID, targetID); err != nil {
+		return roomErrorResponse(c, err, "Failed to unmute member")
+	}
+
+	h.hub.SendControl(roomID, &websocket.RoomControl{Action: websocket.ControlMute, UserID: targetID})
+
+	return response.Success(c, "Member unmuted successfully", nil)
+}
+
+// UpdateMemberRole promotes or demotes a member (owner only)
+// PUT /rooms/:id/members/:userId/role
+func (h *RoomHandler) UpdateMemberRole(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+
+	var req models.UpdateMemberRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.roomService.UpdateMemberRole(c.Context(), c.Params("id"), userID, c.Params("userId"), &req); err != nil {
+		return roomErrorResponse(c, err, "Failed to update member role")
+	}
+
+	return response.Success(c, "Member role updated successfully", nil)
+}
+
+// roomErrorResponse maps room service errors to HTTP responses
+func roomErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	if details, ok := validationDetails(err); ok {
+		return response.BadRequest(c, "Validation failed", details)
+	}
+
+	switch err {
+	case services.ErrRoomNotFound:
+		return response.NotFound(c, "Room not found")
+	case services.ErrNotRoomMember:
+		return response.Forbidden(c, "Not a member of this room")
+	case services.ErrAlreadyRoomMember:
+		return response.Conflict(c, "Already a member of this room")
+	case services.ErrBannedFromRoom:
+		return response.Forbidden(c, "You are banned from this room")
+	case services.ErrNotBanned:
+		return response.NotFound(c, "User is not banned")
+	case services.ErrZodiacRestricted:
+		return response.Forbidden(c, "Room is restricted to another zodiac sign")
+	case services.ErrInsufficientRole:
+		return response.Forbidden(c, "Insufficient room permissions")
+	case services.ErrOwnerCannotLeave:
+		return response.BadRequest(c, "Room owner cannot leave the room, delete it instead", nil)
+	case services.ErrInvalidRoomRole:
+		return response.BadRequest(c, "Role must be moderator or member", nil)
+	case services.ErrCannotModerateSelf:
+		return response.BadRequest(c, "Cannot moderate yourself", nil)
+	case authRepos.ErrUserNotFound:
+		return response.NotFound(c, "User not found")
+	}
+
+	return response.InternalServerError(c, fallback)
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validationDetails converts validator errors into response details (field -> failed rule)
+// Returns false if err isn't a validation error
+func validationDetails(err error) (map[string]interface{}, bool) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil, false
+	}
+
+	details := make(map[string]interface{}, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		details[fieldErr.Field()] = fieldErr.Tag()
+	}
+	return details, true
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"zodiac-ai-backend/pkg/jwt"
+	"zodiac-ai-backend/pkg/middleware"
+	authModels "zodiac-ai-backend/services/auth-service/models"
+	authRepos "zodiac-ai-backend/services/auth-service/repositories"
+	"zodiac-ai-backend/services/chat-service/websocket"
+
+	"github.com/gofiber/fiber/v2"
+	ws "github.com/gofiber/websocket/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// authenticateSocket verifies the access token of a WebSocket upgrade and loads the user
+// Returns a close reason instead of an HTTP error: browsers can't read HTTP errors on an upgrade
+func authenticateSocket(c *fiber.Ctx, jwtManager *jwt.Manager, userRepo *authRepos.UserRepository, validators []middleware.TokenValidator) (*authModels.User, *websocket.CloseReason) {
+	// Authorization header or ?token=
+	tokenString, err := middleware.ExtractToken(c)
+	if err != nil {
+		return nil, &websocket.CloseReason{Code: websocket.CloseUnauthorized, Reason: "missing token"}
+	}
+
+	claims, err := jwtManager.VerifyToken(tokenString)
+	if err != nil {
+		if err == jwt.ErrExpiredToken {
+			return nil, &websocket.CloseReason{Code: websocket.CloseUnauthorized, Reason: "token expired"}
+		}
+		return nil, &websocket.CloseReason{Code: websocket.CloseUnauthorized, Reason: "invalid token"}
+	}
+
+	if err := jwtManager.ValidateTokenType(claims, jwt.AccessToken); err != nil {
+		return nil, &websocket.CloseReason{Code: websocket.CloseUnauthorized, Reason: "invalid token type"}
+	}
+
+	for _, validate := range validators {
+		if err := validate(c.Context(), claims); err != nil {
+			if err == middleware.ErrSessionRevoked {
+				return nil, &websocket.CloseReason{Code: websocket.CloseUnauthorized, Reason: "session revoked"}
+			}
+			if err == middleware.ErrUserSuspended {
+				return nil, &websocket.CloseReason{Code: websocket.CloseForbidden, Reason: "account suspended"}
+			}
+			return nil, &websocket.CloseReason{Code: websocket.CloseInternalError, Reason: "failed to verify session"}
+		}
+	}
+
+	// Load display name and stored zodiac sign
+	userObjID, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		return nil, &websocket.CloseReason{Code: websocket.CloseUnauthorized, Reason: "invalid token"}
+	}
+
+	user, err := userRepo.FindByID(c.Context(), userObjID)
+	if err != nil {
+		if err == authRepos.ErrUserNotFound {
+			return nil, &websocket.CloseReason{Code: websocket.CloseUnauthorized, Reason: "user not found"}
+		}
+		return nil, &websocket.CloseReason{Code: websocket.CloseInternalError, Reason: "failed to load user"}
+	}
+
+	return user, nil
+}
+
+// rejectSocket stores a close reason and lets the upgrade continue,
+// so the WebSocket handler can send it as a typed close frame
+func rejectSocket(c *fiber.Ctx, reason *websocket.CloseReason) error {
+	c.Locals("ws_close", reason)
+	return c.Next()
+}
+
+// closeIfRejected closes a connection refused before the upgrade
+// Returns true if the connection was closed
+func closeIfRejected(c *ws.Conn) bool {
+	reason, ok := c.Locals("ws_close").(*websocket.CloseReason)
+	if !ok {
+		return false
+	}
+	websocket.CloseWithReason(c, reason.Code, reason.Reason)
+	return true
+}
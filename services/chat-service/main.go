@@ -13,6 +13,7 @@ import (
 	"zodiac-ai-backend/pkg/database"
 	"zodiac-ai-backend/pkg/jwt"
 	"zodiac-ai-backend/pkg/middleware"
+	authRepos "zodiac-ai-backend/services/auth-service/repositories"
 	"zodiac-ai-backend/services/chat-service/handlers"
 	"zodiac-ai-backend/services/chat-service/repositories"
 	"zodiac-ai-backend/services/chat-service/services"
@@ -25,6 +26,9 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Connect to MongoDB
 	_, err := database.Connect(database.MongoConfig{
@@ -39,27 +43,72 @@ func main() {
 	db := database.GetDatabase(cfg.MongoDatabase)
 
 	// Initialize JWT manager
-	jwtManager := jwt.NewManager(
-		cfg.JWTSecret,
-		cfg.JWTAccessExpiry,
-		cfg.JWTRefreshExpiry,
-	)
+	jwtManager, err := jwt.New(jwt.Config{
+		Secret:            cfg.JWTSecret,
+		Keys:              cfg.JWTKeys,
+		ActiveKeyID:       cfg.JWTActiveKeyID,
+		AcceptLegacyHS256: cfg.JWTAcceptHS256,
+		AccessExpiry:      cfg.JWTAccessExpiry,
+		RefreshExpiry:     cfg.JWTRefreshExpiry,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize JWT manager: %v", err)
+	}
 
 	// Initialize repositories
 	sessionRepo := repositories.NewChatSessionRepository(db)
 	messageRepo := repositories.NewMessageRepository(db)
 	roomRepo := repositories.NewRoomRepository(db)
+	roomMemberRepo := repositories.NewRoomMemberRepository(db)
+	conversationRepo := repositories.NewConversationRepository(db)
+	userRepo := authRepos.NewUserRepository(db)             // Shared users collection (display names, zodiac filter)
+	friendshipRepo := authRepos.NewFriendshipRepository(db) // Shared friendships collection (direct messages)
+	refreshTokenRepo := authRepos.NewRefreshTokenRepository(db)
+	auditLogRepo := authRepos.NewAuditLogRepository(db) // Shared audit_logs collection (moderation)
+	blockRepo := authRepos.NewBlockRepository(db)       // Shared blocks collection (hidden room frames)
+
+	// Access tokens of suspended users and logged out sessions are rejected (cached briefly)
+	suspensionCache := middleware.NewSuspensionCache(userRepo, cfg.SessionCacheTTL)
+	sessionCache := middleware.NewRevocationCache(refreshTokenRepo, cfg.SessionCacheTTL)
+	authMiddleware := middleware.AuthMiddleware(jwtManager, suspensionCache.Validate, sessionCache.Validate)
 
 	// Initialize services
-	chatService := services.NewChatService(sessionRepo, messageRepo, cfg.AIServiceURL)
-
-	// Initialize WebSocket Hub
-	hub := websocket.NewHub()
+	chatService := services.NewChatService(sessionRepo, messageRepo, cfg.AIServiceURL, services.ContextConfig{
+		TokenBudget: cfg.AIHistoryTokenBudget,
+		MaxMessages: cfg.AIHistoryMaxMessages,
+		Summarize:   cfg.AIHistorySummary,
+	})
+	roomService := services.NewRoomService(roomRepo, roomMemberRepo, userRepo)
+	dmService := services.NewDirectMessageService(conversationRepo, friendshipRepo, userRepo)
+	moderationService := services.NewModerationService(roomService, auditLogRepo)
+
+	// Initialize WebSocket Hub (backplane shares rooms across replicas)
+	var backplane websocket.Backplane = websocket.NewMemoryBackplane()
+	if cfg.WSBackplane == "mongo" {
+		backplane = websocket.NewMongoBackplane(db)
+	}
+	defer backplane.Close()
+
+	hub := websocket.NewHub(roomRepo, backplane, websocket.Config{
+		PingInterval:   cfg.WSPingInterval,
+		PongWait:       cfg.WSPongWait,
+		WriteWait:      cfg.WSWriteWait,
+		MaxMessageSize: int64(cfg.WSMaxMessageSize),
+		SendBufferSize: cfg.WSSendBufferSize,
+	})
 	go hub.Run()
 
+	// Connected users who get suspended are disconnected within one session cache TTL
+	go hub.WatchSuspensions(userRepo.SuspendedAmong, cfg.SessionCacheTTL)
+
+	// Blocks made while users are connected apply to room frames within one session cache TTL
+	go hub.WatchBlocks(blockRepo.HiddenAmong, cfg.SessionCacheTTL)
+
 	// Initialize handlers
 	chatHandler := handlers.NewChatHandler(chatService)
-	roomHandler := handlers.NewRoomHandler(roomRepo, hub)
+	roomHandler := handlers.NewRoomHandler(roomService, roomRepo, userRepo, blockRepo, hub)
+	dmHandler := handlers.NewDirectMessageHandler(dmService, userRepo, hub)
+	moderationHandler := handlers.NewModerationHandler(moderationService, hub)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
@@ -86,8 +135,9 @@ func main() {
 		}
 
 		return c.JSON(fiber.Map{
-			"status":  "healthy",
-			"service": "chat-service",
+			"status":    "healthy",
+			"service":   "chat-service",
+			"websocket": hub.Stats(),
 		})
 	})
 
@@ -96,23 +146,51 @@ func main() {
 
 	// Chat routes (protected)
 	chat := api.Group("/chat")
-	chat.Use(middleware.AuthMiddleware(jwtManager))
+	chat.Use(authMiddleware)
 
 	chat.Post("/sessions", chatHandler.CreateSession)
 	chat.Get("/sessions", chatHandler.GetSessions)
 	chat.Post("/sessions/:id/messages", chatHandler.SendMessage)
+	chat.Post("/sessions/:id/messages/stream", chatHandler.StreamMessage)
 	chat.Get("/sessions/:id/messages", chatHandler.GetMessages)
 	chat.Post("/sessions/:id/generate-insight", chatHandler.GenerateInsight)
 
 	// Room routes
 	rooms := api.Group("/rooms")
-	rooms.Use(middleware.AuthMiddleware(jwtManager))
+	rooms.Use(authMiddleware)
 	rooms.Post("", roomHandler.CreateRoom)
 	rooms.Get("", roomHandler.GetRooms)
+	rooms.Get("/:id/messages", roomHandler.GetMessages)
 	rooms.Delete("/:id", roomHandler.DeleteRoom)
-
-	// WebSocket route for room chat (auth via query param)
-	app.Get("/rooms/:id/ws", fiberws.New(roomHandler.JoinRoom))
+	rooms.Post("/:id/join", roomHandler.Join)
+	rooms.Post("/:id/leave", roomHandler.Leave)
+	rooms.Get("/:id/members", roomHandler.GetMembers)
+	rooms.Post("/:id/members/:userId/kick", roomHandler.KickMember)
+	rooms.Post("/:id/members/:userId/ban", roomHandler.BanMember)
+	rooms.Delete("/:id/members/:userId/ban", roomHandler.UnbanMember)
+	rooms.Post("/:id/members/:userId/mute", roomHandler.MuteMember)
+	rooms.Delete("/:id/members/:userId/mute", roomHandler.UnmuteMember)
+	rooms.Put("/:id/members/:userId/role", roomHandler.UpdateMemberRole)
+
+	// Direct message routes (friends only)
+	conversations := api.Group("/conversations")
+	conversations.Use(authMiddleware)
+	conversations.Post("", dmHandler.StartConversation)
+	conversations.Get("", dmHandler.GetConversations)
+	conversations.Get("/:id/messages", dmHandler.GetMessages)
+	conversations.Post("/:id/messages", dmHandler.SendMessage)
+	conversations.Post("/:id/read", dmHandler.MarkRead)
+
+	// Moderation routes (admins and moderators)
+	admin := api.Group("/admin")
+	admin.Use(authMiddleware, middleware.RequireRole(jwt.RoleAdmin, jwt.RoleModerator))
+	admin.Delete("/rooms/:id", moderationHandler.DeleteRoom)
+
+	// WebSocket route for room chat (auth via header or token query param)
+	app.Get("/rooms/:id/ws", roomHandler.AuthorizeJoin(jwtManager, suspensionCache.Validate, sessionCache.Validate), fiberws.New(roomHandler.JoinRoom))
+
+	// Receive-only WebSocket for direct messages
+	app.Get("/conversations/ws", dmHandler.AuthorizeInbox(jwtManager, suspensionCache.Validate, sessionCache.Validate), fiberws.New(dmHandler.Inbox))
 
 	// Start server
 	port := cfg.ChatServicePort
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Conversation represents a 1:1 direct message thread between two friends
+// Indexes:
+//   - participant_key: unique index (one thread per pair)
+//   - (participants, updated_at, _id): index for conversation lists
+type Conversation struct {
+	ID             primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Participants   []primitive.ObjectID `bson:"participants" json:"participants"`
+	ParticipantKey string               `bson:"participant_key" json:"-"` // "<lower id>|<higher id>"
+
+	// Embedded last message for preview (data locality)
+	LastMessage *DirectMessagePreview `bson:"last_message,omitempty" json:"last_message"`
+
+	// Unread message count per participant (keyed by user ID hex)
+	UnreadCounts map[string]int `bson:"unread_counts" json:"-"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// OtherParticipant returns the participant that isn't userID
+func (c *Conversation) OtherParticipant(userID primitive.ObjectID) primitive.ObjectID {
+	for _, participant := range c.Participants {
+		if participant != userID {
+			return participant
+		}
+	}
+	return primitive.NilObjectID
+}
+
+// HasParticipant reports whether userID takes part in the conversation
+func (c *Conversation) HasParticipant(userID primitive.ObjectID) bool {
+	for _, participant := range c.Participants {
+		if participant == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// DirectMessagePreview represents embedded last message preview
+type DirectMessagePreview struct {
+	Content   string    `bson:"content" json:"content"`
+	SenderID  string    `bson:"sender_id" json:"sender_id"`
+	Timestamp time.Time `bson:"timestamp" json:"timestamp"`
+}
+
+// DirectMessage represents a message in a conversation
+// Indexes:
+//   - (conversation_id, _id): index for cursor-paged history
+type DirectMessage struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ConversationID primitive.ObjectID `bson:"conversation_id" json:"conversation_id"`
+	SenderID       primitive.ObjectID `bson:"sender_id" json:"sender_id"`
+	Content        string             `bson:"content" json:"content"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// ConversationParticipant represents the other user in a conversation
+type ConversationParticipant struct {
+	UserID     string `json:"user_id"`
+	Username   string `json:"username"`
+	ZodiacSign string `json:"zodiac_sign"`
+}
+
+// ConversationResponse represents a conversation as seen by one participant
+type ConversationResponse struct {
+	ID          primitive.ObjectID       `json:"id"`
+	Friend      *ConversationParticipant `json:"friend"`
+	LastMessage *DirectMessagePreview    `json:"last_message"`
+	UnreadCount int                      `json:"unread_count"`
+	ReadOnly    bool                     `json:"read_only"` // No longer friends: history only
+	UpdatedAt   time.Time                `json:"updated_at"`
+}
+
+// StartConversationRequest represents start conversation request
+type StartConversationRequest struct {
+	FriendID string `json:"friend_id" validate:"required"`
+}
+
+// SendDirectMessageRequest represents send direct message request
+type SendDirectMessageRequest struct {
+	Content string `json:"content" validate:"required,max=2000"`
+}
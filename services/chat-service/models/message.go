@@ -13,6 +13,10 @@ type ChatSession struct {
 	Title     string             `bson:"title" json:"title"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+
+	// Rolling summary of messages that no longer fit the AI context window
+	Summary        string             `bson:"summary,omitempty" json:"-"`
+	SummaryUntilID primitive.ObjectID `bson:"summary_until_id,omitempty" json:"-"` // Newest message folded into Summary
 }
 
 // MessageSender represents who sent the message
@@ -35,7 +39,8 @@ type Message struct {
 	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
 	Sender    MessageSender      `bson:"sender" json:"sender"`
 	Content   string             `bson:"content" json:"content"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"` // TTL index on this field
+	Partial   bool               `bson:"partial,omitempty" json:"partial,omitempty"` // Streamed AI reply cut short (cancelled or failed)
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`               // TTL index on this field
 }
 
 // SendMessageRequest represents send message request
@@ -48,3 +53,23 @@ type MessageResponse struct {
 	UserMessage *Message `json:"user_message"`
 	AIMessage   *Message `json:"ai_message"`
 }
+
+// Turn roles understood by the AI service
+const (
+	TurnRoleUser  = "user"
+	TurnRoleModel = "model"
+)
+
+// ChatTurn is one turn of conversation history sent to the AI service
+type ChatTurn struct {
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+// AIChatRequest is the AI service chat request (POST /ai/chat)
+type AIChatRequest struct {
+	ZodiacSign  string     `json:"zodiac_sign"`
+	Summary     string     `json:"summary,omitempty"`
+	History     []ChatTurn `json:"history,omitempty"` // Oldest first, without UserMessage
+	UserMessage string     `json:"user_message"`
+}
@@ -29,6 +29,10 @@ type RoomMessagePreview struct {
 	Timestamp time.Time `bson:"timestamp" json:"timestamp"`
 }
 
+// RoomMessageTTL mirrors the room_messages TTL index (24 hours)
+// Queries filter on it as well because the TTL monitor only runs periodically
+const RoomMessageTTL = 24 * time.Hour
+
 // RoomMessage represents a message in a room
 // CRITICAL: TTL Index for storage optimization
 // Indexes:
@@ -41,6 +45,7 @@ type RoomMessage struct {
 	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
 	Username  string             `bson:"username" json:"username"` // Denormalized for display
 	Content   string             `bson:"content" json:"content"`
+	NodeID    string             `bson:"node_id,omitempty" json:"-"` // Hub replica that accepted the message
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"` // TTL index on this field
 }
 
@@ -50,12 +55,3 @@ type CreateRoomRequest struct {
 	Topic        string `json:"topic"`
 	ZodiacFilter string `json:"zodiac_filter"`
 }
-
-// WebSocketMessage represents WebSocket message format
-type WebSocketMessage struct {
-	Type      string    `json:"type"` // "message", "join", "leave"
-	UserID    string    `json:"user_id"`
-	Username  string    `json:"username"`
-	Content   string    `json:"content"`
-	Timestamp time.Time `json:"timestamp"`
-}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RoomRole represents a member's role in a room
+type RoomRole string
+
+const (
+	RoleOwner     RoomRole = "owner"
+	RoleModerator RoomRole = "moderator"
+	RoleMember    RoomRole = "member"
+)
+
+// Rank orders roles by privilege (owner > moderator > member)
+func (r RoomRole) Rank() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleModerator:
+		return 2
+	case RoleMember:
+		return 1
+	}
+	return 0
+}
+
+// CanModerate reports whether the role may kick, ban and mute lower-ranked members
+func (r RoomRole) CanModerate() bool {
+	return r.Rank() >= RoleModerator.Rank()
+}
+
+// RoomMember represents a user's membership in a room
+// Banned users keep their document (Banned = true) so they can't rejoin
+// Indexes:
+//   - (room_id, user_id): unique index
+//   - (room_id, banned, _id): index for member lists
+type RoomMember struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	RoomID     primitive.ObjectID  `bson:"room_id" json:"room_id"`
+	UserID     primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Username   string              `bson:"username" json:"username"` // Denormalized for display
+	Role       RoomRole            `bson:"role" json:"role"`
+	Banned     bool                `bson:"banned" json:"banned"`
+	BannedBy   *primitive.ObjectID `bson:"banned_by,omitempty" json:"banned_by,omitempty"`
+	MutedUntil *time.Time          `bson:"muted_until,omitempty" json:"muted_until,omitempty"`
+	JoinedAt   time.Time           `bson:"joined_at" json:"joined_at"`
+	UpdatedAt  time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// IsMuted reports whether the member is currently muted
+func (m *RoomMember) IsMuted() bool {
+	return m.MutedUntil != nil && m.MutedUntil.After(time.Now())
+}
+
+// UpdateMemberRoleRequest represents change member role request
+type UpdateMemberRoleRequest struct {
+	Role RoomRole `json:"role" validate:"required"`
+}
+
+// MuteMemberRequest represents mute member request
+type MuteMemberRequest struct {
+	DurationMinutes int `json:"duration_minutes" validate:"required,min=1"`
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProtocolVersion is the current WebSocket envelope version ("v" field)
+// Frames without "v" are treated as version 1 for older clients
+const ProtocolVersion = 1
+
+// MaxMessageContentLength is the maximum length of a chat message (in characters)
+const MaxMessageContentLength = 2000
+
+// FrameType is the closed set of WebSocket frame types
+type FrameType string
+
+const (
+	// Client-originated frames (validated, then relayed by the server)
+	FrameMessage     FrameType = "message"
+	FrameTypingStart FrameType = "typing_start"
+	FrameTypingStop  FrameType = "typing_stop"
+	FrameReadReceipt FrameType = "read_receipt"
+
+	// Server-only frames (rejected when sent by a client)
+	FrameAck              FrameType = "ack"
+	FrameError            FrameType = "error"
+	FramePresenceSnapshot FrameType = "presence_snapshot"
+	FrameJoin             FrameType = "join"
+	FrameLeave            FrameType = "leave"
+	FrameDirectMessage    FrameType = "direct_message" // Delivered on the per-user channel
+)
+
+// Error codes carried by "error" frames
+const (
+	ErrCodeInvalidFrame       = "invalid_frame"
+	ErrCodeUnsupportedVersion = "unsupported_version"
+	ErrCodeUnknownType        = "unknown_type"
+	ErrCodeForbiddenType      = "forbidden_type"
+	ErrCodeEmptyContent       = "empty_content"
+	ErrCodeContentTooLong     = "content_too_long"
+	ErrCodeInvalidMessageID   = "invalid_message_id"
+	ErrCodePersistFailed      = "persist_failed"
+	ErrCodeMuted              = "muted"
+)
+
+// IsClientFrame reports whether clients may send this frame type
+func (t FrameType) IsClientFrame() bool {
+	switch t {
+	case FrameMessage, FrameTypingStart, FrameTypingStop, FrameReadReceipt:
+		return true
+	}
+	return false
+}
+
+// IsServerFrame reports whether the frame type is reserved for the server
+func (t FrameType) IsServerFrame() bool {
+	switch t {
+	case FrameAck, FrameError, FramePresenceSnapshot, FrameJoin, FrameLeave, FrameDirectMessage:
+		return true
+	}
+	return false
+}
+
+// WebSocketMessage represents the versioned WebSocket envelope
+// See API_DOCUMENTATION.md "WebSocket Protocol" for the JSON schema
+type WebSocketMessage struct {
+	Version        int               `json:"v"`
+	Type           FrameType         `json:"type"`
+	ID             string            `json:"id,omitempty"`            // Server message ID (message, ack)
+	ClientMsgID    string            `json:"client_msg_id,omitempty"` // Client-supplied ID echoed in ack/error
+	UserID         string            `json:"user_id"`
+	Username       string            `json:"username"`
+	Content        string            `json:"content"`
+	MessageID      string            `json:"message_id,omitempty"`      // read_receipt: last message read
+	ConversationID string            `json:"conversation_id,omitempty"` // direct_message
+	Error          *WebSocketError   `json:"error,omitempty"`
+	Presence       *PresenceSnapshot `json:"presence,omitempty"`
+	Timestamp      time.Time         `json:"timestamp"`
+}
+
+// WebSocketError describes why a client frame was rejected
+type WebSocketError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PresenceMember represents a connected user
+type PresenceMember struct {
+	UserID   string `bson:"user_id" json:"user_id"`
+	Username string `bson:"username" json:"username"`
+}
+
+// PresenceSnapshot lists users currently connected to a room
+type PresenceSnapshot struct {
+	Count   int              `json:"count"`
+	Members []PresenceMember `json:"members"`
+}
+
+// NewFrame creates a server frame of the given type
+func NewFrame(frameType FrameType) *WebSocketMessage {
+	return &WebSocketMessage{
+		Version:   ProtocolVersion,
+		Type:      frameType,
+		Timestamp: time.Now(),
+	}
+}
+
+// NewErrorFrame creates an "error" frame, echoing the client message ID if any
+func NewErrorFrame(clientMsgID, code, message string) *WebSocketMessage {
+	frame := NewFrame(FrameError)
+	frame.ClientMsgID = clientMsgID
+	frame.Error = &WebSocketError{Code: code, Message: message}
+	return frame
+}
+
+// ValidateClientFrame checks a frame received from a client
+// Returns nil if the frame may be processed
+func (m *WebSocketMessage) ValidateClientFrame() *WebSocketError {
+	if m.Version != 0 && m.Version != ProtocolVersion {
+		return &WebSocketError{Code: ErrCodeUnsupportedVersion, Message: "unsupported protocol version"}
+	}
+
+	if m.Type.IsServerFrame() {
+		return &WebSocketError{Code: ErrCodeForbiddenType, Message: "frame type " + string(m.Type) + " is server-only"}
+	}
+	if !m.Type.IsClientFrame() {
+		return &WebSocketError{Code: ErrCodeUnknownType, Message: "unknown frame type"}
+	}
+
+	switch m.Type {
+	case FrameMessage:
+		content := strings.TrimSpace(m.Content)
+		if content == "" {
+			return &WebSocketError{Code: ErrCodeEmptyContent, Message: "message content is required"}
+		}
+		if utf8.RuneCountInString(content) > MaxMessageContentLength {
+			return &WebSocketError{Code: ErrCodeContentTooLong, Message: "message content is too long"}
+		}
+
+	case FrameReadReceipt:
+		if _, err := primitive.ObjectIDFromHex(m.MessageID); err != nil {
+			return &WebSocketError{Code: ErrCodeInvalidMessageID, Message: "message_id must be a valid message ID"}
+		}
+	}
+
+	return nil
+}
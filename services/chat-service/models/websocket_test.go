@@ -0,0 +1,62 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateClientFrame(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame WebSocketMessage
+		want  string // Error code, "" if the frame is accepted
+	}{
+		{name: "current version", frame: WebSocketMessage{Version: ProtocolVersion, Type: FrameMessage, Content: "hi"}},
+		{name: "no version falls back to 1", frame: WebSocketMessage{Type: FrameMessage, Content: "hi"}},
+		{name: "future version", frame: WebSocketMessage{Version: ProtocolVersion + 1, Type: FrameMessage, Content: "hi"}, want: ErrCodeUnsupportedVersion},
+		{name: "negative version", frame: WebSocketMessage{Version: -1, Type: FrameMessage, Content: "hi"}, want: ErrCodeUnsupportedVersion},
+
+		{name: "typing start", frame: WebSocketMessage{Type: FrameTypingStart}},
+		{name: "typing stop", frame: WebSocketMessage{Type: FrameTypingStop}},
+
+		{name: "ack", frame: WebSocketMessage{Type: FrameAck}, want: ErrCodeForbiddenType},
+		{name: "error", frame: WebSocketMessage{Type: FrameError}, want: ErrCodeForbiddenType},
+		{name: "presence snapshot", frame: WebSocketMessage{Type: FramePresenceSnapshot}, want: ErrCodeForbiddenType},
+		{name: "join", frame: WebSocketMessage{Type: FrameJoin}, want: ErrCodeForbiddenType},
+		{name: "leave", frame: WebSocketMessage{Type: FrameLeave}, want: ErrCodeForbiddenType},
+		{name: "direct message", frame: WebSocketMessage{Type: FrameDirectMessage, Content: "hi"}, want: ErrCodeForbiddenType},
+
+		{name: "unknown type", frame: WebSocketMessage{Type: "shout", Content: "hi"}, want: ErrCodeUnknownType},
+		{name: "missing type", frame: WebSocketMessage{Content: "hi"}, want: ErrCodeUnknownType},
+		{name: "type is case-sensitive", frame: WebSocketMessage{Type: "MESSAGE", Content: "hi"}, want: ErrCodeUnknownType},
+
+		{name: "empty content", frame: WebSocketMessage{Type: FrameMessage}, want: ErrCodeEmptyContent},
+		{name: "whitespace content", frame: WebSocketMessage{Type: FrameMessage, Content: " \t\n "}, want: ErrCodeEmptyContent},
+		{name: "content at the limit", frame: WebSocketMessage{Type: FrameMessage, Content: strings.Repeat("a", MaxMessageContentLength)}},
+		{name: "content over the limit", frame: WebSocketMessage{Type: FrameMessage, Content: strings.Repeat("a", MaxMessageContentLength+1)}, want: ErrCodeContentTooLong},
+		// 2000 three-byte runes: 6000 bytes, still within the limit
+		{name: "multi-byte content at the limit", frame: WebSocketMessage{Type: FrameMessage, Content: strings.Repeat("♌", MaxMessageContentLength)}},
+		{name: "multi-byte content over the limit", frame: WebSocketMessage{Type: FrameMessage, Content: strings.Repeat("♌", MaxMessageContentLength+1)}, want: ErrCodeContentTooLong},
+		{name: "surrounding whitespace not counted", frame: WebSocketMessage{Type: FrameMessage, Content: "  " + strings.Repeat("a", MaxMessageContentLength) + "  "}},
+
+		{name: "read receipt", frame: WebSocketMessage{Type: FrameReadReceipt, MessageID: primitive.NewObjectID().Hex()}},
+		{name: "read receipt without message ID", frame: WebSocketMessage{Type: FrameReadReceipt}, want: ErrCodeInvalidMessageID},
+		{name: "read receipt with invalid message ID", frame: WebSocketMessage{Type: FrameReadReceipt, MessageID: "not-an-id"}, want: ErrCodeInvalidMessageID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.frame.ValidateClientFrame()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("rejected with %s (%s), want accepted", err.Code, err.Message)
+			case tt.want != "" && err == nil:
+				t.Fatalf("accepted, want %s", tt.want)
+			case tt.want != "" && err.Code != tt.want:
+				t.Fatalf("code %s, want %s", err.Code, tt.want)
+			}
+		})
+	}
+}
@@ -79,3 +79,23 @@ func (r *ChatSessionRepository) Update(ctx context.Context, sessionID primitive.
 	)
 	return err
 }
+
+// UpdateSummary stores a new rolling summary
+// Only applies if the summary still ends at previousUntilID, so concurrent summaries can't overwrite a newer one
+func (r *ChatSessionRepository) UpdateSummary(ctx context.Context, sessionID, previousUntilID, untilID primitive.ObjectID, summary string) error {
+	filter := bson.M{"_id": sessionID, "summary_until_id": previousUntilID}
+	if previousUntilID.IsZero() {
+		filter["summary_until_id"] = bson.M{"$exists": false}
+	}
+
+	_, err := r.collection.UpdateOne(
+		ctx,
+		filter,
+		bson.M{"$set": bson.M{
+			"summary":          summary,
+			"summary_until_id": untilID,
+			"updated_at":       time.Now(),
+		}},
+	)
+	return err
+}
@@ -0,0 +1,222 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"zodiac-ai-backend/services/chat-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrConversationNotFound = errors.New("conversation not found")
+)
+
+// ConversationRepository handles direct message data access
+type ConversationRepository struct {
+	collection        *mongo.Collection
+	messageCollection *mongo.Collection
+}
+
+// NewConversationRepository creates a new conversation repository
+func NewConversationRepository(db *mongo.Database) *ConversationRepository {
+	return &ConversationRepository{
+		collection:        db.Collection("conversations"),
+		messageCollection: db.Collection("direct_messages"),
+	}
+}
+
+// participantKey builds the order-independent key for a pair of users
+func participantKey(a, b primitive.ObjectID) (string, []primitive.ObjectID) {
+	if a.Hex() > b.Hex() {
+		a, b = b, a
+	}
+	return a.Hex() + "|" + b.Hex(), []primitive.ObjectID{a, b}
+}
+
+// GetOrCreate gets the conversation between two users, creating it if needed
+// Upsert on the unique participant_key keeps concurrent starts from creating duplicates
+func (r *ConversationRepository) GetOrCreate(ctx context.Context, userID, friendID primitive.ObjectID) (*models.Conversation, error) {
+	key, participants := participantKey(userID, friendID)
+	now := time.Now()
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var conversation models.Conversation
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"participant_key": key},
+		bson.M{
+			"$setOnInsert": bson.M{
+				"participants": participants,
+				"unread_counts": bson.M{
+					participants[0].Hex(): 0,
+					participants[1].Hex(): 0,
+				},
+				"created_at": now,
+				"updated_at": now,
+			},
+		},
+		opts,
+	).Decode(&conversation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+// FindByID finds a conversation by ID
+func (r *ConversationRepository) FindByID(ctx context.Context, conversationID primitive.ObjectID) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := r.collection.FindOne(ctx, bson.M{"_id": conversationID}).Decode(&conversation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrConversationNotFound
+		}
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// FindByParticipant finds a user's conversations, most recently active first
+// Cursor format: "<updated_at unix millis>_<conversation id>" of the last returned conversation
+func (r *ConversationRepository) FindByParticipant(ctx context.Context, userID primitive.ObjectID, cursor string, limit int) ([]*models.Conversation, string, error) {
+	filter := bson.M{"participants": userID}
+
+	// updated_at changes on every message, so the cursor carries it alongside the ID
+	if updatedAt, cursorID, ok := parseConversationCursor(cursor); ok {
+		filter["$or"] = bson.A{
+			bson.M{"updated_at": bson.M{"$lt": updatedAt}},
+			bson.M{"updated_at": updatedAt, "_id": bson.M{"$lt": cursorID}},
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit + 1)) // Fetch one extra to check if there's more
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	var conversations []*models.Conversation
+	if err := cur.All(ctx, &conversations); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(conversations) > limit {
+		conversations = conversations[:limit]
+		last := conversations[len(conversations)-1]
+		nextCursor = strconv.FormatInt(last.UpdatedAt.UnixMilli(), 10) + "_" + last.ID.Hex()
+	}
+
+	return conversations, nextCursor, nil
+}
+
+// parseConversationCursor parses a FindByParticipant cursor
+func parseConversationCursor(cursor string) (time.Time, primitive.ObjectID, bool) {
+	millis, id, found := strings.Cut(cursor, "_")
+	if !found {
+		return time.Time{}, primitive.NilObjectID, false
+	}
+
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, false
+	}
+
+	cursorID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, false
+	}
+
+	return time.UnixMilli(ms), cursorID, true
+}
+
+// SaveMessage saves a direct message and updates the conversation preview and recipient's unread count
+func (r *ConversationRepository) SaveMessage(ctx context.Context, message *models.DirectMessage, recipientID primitive.ObjectID) error {
+	message.CreatedAt = time.Now()
+
+	result, err := r.messageCollection.InsertOne(ctx, message)
+	if err != nil {
+		return err
+	}
+	message.ID = result.InsertedID.(primitive.ObjectID)
+
+	_, err = r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": message.ConversationID},
+		bson.M{
+			"$set": bson.M{
+				"last_message": &models.DirectMessagePreview{
+					Content:   message.Content,
+					SenderID:  message.SenderID.Hex(),
+					Timestamp: message.CreatedAt,
+				},
+				"updated_at": message.CreatedAt,
+			},
+			"$inc": bson.M{"unread_counts." + recipientID.Hex(): 1},
+		},
+	)
+	return err
+}
+
+// FindMessages finds conversation messages with cursor-based pagination
+// Same contract as MessageRepository.FindBySessionID: newest first, cursor is the last returned ID
+func (r *ConversationRepository) FindMessages(ctx context.Context, conversationID primitive.ObjectID, cursor string, limit int) ([]*models.DirectMessage, string, error) {
+	filter := bson.M{"conversation_id": conversationID}
+
+	// If cursor provided, filter messages before cursor
+	if cursor != "" {
+		cursorID, err := primitive.ObjectIDFromHex(cursor)
+		if err == nil {
+			filter["_id"] = bson.M{"$lt": cursorID}
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"_id": -1}). // Newest first
+		SetLimit(int64(limit + 1))  // Fetch one extra to check if there's more
+
+	cur, err := r.messageCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	var messages []*models.DirectMessage
+	if err := cur.All(ctx, &messages); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(messages) > limit {
+		messages = messages[:limit]
+		nextCursor = messages[len(messages)-1].ID.Hex()
+	}
+
+	return messages, nextCursor, nil
+}
+
+// MarkRead resets a participant's unread count
+func (r *ConversationRepository) MarkRead(ctx context.Context, conversationID, userID primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": conversationID},
+		bson.M{"$set": bson.M{"unread_counts." + userID.Hex(): 0}},
+	)
+	return err
+}
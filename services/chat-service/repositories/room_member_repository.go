@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"zodiac-ai-backend/services/chat-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrMemberNotFound = errors.New("room member not found")
+	ErrAlreadyMember  = errors.New("already a room member")
+)
+
+// RoomMemberRepository handles room membership data access
+type RoomMemberRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRoomMemberRepository creates a new room member repository
+func NewRoomMemberRepository(db *mongo.Database) *RoomMemberRepository {
+	return &RoomMemberRepository{
+		collection: db.Collection("room_members"),
+	}
+}
+
+// Add adds a member to a room
+// Relies on the unique (room_id, user_id) index to reject duplicates
+func (r *RoomMemberRepository) Add(ctx context.Context, member *models.RoomMember) error {
+	member.JoinedAt = time.Now()
+	member.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, member)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrAlreadyMember
+		}
+		return err
+	}
+
+	member.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// Find finds a user's membership in a room (including bans)
+func (r *RoomMemberRepository) Find(ctx context.Context, roomID, userID primitive.ObjectID) (*models.RoomMember, error) {
+	var member models.RoomMember
+	err := r.collection.FindOne(ctx, bson.M{"room_id": roomID, "user_id": userID}).Decode(&member)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindByRoomID finds active members (or banned users) with cursor-based pagination
+// Same contract as MessageRepository.FindBySessionID: newest first, cursor is the last returned ID
+func (r *RoomMemberRepository) FindByRoomID(ctx context.Context, roomID primitive.ObjectID, banned bool, cursor string, limit int) ([]*models.RoomMember, string, error) {
+	filter := bson.M{
+		"room_id": roomID,
+		"banned":  banned,
+	}
+
+	// If cursor provided, filter members before cursor
+	if cursor != "" {
+		cursorID, err := primitive.ObjectIDFromHex(cursor)
+		if err == nil {
+			filter["_id"] = bson.M{"$lt": cursorID}
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"_id": -1}).
+		SetLimit(int64(limit + 1)) // Fetch one extra to check if there's more
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	var members []*models.RoomMember
+	if err := cur.All(ctx, &members); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(members) > limit {
+		members = members[:limit]
+		nextCursor = members[len(members)-1].ID.Hex()
+	}
+
+	return members, nextCursor, nil
+}
+
+// Remove removes an active member from a room
+// Returns ErrMemberNotFound if the user isn't an active member
+func (r *RoomMemberRepository) Remove(ctx context.Context, roomID, userID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{
+		"room_id": roomID,
+		"user_id": userID,
+		"banned":  false,
+	})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// UpdateRole changes an active member's role
+func (r *RoomMemberRepository) UpdateRole(ctx context.Context, roomID, userID primitive.ObjectID, role models.RoomRole) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"room_id": roomID, "user_id": userID, "banned": false},
+		bson.M{"$set": bson.M{"role": role, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// Ban marks a user as banned from a room, creating the document if the user never joined
+// Returns true if the user was an active member before the ban
+func (r *RoomMemberRepository) Ban(ctx context.Context, roomID, userID primitive.ObjectID, username string, bannedBy primitive.ObjectID) (bool, error) {
+	now := time.Now()
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.Before)
+
+	var before models.RoomMember
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"room_id": roomID, "user_id": userID},
+		bson.M{
+			"$set": bson.M{
+				"banned":     true,
+				"banned_by":  bannedBy,
+				"role":       models.RoleMember,
+				"updated_at": now,
+			},
+			"$unset": bson.M{"muted_until": ""},
+			"$setOnInsert": bson.M{
+				"username":  username,
+				"joined_at": now,
+			},
+		},
+		opts,
+	).Decode(&before)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil // Upserted: user was never a member
+		}
+		return false, err
+	}
+
+	return !before.Banned, nil
+}
+
+// Unban lifts a ban by removing the banned document (the user may join again)
+func (r *RoomMemberRepository) Unban(ctx context.Context, roomID, userID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{
+		"room_id": roomID,
+		"user_id": userID,
+		"banned":  true,
+	})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// SetMutedUntil mutes an active member until the given time (nil unmutes)
+func (r *RoomMemberRepository) SetMutedUntil(ctx context.Context, roomID, userID primitive.ObjectID, until *time.Time) error {
+	update := bson.M{"$set": bson.M{"muted_until": until, "updated_at": time.Now()}}
+	if until == nil {
+		update = bson.M{
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$unset": bson.M{"muted_until": ""},
+		}
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"room_id": roomID, "user_id": userID, "banned": false},
+		update,
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// DeleteByRoomID deletes all memberships (and bans) of a room
+func (r *RoomMemberRepository) DeleteByRoomID(ctx context.Context, roomID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"room_id": roomID})
+	return err
+}
@@ -84,8 +84,68 @@ func (r *RoomRepository) GetRooms(ctx context.Context, topic, zodiacFilter strin
 func (r *RoomRepository) SaveMessage(ctx context.Context, message *models.RoomMessage) error {
 	message.CreatedAt = time.Now()
 
-	_, err := r.messageCollection.InsertOne(ctx, message)
-	return err
+	result, err := r.messageCollection.InsertOne(ctx, message)
+	if err != nil {
+		return err
+	}
+
+	message.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindMessagesByRoomID finds room messages with cursor-based pagination
+// Same contract as MessageRepository.FindBySessionID: newest first, cursor is the last returned ID
+func (r *RoomRepository) FindMessagesByRoomID(ctx context.Context, roomID primitive.ObjectID, cursor string, limit int) ([]*models.RoomMessage, string, error) {
+	filter := bson.M{
+		"room_id":    roomID,
+		"created_at": bson.M{"$gt": time.Now().Add(-models.RoomMessageTTL)},
+	}
+
+	// If cursor provided, filter messages before cursor
+	if cursor != "" {
+		cursorID, err := primitive.ObjectIDFromHex(cursor)
+		if err == nil {
+			filter["_id"] = bson.M{"$lt": cursorID}
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"_id": -1}). // Newest first
+		SetLimit(int64(limit + 1))  // Fetch one extra to check if there's more
+
+	cur, err := r.messageCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	var messages []*models.RoomMessage
+	if err := cur.All(ctx, &messages); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(messages) > limit {
+		messages = messages[:limit]
+		nextCursor = messages[len(messages)-1].ID.Hex()
+	}
+
+	return messages, nextCursor, nil
+}
+
+// GetRecentMessages gets the latest room messages in chronological order (for join replay)
+func (r *RoomRepository) GetRecentMessages(ctx context.Context, roomID primitive.ObjectID, limit int) ([]*models.RoomMessage, error) {
+	messages, _, err := r.FindMessagesByRoomID(ctx, roomID, "", limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reverse to oldest first
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return messages, nil
 }
 
 // UpdateLastMessage updates room's last message
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"zodiac-ai-backend/services/chat-service/models"
+)
+
+// ContextConfig bounds the conversation history sent to the AI service
+type ContextConfig struct {
+	TokenBudget int  // Estimated tokens per request for summary + history + new message
+	MaxMessages int  // Most recent messages considered before applying the budget
+	Summarize   bool // Fold messages that drop out of the budget into ChatSession.Summary
+}
+
+// DefaultContextConfig returns the default context limits
+func DefaultContextConfig() ContextConfig {
+	return ContextConfig{
+		TokenBudget: 2000,
+		MaxMessages: 50,
+		Summarize:   true,
+	}
+}
+
+// estimateTokens roughly estimates the token count of text (~4 characters per token)
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// buildAIRequest assembles the AI request for a saved user message
+// History is the newest run of earlier messages that fits the token budget: the oldest turns
+// are dropped first, and messages already folded into the session summary are never resent.
+// Returns the dropped messages that aren't summarized yet (newest first).
+func (s *ChatService) buildAIRequest(ctx context.Context, session *models.ChatSession, userMessage *models.Message, zodiacSign string) (*models.AIChatRequest, []*models.Message, error) {
+	aiReq := &models.AIChatRequest{
+		ZodiacSign:  zodiacSign,
+		Summary:     session.Summary,
+		UserMessage: userMessage.Content,
+	}
+
+	// Messages before the user message, newest first
+	messages, _, err := s.messageRepo.FindBySessionID(ctx, session.ID, userMessage.ID.Hex(), s.contextConfig.MaxMessages)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only messages newer than the summary are candidates
+	unsummarized := len(messages)
+	for i, msg := range messages {
+		if !session.SummaryUntilID.IsZero() && msg.ID.Hex() <= session.SummaryUntilID.Hex() {
+			unsummarized = i
+			break
+		}
+	}
+	messages = messages[:unsummarized]
+
+	budget := s.contextConfig.TokenBudget - estimateTokens(session.Summary) - estimateTokens(userMessage.Content)
+	kept := 0
+	for _, msg := range messages {
+		cost := estimateTokens(msg.Content)
+		if cost > budget {
+			break
+		}
+		budget -= cost
+		kept++
+	}
+
+	// Back to chronological order
+	aiReq.History = make([]models.ChatTurn, 0, kept)
+	for i := kept - 1; i >= 0; i-- {
+		aiReq.History = append(aiReq.History, toChatTurn(messages[i]))
+	}
+
+	return aiReq, messages[kept:], nil
+}
+
+// toChatTurn converts a stored message to an AI conversation turn
+func toChatTurn(msg *models.Message) models.ChatTurn {
+	role := models.TurnRoleUser
+	if msg.Sender == models.SenderAI {
+		role = models.TurnRoleModel
+	}
+	return models.ChatTurn{Role: role, Text: msg.Content}
+}
+
+// maybeSummarize folds dropped messages into the session's rolling summary in the background
+// The reply doesn't wait for it; the next request picks the new summary up
+func (s *ChatService) maybeSummarize(session *models.ChatSession, dropped []*models.Message) {
+	if !s.contextConfig.Summarize || len(dropped) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		// dropped is newest first
+		turns := make([]models.ChatTurn, 0, len(dropped))
+		for i := len(dropped) - 1; i >= 0; i-- {
+			turns = append(turns, toChatTurn(dropped[i]))
+		}
+
+		summary, err := s.callAISummarizeService(ctx, session.Summary, turns)
+		if err != nil {
+			log.Printf("⚠️ Failed to summarize session %s: %v", session.ID.Hex(), err)
+			return
+		}
+
+		if err := s.sessionRepo.UpdateSummary(ctx, session.ID, session.SummaryUntilID, dropped[0].ID, summary); err != nil {
+			log.Printf("⚠️ Failed to save summary for session %s: %v", session.ID.Hex(), err)
+		}
+	}()
+}
+
+// callAISummarizeService calls AI service to update a rolling summary
+func (s *ChatService) callAISummarizeService(ctx context.Context, previousSummary string, turns []models.ChatTurn) (string, error) {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"summary": previousSummary,
+		"turns":   turns,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.aiServiceURL+"/api/v1/ai/summarize", bytes.NewReader(jsonData))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 35 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", ErrAIServiceDown
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("AI service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+		Data    struct {
+			Summary string `json:"summary"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	if !result.Success || result.Data.Summary == "" {
+		return "", ErrAIServiceDown
+	}
+
+	return result.Data.Summary, nil
+}
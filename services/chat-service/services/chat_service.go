@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"zodiac-ai-backend/pkg/sse"
 	"zodiac-ai-backend/services/chat-service/models"
 	"zodiac-ai-backend/services/chat-service/repositories"
 
@@ -26,7 +27,9 @@ var (
 type ChatService struct {
 	sessionRepo  *repositories.ChatSessionRepository
 	messageRepo  *repositories.MessageRepository
-	aiServiceURL string
+	aiServiceURL  string
+	streamClient  *http.Client
+	contextConfig ContextConfig
 }
 
 // NewChatService creates a new chat service
@@ -34,14 +37,33 @@ func NewChatService(
 	sessionRepo *repositories.ChatSessionRepository,
 	messageRepo *repositories.MessageRepository,
 	aiServiceURL string,
+	contextConfig ContextConfig,
 ) *ChatService {
+	defaults := DefaultContextConfig()
+	if contextConfig.TokenBudget <= 0 {
+		contextConfig.TokenBudget = defaults.TokenBudget
+	}
+	if contextConfig.MaxMessages <= 0 {
+		contextConfig.MaxMessages = defaults.MaxMessages
+	}
+
 	return &ChatService{
-		sessionRepo:  sessionRepo,
-		messageRepo:  messageRepo,
-		aiServiceURL: aiServiceURL,
+		sessionRepo:   sessionRepo,
+		messageRepo:   messageRepo,
+		aiServiceURL:  aiServiceURL,
+		streamClient:  newStreamClient(),
+		contextConfig: contextConfig,
 	}
 }
 
+// newStreamClient creates the HTTP client for AI streams
+// No overall timeout (it would cut long streams): only waiting for the response headers is timed
+func newStreamClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 35 * time.Second
+	return &http.Client{Transport: transport}
+}
+
 // CreateSession creates a new chat session
 func (s *ChatService) CreateSession(ctx context.Context, userID, title string) (*models.ChatSession, error) {
 	userObjID, err := primitive.ObjectIDFromHex(userID)
@@ -90,12 +112,20 @@ func (s *ChatService) SendMessage(ctx context.Context, sessionID, userID, zodiac
 		return nil, err
 	}
 
+	// Send recent history along so the AI remembers the conversation
+	aiReq, dropped, err := s.buildAIRequest(ctx, session, userMessage, zodiacSign)
+	if err != nil {
+		return nil, err
+	}
+
 	// Call AI service to get response
-	aiResponse, err := s.callAIService(ctx, zodiacSign, message)
+	aiResponse, err := s.callAIService(ctx, aiReq)
 	if err != nil {
 		return nil, err
 	}
 
+	s.maybeSummarize(session, dropped)
+
 	// Save AI message
 	aiMessage := &models.Message{
 		SessionID: sessionObjID,
@@ -114,6 +144,86 @@ func (s *ChatService) SendMessage(ctx context.Context, sessionID, userID, zodiac
 	}, nil
 }
 
+// StartStream verifies the session and saves the user message for a streamed AI reply
+func (s *ChatService) StartStream(ctx context.Context, sessionID, userID, message string) (*models.Message, error) {
+	sessionObjID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify session exists and belongs to user
+	session, err := s.sessionRepo.FindByID(ctx, sessionObjID)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	if session.UserID != userObjID {
+		return nil, errors.New("unauthorized access to session")
+	}
+
+	// Save user message
+	userMessage := &models.Message{
+		SessionID: sessionObjID,
+		UserID:    userObjID,
+		Sender:    models.SenderUser,
+		Content:   message,
+	}
+
+	if err := s.messageRepo.Create(ctx, userMessage); err != nil {
+		return nil, err
+	}
+
+	return userMessage, nil
+}
+
+// StreamAIResponse streams the AI reply to a user message, forwarding each chunk to onChunk
+// The assembled AI message is saved when the stream completes. If onChunk fails (client went
+// away) or the stream breaks after some text arrived, what was received is saved as partial.
+func (s *ChatService) StreamAIResponse(ctx context.Context, userMessage *models.Message, zodiacSign string, onChunk func(string) error) (*models.Message, error) {
+	session, err := s.sessionRepo.FindByID(ctx, userMessage.SessionID)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	aiReq, dropped, err := s.buildAIRequest(ctx, session, userMessage, zodiacSign)
+	if err != nil {
+		return nil, err
+	}
+
+	content, complete, streamErr := s.callAIServiceStream(ctx, aiReq, onChunk)
+	if content == "" {
+		if streamErr == nil {
+			streamErr = ErrAIServiceDown
+		}
+		return nil, streamErr
+	}
+
+	aiMessage := &models.Message{
+		SessionID: userMessage.SessionID,
+		UserID:    userMessage.UserID,
+		Sender:    models.SenderAI,
+		Content:   content,
+		Partial:   !complete,
+	}
+
+	// ctx may already be cancelled by the client disconnecting
+	saveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.messageRepo.Create(saveCtx, aiMessage); err != nil {
+		return nil, err
+	}
+
+	s.maybeSummarize(session, dropped)
+
+	return aiMessage, streamErr
+}
+
 // GetMessages gets chat history with cursor-based pagination
 func (s *ChatService) GetMessages(ctx context.Context, sessionID, userID, cursor string, limit int) ([]*models.Message, string, error) {
 	sessionObjID, err := primitive.ObjectIDFromHex(sessionID)
@@ -200,15 +310,10 @@ func (s *ChatService) GetUserSessions(ctx context.Context, userID string) ([]*mo
 }
 
 // callAIService calls AI service to get chat response
-func (s *ChatService) callAIService(ctx context.Context, zodiacSign, userMessage string) (string, error) {
-	log.Printf("📞 Calling AI service at %s with zodiac: %s", s.aiServiceURL, zodiacSign)
+func (s *ChatService) callAIService(ctx context.Context, aiReq *models.AIChatRequest) (string, error) {
+	log.Printf("📞 Calling AI service at %s with zodiac: %s, history: %d turns", s.aiServiceURL, aiReq.ZodiacSign, len(aiReq.History))
 	
-	reqBody := map[string]string{
-		"zodiac_sign":  zodiacSign,
-		"user_message": userMessage,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
+	jsonData, err := json.Marshal(aiReq)
 	if err != nil {
 		log.Printf("❌ Failed to marshal request: %v", err)
 		return "", err
@@ -265,6 +370,75 @@ func (s *ChatService) callAIService(ctx context.Context, zodiacSign, userMessage
 	return result.Data.Response, nil
 }
 
+// callAIServiceStream calls the AI service streaming endpoint and forwards chunks to onChunk
+// Returns the text received so far, whether the stream completed, and the error that stopped it
+func (s *ChatService) callAIServiceStream(ctx context.Context, aiReq *models.AIChatRequest, onChunk func(string) error) (string, bool, error) {
+	log.Printf("📞 Streaming from AI service at %s with zodiac: %s, history: %d turns", s.aiServiceURL, aiReq.ZodiacSign, len(aiReq.History))
+
+	jsonData, err := json.Marshal(aiReq)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Cancelling stops the upstream stream as soon as the client goes away
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.aiServiceURL+"/api/v1/ai/chat/stream", strings.NewReader(string(jsonData)))
+	if err != nil {
+		return "", false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := s.streamClient.Do(req)
+	if err != nil {
+		log.Printf("❌ AI service stream request failed: %v", err)
+		return "", false, ErrAIServiceDown
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || !sse.IsEventStream(resp.Header.Get("Content-Type")) {
+		body, _ := io.ReadAll(resp.Body)
+		log.Printf("❌ AI service stream returned status %d: %s", resp.StatusCode, string(body))
+		return "", false, ErrAIServiceDown
+	}
+
+	var content strings.Builder
+	reader := sse.NewReader(resp.Body)
+
+	for {
+		event, err := reader.Next()
+		if err != nil {
+			// Stream ended without a done event
+			log.Printf("❌ AI service stream broke after %d chars: %v", content.Len(), err)
+			return content.String(), false, ErrAIServiceDown
+		}
+
+		switch event.Name {
+		case sse.EventChunk:
+			var chunk sse.Chunk
+			if err := event.Decode(&chunk); err != nil {
+				return content.String(), false, err
+			}
+
+			content.WriteString(chunk.Delta)
+			if err := onChunk(chunk.Delta); err != nil {
+				return content.String(), false, err
+			}
+
+		case sse.EventDone:
+			log.Printf("✅ AI stream completed: %d chars", content.Len())
+			return content.String(), true, nil
+
+		case sse.EventError:
+			log.Printf("❌ AI service stream error event: %s", event.Data)
+			return content.String(), false, ErrAIServiceDown
+		}
+	}
+}
+
 // callAIInsightService calls AI service to generate insight
 func (s *ChatService) callAIInsightService(ctx context.Context, chatHistory string) (string, error) {
 	reqBody := map[string]string{
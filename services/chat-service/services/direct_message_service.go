@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"zodiac-ai-backend/pkg/validator"
+	authRepos "zodiac-ai-backend/services/auth-service/repositories"
+	"zodiac-ai-backend/services/chat-service/models"
+	"zodiac-ai-backend/services/chat-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrConversationNotFound = errors.New("conversation not found")
+	ErrNotFriends           = errors.New("users are not friends")
+	ErrConversationReadOnly = errors.New("conversation is read-only")
+	ErrEmptyMessage         = errors.New("message content is required")
+)
+
+// DirectMessageService handles 1:1 conversations between friends
+type DirectMessageService struct {
+	conversationRepo *repositories.ConversationRepository
+	friendshipRepo   *authRepos.FriendshipRepository
+	userRepo         *authRepos.UserRepository
+}
+
+// NewDirectMessageService creates a new direct message service
+func NewDirectMessageService(
+	conversationRepo *repositories.ConversationRepository,
+	friendshipRepo *authRepos.FriendshipRepository,
+	userRepo *authRepos.UserRepository,
+) *DirectMessageService {
+	return &DirectMessageService{
+		conversationRepo: conversationRepo,
+		friendshipRepo:   friendshipRepo,
+		userRepo:         userRepo,
+	}
+}
+
+// StartConversation gets or creates the conversation with a friend
+func (s *DirectMessageService) StartConversation(ctx context.Context, userID string, req *models.StartConversationRequest) (*models.ConversationResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	friendObjID, err := primitive.ObjectIDFromHex(req.FriendID)
+	if err != nil || friendObjID == userObjID {
+		return nil, ErrNotFriends
+	}
+
+	areFriends, err := s.friendshipRepo.CheckFriendship(ctx, userObjID, friendObjID)
+	if err != nil {
+		return nil, err
+	}
+	if !areFriends {
+		return nil, ErrNotFriends
+	}
+
+	conversation, err := s.conversationRepo.GetOrCreate(ctx, userObjID, friendObjID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := s.toResponses(ctx, userObjID, []*models.Conversation{conversation})
+	if err != nil {
+		return nil, err
+	}
+	return responses[0], nil
+}
+
+// GetConversations gets a user's conversations with pagination
+func (s *DirectMessageService) GetConversations(ctx context.Context, userID, cursor string, limit int) ([]*models.ConversationResponse, string, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	conversations, nextCursor, err := s.conversationRepo.FindByParticipant(ctx, userObjID, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	responses, err := s.toResponses(ctx, userObjID, conversations)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return responses, nextCursor, nil
+}
+
+// GetMessages gets conversation history with pagination
+// Former friends keep read access to their history
+func (s *DirectMessageService) GetMessages(ctx context.Context, conversationID, userID, cursor string, limit int) ([]*models.DirectMessage, string, error) {
+	conversation, _, err := s.findConversation(ctx, conversationID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.conversationRepo.FindMessages(ctx, conversation.ID, cursor, limit)
+}
+
+// SendMessage sends a message to a friend
+// Returns the stored message and the recipient's ID for real-time delivery
+func (s *DirectMessageService) SendMessage(ctx context.Context, conversationID, senderID string, req *models.SendDirectMessageRequest) (*models.DirectMessage, string, error) {
+	req.Content = strings.TrimSpace(req.Content)
+	if req.Content == "" {
+		return nil, "", ErrEmptyMessage
+	}
+	if err := validator.Validate(req); err != nil {
+		return nil, "", err
+	}
+
+	conversation, senderObjID, err := s.findConversation(ctx, conversationID, senderID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Unfriending makes the thread read-only
+	recipientID := conversation.OtherParticipant(senderObjID)
+	areFriends, err := s.friendshipRepo.CheckFriendship(ctx, senderObjID, recipientID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !areFriends {
+		return nil, "", ErrConversationReadOnly
+	}
+
+	message := &models.DirectMessage{
+		ConversationID: conversation.ID,
+		SenderID:       senderObjID,
+		Content:        req.Content,
+	}
+	if err := s.conversationRepo.SaveMessage(ctx, message, recipientID); err != nil {
+		return nil, "", err
+	}
+
+	return message, recipientID.Hex(), nil
+}
+
+// MarkRead resets the user's unread count for a conversation
+func (s *DirectMessageService) MarkRead(ctx context.Context, conversationID, userID string) error {
+	conversation, userObjID, err := s.findConversation(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.conversationRepo.MarkRead(ctx, conversation.ID, userObjID)
+}
+
+// findConversation loads a conversation the user takes part in
+// Non-participants get ErrConversationNotFound so thread IDs can't be probed
+func (s *DirectMessageService) findConversation(ctx context.Context, conversationID, userID string) (*models.Conversation, primitive.ObjectID, error) {
+	convObjID, err := primitive.ObjectIDFromHex(conversationID)
+	if err != nil {
+		return nil, primitive.NilObjectID, ErrConversationNotFound
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	conversation, err := s.conversationRepo.FindByID(ctx, convObjID)
+	if err != nil {
+		if err == repositories.ErrConversationNotFound {
+			return nil, primitive.NilObjectID, ErrConversationNotFound
+		}
+		return nil, primitive.NilObjectID, err
+	}
+
+	if !conversation.HasParticipant(userObjID) {
+		return nil, primitive.NilObjectID, ErrConversationNotFound
+	}
+
+	return conversation, userObjID, nil
+}
+
+// toResponses resolves the other participant and friendship state for each conversation
+// Uses one friend list and one batched user lookup instead of a query per conversation
+func (s *DirectMessageService) toResponses(ctx context.Context, userID primitive.ObjectID, conversations []*models.Conversation) ([]*models.ConversationResponse, error) {
+	friendIDs, err := s.friendshipRepo.GetFriends(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	friends := make(map[primitive.ObjectID]bool, len(friendIDs))
+	for _, id := range friendIDs {
+		friends[id] = true
+	}
+
+	otherIDs := make([]primitive.ObjectID, 0, len(conversations))
+	for _, conversation := range conversations {
+		otherIDs = append(otherIDs, conversation.OtherParticipant(userID))
+	}
+
+	users, err := s.userRepo.FindByIDs(ctx, otherIDs)
+	if err != nil {
+		return nil, err
+	}
+	participants := make(map[primitive.ObjectID]*models.ConversationParticipant, len(users))
+	for _, user := range users {
+		participants[user.ID] = &models.ConversationParticipant{
+			UserID:     user.ID.Hex(),
+			Username:   displayName(user.DisplayName, user.FullName),
+			ZodiacSign: user.ZodiacSign,
+		}
+	}
+
+	responses := make([]*models.ConversationResponse, 0, len(conversations))
+	for _, conversation := range conversations {
+		otherID := conversation.OtherParticipant(userID)
+
+		friend, ok := participants[otherID]
+		if !ok {
+			// Deleted account: keep the thread readable
+			friend = &models.ConversationParticipant{UserID: otherID.Hex()}
+		}
+
+		responses = append(responses, &models.ConversationResponse{
+			ID:          conversation.ID,
+			Friend:      friend,
+			LastMessage: conversation.LastMessage,
+			UnreadCount: conversation.UnreadCounts[userID.Hex()],
+			ReadOnly:    !friends[otherID],
+			UpdatedAt:   conversation.UpdatedAt,
+		})
+	}
+
+	return responses, nil
+}
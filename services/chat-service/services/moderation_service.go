@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	authModels "zodiac-ai-backend/services/auth-service/models"
+	authRepos "zodiac-ai-backend/services/auth-service/repositories"
+)
+
+// ModerationService handles removal of rooms by admins and moderators
+// Removals are written to the shared audit log under the room creator's user ID
+type ModerationService struct {
+	roomService *RoomService
+	auditRepo   *authRepos.AuditLogRepository
+}
+
+// NewModerationService creates a new moderation service
+func NewModerationService(roomService *RoomService, auditRepo *authRepos.AuditLogRepository) *ModerationService {
+	return &ModerationService{
+		roomService: roomService,
+		auditRepo:   auditRepo,
+	}
+}
+
+// DeleteRoom deletes a room and its memberships
+// The caller disconnects the room's clients
+func (s *ModerationService) DeleteRoom(ctx context.Context, adminID, roomID string) error {
+	room, err := s.roomService.RemoveRoom(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	// The removal itself already applies; a failed audit write is only logged
+	if err := s.auditRepo.Create(ctx, &authModels.AuditLog{
+		Event:  authModels.AuditRoomDeleted,
+		UserID: &room.CreatorID,
+		Details: map[string]interface{}{
+			"admin_id": adminID,
+			"room_id":  room.ID.Hex(),
+			"name":     room.Name,
+		},
+	}); err != nil {
+		log.Printf("⚠️ Failed to write %s audit log: %v", authModels.AuditRoomDeleted, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,500 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"zodiac-ai-backend/pkg/validator"
+	authRepos "zodiac-ai-backend/services/auth-service/repositories"
+	"zodiac-ai-backend/services/chat-service/models"
+	"zodiac-ai-backend/services/chat-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrRoomNotFound       = errors.New("room not found")
+	ErrNotRoomMember      = errors.New("not a room member")
+	ErrAlreadyRoomMember  = errors.New("already a room member")
+	ErrBannedFromRoom     = errors.New("banned from room")
+	ErrNotBanned          = errors.New("user is not banned")
+	ErrZodiacRestricted   = errors.New("room is restricted to another zodiac sign")
+	ErrInsufficientRole   = errors.New("insufficient room role")
+	ErrOwnerCannotLeave   = errors.New("room owner cannot leave")
+	ErrInvalidRoomRole    = errors.New("invalid room role")
+	ErrCannotModerateSelf = errors.New("cannot moderate yourself")
+)
+
+// RoomService handles room membership and moderation business logic
+type RoomService struct {
+	roomRepo   *repositories.RoomRepository
+	memberRepo *repositories.RoomMemberRepository
+	userRepo   *authRepos.UserRepository
+}
+
+// NewRoomService creates a new room service
+func NewRoomService(
+	roomRepo *repositories.RoomRepository,
+	memberRepo *repositories.RoomMemberRepository,
+	userRepo *authRepos.UserRepository,
+) *RoomService {
+	return &RoomService{
+		roomRepo:   roomRepo,
+		memberRepo: memberRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// CreateRoom creates a room and makes the creator its owner
+func (s *RoomService) CreateRoom(ctx context.Context, creatorID string, req *models.CreateRoomRequest) (*models.Room, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	creatorObjID, err := primitive.ObjectIDFromHex(creatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	username, err := s.username(ctx, creatorObjID)
+	if err != nil {
+		return nil, err
+	}
+
+	room := &models.Room{
+		Name:         req.Name,
+		Topic:        req.Topic,
+		ZodiacFilter: req.ZodiacFilter,
+		CreatorID:    creatorObjID,
+	}
+
+	if err := s.roomRepo.Create(ctx, room); err != nil {
+		return nil, err
+	}
+
+	owner := &models.RoomMember{
+		RoomID:   room.ID,
+		UserID:   creatorObjID,
+		Username: username,
+		Role:     models.RoleOwner,
+	}
+	if err := s.memberRepo.Add(ctx, owner); err != nil {
+		// Don't leave an ownerless room behind
+		_ = s.roomRepo.Delete(ctx, room.ID)
+		return nil, err
+	}
+
+	if err := s.roomRepo.IncrementMemberCount(ctx, room.ID, 1); err != nil {
+		return nil, err
+	}
+	room.MemberCount = 1
+
+	return room, nil
+}
+
+// Membership returns a user's active membership in a room
+// Creators of rooms made before memberships existed are treated as owners
+func (s *RoomService) Membership(ctx context.Context, room *models.Room, userID primitive.ObjectID) (*models.RoomMember, error) {
+	member, err := s.memberRepo.Find(ctx, room.ID, userID)
+	if err != nil {
+		if err != repositories.ErrMemberNotFound {
+			return nil, err
+		}
+		if room.CreatorID == userID {
+			return &models.RoomMember{RoomID: room.ID, UserID: userID, Role: models.RoleOwner}, nil
+		}
+		return nil, ErrNotRoomMember
+	}
+
+	if member.Banned {
+		return nil, ErrBannedFromRoom
+	}
+	return member, nil
+}
+
+// JoinRoom adds a user to a room
+func (s *RoomService) JoinRoom(ctx context.Context, roomID, userID string) (*models.RoomMember, error) {
+	room, err := s.findRoom(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userObjID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check against the stored sign (token claims may be stale)
+	if room.ZodiacFilter != "" && !strings.EqualFold(room.ZodiacFilter, user.ZodiacSign) {
+		return nil, ErrZodiacRestricted
+	}
+
+	existing, err := s.memberRepo.Find(ctx, room.ID, userObjID)
+	if err == nil {
+		if existing.Banned {
+			return nil, ErrBannedFromRoom
+		}
+		return nil, ErrAlreadyRoomMember
+	}
+	if err != repositories.ErrMemberNotFound {
+		return nil, err
+	}
+
+	role := models.RoleMember
+	if room.CreatorID == userObjID {
+		role = models.RoleOwner
+	}
+
+	member := &models.RoomMember{
+		RoomID:   room.ID,
+		UserID:   userObjID,
+		Username: displayName(user.DisplayName, user.FullName),
+		Role:     role,
+	}
+	if err := s.memberRepo.Add(ctx, member); err != nil {
+		if err == repositories.ErrAlreadyMember {
+			return nil, ErrAlreadyRoomMember
+		}
+		return nil, err
+	}
+
+	if err := s.roomRepo.IncrementMemberCount(ctx, room.ID, 1); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// LeaveRoom removes a user from a room
+// The owner can't leave; they delete the room instead
+func (s *RoomService) LeaveRoom(ctx context.Context, roomID, userID string) error {
+	room, err := s.findRoom(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.Membership(ctx, room, userObjID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.RoleOwner {
+		return ErrOwnerCannotLeave
+	}
+
+	if err := s.memberRepo.Remove(ctx, room.ID, userObjID); err != nil {
+		if err == repositories.ErrMemberNotFound {
+			return ErrNotRoomMember
+		}
+		return err
+	}
+
+	return s.roomRepo.IncrementMemberCount(ctx, room.ID, -1)
+}
+
+// GetMembers gets room members with pagination
+// Only members can list members; only moderators can list bans
+func (s *RoomService) GetMembers(ctx context.Context, roomID, userID string, banned bool, cursor string, limit int) ([]*models.RoomMember, string, error) {
+	room, err := s.findRoom(ctx, roomID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	member, err := s.Membership(ctx, room, userObjID)
+	if err != nil {
+		return nil, "", err
+	}
+	if banned && !member.Role.CanModerate() {
+		return nil, "", ErrInsufficientRole
+	}
+
+	return s.memberRepo.FindByRoomID(ctx, room.ID, banned, cursor, limit)
+}
+
+// KickMember removes a member from a room (they may join again)
+func (s *RoomService) KickMember(ctx context.Context, roomID, actorID, targetID string) error {
+	room, target, err := s.authorizeModeration(ctx, roomID, actorID, targetID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrNotRoomMember
+	}
+
+	if err := s.memberRepo.Remove(ctx, room.ID, target.UserID); err != nil {
+		if err == repositories.ErrMemberNotFound {
+			return ErrNotRoomMember
+		}
+		return err
+	}
+
+	return s.roomRepo.IncrementMemberCount(ctx, room.ID, -1)
+}
+
+// BanMember removes a user from a room and prevents them from joining again
+// Users who never joined can be banned pre-emptively
+func (s *RoomService) BanMember(ctx context.Context, roomID, actorID, targetID string) error {
+	room, target, err := s.authorizeModeration(ctx, roomID, actorID, targetID)
+	if err != nil {
+		return err
+	}
+
+	actorObjID, _ := primitive.ObjectIDFromHex(actorID)
+	targetObjID, _ := primitive.ObjectIDFromHex(targetID)
+
+	var username string
+	if target != nil {
+		username = target.Username
+	} else {
+		if username, err = s.username(ctx, targetObjID); err != nil {
+			return err
+		}
+	}
+
+	wasMember, err := s.memberRepo.Ban(ctx, room.ID, targetObjID, username, actorObjID)
+	if err != nil {
+		return err
+	}
+
+	if wasMember {
+		return s.roomRepo.IncrementMemberCount(ctx, room.ID, -1)
+	}
+	return nil
+}
+
+// UnbanMember lifts a ban
+func (s *RoomService) UnbanMember(ctx context.Context, roomID, actorID, targetID string) error {
+	room, _, err := s.authorizeModeration(ctx, roomID, actorID, targetID)
+	if err != nil {
+		return err
+	}
+
+	targetObjID, _ := primitive.ObjectIDFromHex(targetID)
+	if err := s.memberRepo.Unban(ctx, room.ID, targetObjID); err != nil {
+		if err == repositories.ErrMemberNotFound {
+			return ErrNotBanned
+		}
+		return err
+	}
+	return nil
+}
+
+// MuteMember prevents a member from sending messages for a duration
+// Returns when the mute ends
+func (s *RoomService) MuteMember(ctx context.Context, roomID, actorID, targetID string, req *models.MuteMemberRequest) (time.Time, error) {
+	if err := validator.Validate(req); err != nil {
+		return time.Time{}, err
+	}
+
+	room, target, err := s.authorizeModeration(ctx, roomID, actorID, targetID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if target == nil {
+		return time.Time{}, ErrNotRoomMember
+	}
+
+	until := time.Now().Add(time.Duration(req.DurationMinutes) * time.Minute)
+	if err := s.memberRepo.SetMutedUntil(ctx, room.ID, target.UserID, &until); err != nil {
+		if err == repositories.ErrMemberNotFound {
+			return time.Time{}, ErrNotRoomMember
+		}
+		return time.Time{}, err
+	}
+
+	return until, nil
+}
+
+// UnmuteMember lifts a mute
+func (s *RoomService) UnmuteMember(ctx context.Context, roomID, actorID, targetID string) error {
+	room, target, err := s.authorizeModeration(ctx, roomID, actorID, targetID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrNotRoomMember
+	}
+
+	if err := s.memberRepo.SetMutedUntil(ctx, room.ID, target.UserID, nil); err != nil {
+		if err == repositories.ErrMemberNotFound {
+			return ErrNotRoomMember
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateMemberRole promotes or demotes a member (owner only)
+func (s *RoomService) UpdateMemberRole(ctx context.Context, roomID, actorID, targetID string, req *models.UpdateMemberRoleRequest) error {
+	if req.Role != models.RoleModerator && req.Role != models.RoleMember {
+		return ErrInvalidRoomRole
+	}
+
+	room, target, err := s.authorizeModeration(ctx, roomID, actorID, targetID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrNotRoomMember
+	}
+
+	actorObjID, _ := primitive.ObjectIDFromHex(actorID)
+	actor, err := s.Membership(ctx, room, actorObjID)
+	if err != nil {
+		return err
+	}
+	if actor.Role != models.RoleOwner {
+		return ErrInsufficientRole
+	}
+
+	if err := s.memberRepo.UpdateRole(ctx, room.ID, target.UserID, req.Role); err != nil {
+		if err == repositories.ErrMemberNotFound {
+			return ErrNotRoomMember
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteRoom deletes a room with its members and messages (owner only)
+func (s *RoomService) DeleteRoom(ctx context.Context, roomID, userID string) error {
+	room, err := s.findRoom(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.Membership(ctx, room, userObjID)
+	if err != nil {
+		if err == ErrNotRoomMember || err == ErrBannedFromRoom {
+			return ErrInsufficientRole
+		}
+		return err
+	}
+	if member.Role != models.RoleOwner {
+		return ErrInsufficientRole
+	}
+
+	return s.deleteRoom(ctx, room)
+}
+
+// RemoveRoom deletes a room regardless of membership (admin moderation)
+// Returns the deleted room
+func (s *RoomService) RemoveRoom(ctx context.Context, roomID string) (*models.Room, error) {
+	room, err := s.findRoom(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.deleteRoom(ctx, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// deleteRoom deletes a room and its memberships
+func (s *RoomService) deleteRoom(ctx context.Context, room *models.Room) error {
+	// The room document goes last, so a failed delete can simply be retried
+	if err := s.memberRepo.DeleteByRoomID(ctx, room.ID); err != nil {
+		return err
+	}
+	return s.roomRepo.Delete(ctx, room.ID)
+}
+
+// authorizeModeration checks that the actor outranks the target in a room
+// Returns the target's membership, or nil if the target has none (never joined or banned)
+func (s *RoomService) authorizeModeration(ctx context.Context, roomID, actorID, targetID string) (*models.Room, *models.RoomMember, error) {
+	room, err := s.findRoom(ctx, roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	actorObjID, err := primitive.ObjectIDFromHex(actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targetObjID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return nil, nil, ErrNotRoomMember
+	}
+
+	if actorObjID == targetObjID {
+		return nil, nil, ErrCannotModerateSelf
+	}
+
+	actor, err := s.Membership(ctx, room, actorObjID)
+	if err != nil {
+		if err == ErrNotRoomMember || err == ErrBannedFromRoom {
+			return nil, nil, ErrInsufficientRole
+		}
+		return nil, nil, err
+	}
+	if !actor.Role.CanModerate() {
+		return nil, nil, ErrInsufficientRole
+	}
+
+	target, err := s.Membership(ctx, room, targetObjID)
+	if err != nil {
+		if err == ErrNotRoomMember || err == ErrBannedFromRoom {
+			return room, nil, nil
+		}
+		return nil, nil, err
+	}
+	if target.Role.Rank() >= actor.Role.Rank() {
+		return nil, nil, ErrInsufficientRole
+	}
+
+	return room, target, nil
+}
+
+// findRoom finds a room by hex ID
+func (s *RoomService) findRoom(ctx context.Context, roomID string) (*models.Room, error) {
+	roomObjID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return nil, ErrRoomNotFound
+	}
+
+	room, err := s.roomRepo.FindByID(ctx, roomObjID)
+	if err != nil {
+		return nil, ErrRoomNotFound
+	}
+	return room, nil
+}
+
+// username gets a user's display name
+func (s *RoomService) username(ctx context.Context, userID primitive.ObjectID) (string, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return displayName(user.DisplayName, user.FullName), nil
+}
+
+// displayName prefers the display name, falling back to the full name
+func displayName(displayName, fullName string) string {
+	if displayName != "" {
+		return displayName
+	}
+	return fullName
+}
@@ -0,0 +1,60 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"zodiac-ai-backend/services/chat-service/models"
+)
+
+// Room control actions applied to live connections on every replica
+const (
+	ControlKick   = "kick"   // Close the user's connections (they may rejoin)
+	ControlBan    = "ban"    // Close the user's connections (they can't rejoin)
+	ControlLeave  = "leave"  // User left the room from another client
+	ControlMute   = "mute"   // Update the user's mute (nil MutedUntil unmutes)
+	ControlDelete = "delete" // Room deleted: close every connection
+)
+
+// RoomControl is a moderation action for live connections in a room
+type RoomControl struct {
+	Action     string     `bson:"action" json:"action"`
+	UserID     string     `bson:"user_id,omitempty" json:"user_id,omitempty"` // Empty targets every client
+	MutedUntil *time.Time `bson:"muted_until,omitempty" json:"muted_until,omitempty"`
+}
+
+// Envelope is a room frame travelling between hub replicas
+// Carries either a Message for clients or a Control for the hubs themselves
+type Envelope struct {
+	NodeID  string                   `bson:"node_id" json:"node_id"` // Hub that produced the frame
+	RoomID  string                   `bson:"room_id" json:"room_id"`
+	Message *models.WebSocketMessage `bson:"message,omitempty" json:"message,omitempty"`
+	Control *RoomControl             `bson:"control,omitempty" json:"control,omitempty"`
+
+	// Persisted marks frames already stored in room_messages.
+	// Backplanes that replicate through that collection don't publish them again.
+	Persisted bool `bson:"-" json:"-"`
+}
+
+// EnvelopeHandler receives envelopes for a subscribed room
+type EnvelopeHandler func(env *Envelope)
+
+// Backplane fans out room frames and presence counts between hub replicas
+// Reference: DDIA Ch. 11 - Message brokers decouple producers from consumers
+type Backplane interface {
+	// Publish sends an envelope to every hub subscribed to its room (including the sender)
+	Publish(ctx context.Context, env *Envelope) error
+
+	// Subscribe registers a handler for a room and returns a function that removes it
+	Subscribe(roomID string, handler EnvelopeHandler) (unsubscribe func(), err error)
+
+	// SetPresence records the clients a node holds in a room (empty removes the entry)
+	SetPresence(ctx context.Context, nodeID, roomID string, members []models.PresenceMember) error
+
+	// ListPresence returns connected clients for a room across all live nodes
+	// (one entry per connection, so a user may appear more than once)
+	ListPresence(ctx context.Context, roomID string) ([]models.PresenceMember, error)
+
+	// Close releases backplane resources
+	Close() error
+}
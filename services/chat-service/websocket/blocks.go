@@ -0,0 +1,54 @@
+package websocket
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// BlockLookup returns, for each of userIDs, the users hidden from them (blocked either way)
+type BlockLookup func(ctx context.Context, userIDs []string) (map[string][]string, error)
+
+// WatchBlocks refreshes the hidden users of every connection every interval, so blocks
+// made or lifted while a user is connected apply without reconnecting.
+// Each replica checks only its own connections, so no backplane traffic is involved.
+// Runs until the process exits, like the hub itself.
+func (h *Hub) WatchBlocks(lookup BlockLookup, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		userIDs := h.connectedUsers()
+		if len(userIDs) == 0 {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		hidden, err := lookup(ctx, userIDs)
+		cancel()
+		if err != nil {
+			log.Printf("⚠️ Failed to refresh blocked users: %v", err)
+			continue
+		}
+
+		checked := make(map[string]bool, len(userIDs))
+		for _, userID := range userIDs {
+			checked[userID] = true
+		}
+
+		// Clients that joined since connectedUsers keep the list loaded when they connected
+		h.mu.RLock()
+		for _, clients := range h.rooms {
+			for client := range clients {
+				if checked[client.UserID] {
+					client.SetHiddenUsers(hidden[client.UserID])
+				}
+			}
+		}
+		h.mu.RUnlock()
+	}
+}
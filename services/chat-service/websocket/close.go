@@ -0,0 +1,57 @@
+package websocket
+
+import (
+	"log"
+	"time"
+
+	"github.com/gofiber/websocket/v2"
+)
+
+// Application close codes (RFC 6455 reserves 4000-4999 for applications)
+// Clients can branch on the code instead of parsing the reason text
+const (
+	CloseUnauthorized  = 4001 // Missing, invalid or expired token
+	CloseForbidden     = 4003 // Authenticated but not allowed in the room (e.g. zodiac filter, account suspended)
+	CloseRoomNotFound  = 4004 // Room doesn't exist
+	CloseSlowConsumer  = 4008 // Client didn't read frames fast enough
+	CloseInternalError = 4500 // Server failed while authorizing the join
+)
+
+// Reasons a connected client was dropped by the server (reported by Hub.Stats)
+const (
+	DropSlowConsumer    = "slow_consumer"     // Send buffer full
+	DropPongTimeout     = "pong_timeout"      // No pong or frame within PongWait
+	DropMessageTooLarge = "message_too_large" // Frame larger than MaxMessageSize
+	DropWriteFailed     = "write_failed"      // Write error or WriteWait exceeded
+)
+
+// controlCloseReason maps a room control action to the close frame sent to affected clients
+func controlCloseReason(action string) (int, string) {
+	switch action {
+	case ControlKick:
+		return CloseForbidden, "kicked from room"
+	case ControlBan:
+		return CloseForbidden, "banned from room"
+	case ControlDelete:
+		return CloseRoomNotFound, "room deleted"
+	}
+	return websocket.CloseNormalClosure, "left room"
+}
+
+// closeWriteWait bounds writing the close frame to a peer
+const closeWriteWait = time.Second
+
+// CloseReason describes why a connection is refused or terminated
+type CloseReason struct {
+	Code   int
+	Reason string
+}
+
+// CloseWithReason sends a close frame with an application code, then closes the connection
+func CloseWithReason(conn *websocket.Conn, code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	if err := conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(closeWriteWait)); err != nil {
+		log.Printf("Failed to send close frame (%d %s): %v", code, reason, err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,56 @@
+package websocket
+
+import "time"
+
+// Config holds per-connection limits for WebSocket clients
+type Config struct {
+	// PingInterval is how often the server pings idle clients
+	PingInterval time.Duration
+
+	// PongWait is how long a client may stay silent (no pong, no frame) before it's dropped
+	PongWait time.Duration
+
+	// WriteWait bounds a single write to a client
+	WriteWait time.Duration
+
+	// MaxMessageSize is the largest frame accepted from a client (bytes)
+	MaxMessageSize int64
+
+	// SendBufferSize is the number of frames queued per client before it counts as a slow consumer
+	SendBufferSize int
+}
+
+// DefaultConfig returns the default WebSocket limits
+func DefaultConfig() Config {
+	return Config{
+		PingInterval:   30 * time.Second,
+		PongWait:       60 * time.Second,
+		WriteWait:      10 * time.Second,
+		MaxMessageSize: 8192,
+		SendBufferSize: 256,
+	}
+}
+
+// normalize fills invalid values with defaults
+// Pings must be sent more often than PongWait, otherwise healthy clients time out
+func (c Config) normalize() Config {
+	defaults := DefaultConfig()
+
+	if c.PongWait <= 0 {
+		c.PongWait = defaults.PongWait
+	}
+	if c.PingInterval <= 0 || c.PingInterval >= c.PongWait {
+		c.PingInterval = c.PongWait * 9 / 10
+	}
+	if c.WriteWait <= 0 {
+		c.WriteWait = defaults.WriteWait
+	}
+	if c.MaxMessageSize <= 0 {
+		c.MaxMessageSize = defaults.MaxMessageSize
+	}
+	if c.SendBufferSize <= 0 {
+		c.SendBufferSize = defaults.SendBufferSize
+	}
+
+	return c
+}
@@ -1,13 +1,44 @@
 package websocket
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"log"
+	"net"
+	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"zodiac-ai-backend/services/chat-service/models"
+	"zodiac-ai-backend/services/chat-service/repositories"
 
+	fasthttpws "github.com/fasthttp/websocket"
 	"github.com/gofiber/websocket/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// historyReplayLimit is the number of recent messages sent to a client on join
+	historyReplayLimit = 50
+
+	// persistTimeout bounds database writes made from the read pump
+	persistTimeout = 5 * time.Second
+
+	// backplaneTimeout bounds backplane publish and presence calls
+	backplaneTimeout = 5 * time.Second
+
+	// presenceInterval is how often local room counts are reported to the backplane
+	presenceInterval = 10 * time.Second
+
+	// backplaneBufferSize bounds frames waiting to be published or delivered
+	backplaneBufferSize = 1024
+
+	// userChannelPrefix marks hub channels that belong to a user rather than a room
+	userChannelPrefix = "user:"
 )
 
 // Client represents a WebSocket client
@@ -19,6 +50,58 @@ type Client struct {
 	Conn     *websocket.Conn
 	Hub      *Hub
 	Send     chan *models.WebSocketMessage
+
+	// Closed when WritePump stops using the connection
+	writeDone chan struct{}
+
+	// A client is counted as dropped at most once
+	dropOnce sync.Once
+
+	// Mute expiry as Unix nanoseconds (0 = not muted), updated by room controls
+	mutedUntil atomic.Int64
+
+	// Users blocked either way, whose frames this client doesn't receive; kept current by WatchBlocks
+	hiddenUsers atomic.Pointer[map[string]bool]
+}
+
+// SetMutedUntil mutes the client until the given time (nil unmutes)
+func (c *Client) SetMutedUntil(until *time.Time) {
+	if until == nil {
+		c.mutedUntil.Store(0)
+		return
+	}
+	c.mutedUntil.Store(until.UnixNano())
+}
+
+// isMuted reports whether the client may not send messages right now
+func (c *Client) isMuted() bool {
+	until := c.mutedUntil.Load()
+	return until != 0 && time.Now().UnixNano() < until
+}
+
+// SetHiddenUsers replaces the users whose frames the client doesn't receive
+func (c *Client) SetHiddenUsers(userIDs []string) {
+	if len(userIDs) == 0 {
+		c.hiddenUsers.Store(nil)
+		return
+	}
+
+	hidden := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		hidden[userID] = true
+	}
+	c.hiddenUsers.Store(&hidden)
+}
+
+// hides reports whether frames from a user are kept from the client
+func (c *Client) hides(userID string) bool {
+	hidden := c.hiddenUsers.Load()
+	return hidden != nil && userID != "" && (*hidden)[userID]
+}
+
+// markClosing stops later connection errors from being counted as drops
+func (c *Client) markClosing() {
+	c.dropOnce.Do(func() {})
 }
 
 // Hub manages WebSocket connections and rooms
@@ -36,29 +119,106 @@ type Hub struct {
 	// Broadcast messages to room
 	broadcast chan *BroadcastMessage
 
+	// Frames received from other replicas through the backplane
+	remote chan *Envelope
+
+	// Frames waiting to be published to the backplane
+	outbound chan *Envelope
+
+	// Frames addressed to a single client (acks, errors, presence snapshots)
+	direct chan *DirectMessage
+
+	// Room message persistence (history, last message preview)
+	roomRepo *repositories.RoomRepository
+
+	// Cross-replica fan-out and presence
+	backplane     Backplane
+	nodeID        string
+	subscriptions map[string]func() // roomID -> unsubscribe
+
+	// Signals the presence loop that local counts changed
+	presenceDirty chan struct{}
+
+	// Per-connection limits (heartbeat, frame size, send buffer)
+	config Config
+
+	// Dropped client counters per reason
+	dropped map[string]int64
+	statsMu sync.Mutex
+
 	// Mutex for thread-safe room access
 	mu sync.RWMutex
 }
 
 // BroadcastMessage represents a message to broadcast
 type BroadcastMessage struct {
-	RoomID  string
+	RoomID    string
+	Message   *models.WebSocketMessage
+	Control   *RoomControl // Set instead of Message for moderation actions
+	Persisted bool         // Already stored in room_messages
+}
+
+// DirectMessage represents a frame for one client only
+type DirectMessage struct {
+	Client  *Client
 	Message *models.WebSocketMessage
 }
 
 // NewHub creates a new WebSocket hub
-func NewHub() *Hub {
+// A nil backplane keeps fan-out in process (single replica)
+func NewHub(roomRepo *repositories.RoomRepository, backplane Backplane, config Config) *Hub {
+	if backplane == nil {
+		backplane = NewMemoryBackplane()
+	}
+
 	return &Hub{
-		rooms:      make(map[string]map[*Client]bool),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		broadcast:  make(chan *BroadcastMessage),
+		rooms:         make(map[string]map[*Client]bool),
+		register:      make(chan *Client),
+		unregister:    make(chan *Client),
+		broadcast:     make(chan *BroadcastMessage),
+		remote:        make(chan *Envelope, backplaneBufferSize),
+		outbound:      make(chan *Envelope, backplaneBufferSize),
+		direct:        make(chan *DirectMessage, backplaneBufferSize),
+		roomRepo:      roomRepo,
+		backplane:     backplane,
+		nodeID:        newNodeID(),
+		subscriptions: make(map[string]func()),
+		presenceDirty: make(chan struct{}, 1),
+		config:        config.normalize(),
+		dropped: map[string]int64{
+			DropSlowConsumer:    0,
+			DropPongTimeout:     0,
+			DropMessageTooLarge: 0,
+			DropWriteFailed:     0,
+		},
+	}
+}
+
+// NewClient creates a client for a connection, sized by the hub's config
+func (h *Hub) NewClient(conn *websocket.Conn, roomID, userID, username string) *Client {
+	return &Client{
+		ID:        userID + "_" + roomID,
+		RoomID:    roomID,
+		UserID:    userID,
+		Username:  username,
+		Conn:      conn,
+		Hub:       h,
+		Send:      make(chan *models.WebSocketMessage, h.config.SendBufferSize),
+		writeDone: make(chan struct{}),
 	}
 }
 
+// NodeID returns the identifier of this hub replica
+func (h *Hub) NodeID() string {
+	return h.nodeID
+}
+
 // Run starts the hub's main loop
 // Handles register, unregister, and broadcast events using Go channels
 func (h *Hub) Run() {
+	go h.publishLoop()
+	go h.presenceLoop()
+
 	for {
 		select {
 		case client := <-h.register:
@@ -69,17 +229,25 @@ func (h *Hub) Run() {
 			h.rooms[client.RoomID][client] = true
 			h.mu.Unlock()
 
+			h.subscribe(client.RoomID)
+			h.markPresenceDirty()
+
 			log.Printf("Client %s joined room %s", client.Username, client.RoomID)
 
-			// Broadcast join message
-			joinMsg := &models.WebSocketMessage{
-				Type:      "join",
-				UserID:    client.UserID,
-				Username:  client.Username,
-				Content:   client.Username + " joined the room",
-				Timestamp: getCurrentTime(),
+			// Per-user channels carry no presence or join/leave frames
+			if IsUserChannel(client.RoomID) {
+				continue
 			}
-			h.broadcastToRoom(client.RoomID, joinMsg)
+
+			// Tell the new client who is already here
+			go h.sendPresenceSnapshot(client)
+
+			// Broadcast join message
+			joinMsg := models.NewFrame(models.FrameJoin)
+			joinMsg.UserID = client.UserID
+			joinMsg.Username = client.Username
+			joinMsg.Content = client.Username + " joined the room"
+			h.broadcastToRoom(client.RoomID, joinMsg, false)
 
 		case client := <-h.unregister:
 			h.mu.Lock()
@@ -94,28 +262,107 @@ func (h *Hub) Run() {
 					}
 				}
 			}
+			_, roomActive := h.rooms[client.RoomID]
 			h.mu.Unlock()
 
+			if !roomActive {
+				h.unsubscribe(client.RoomID)
+			}
+			h.markPresenceDirty()
+
 			log.Printf("Client %s left room %s", client.Username, client.RoomID)
 
-			// Broadcast leave message
-			leaveMsg := &models.WebSocketMessage{
-				Type:      "leave",
-				UserID:    client.UserID,
-				Username:  client.Username,
-				Content:   client.Username + " left the room",
-				Timestamp: getCurrentTime(),
+			if IsUserChannel(client.RoomID) {
+				continue
 			}
-			h.broadcastToRoom(client.RoomID, leaveMsg)
+
+			// Broadcast leave message
+			leaveMsg := models.NewFrame(models.FrameLeave)
+			leaveMsg.UserID = client.UserID
+			leaveMsg.Username = client.Username
+			leaveMsg.Content = client.Username + " left the room"
+			h.broadcastToRoom(client.RoomID, leaveMsg, false)
 
 		case broadcastMsg := <-h.broadcast:
-			h.broadcastToRoom(broadcastMsg.RoomID, broadcastMsg.Message)
+			if broadcastMsg.Control != nil {
+				h.applyControl(broadcastMsg.RoomID, broadcastMsg.Control)
+				h.publish(&Envelope{NodeID: h.nodeID, RoomID: broadcastMsg.RoomID, Control: broadcastMsg.Control})
+				continue
+			}
+			h.broadcastToRoom(broadcastMsg.RoomID, broadcastMsg.Message, broadcastMsg.Persisted)
+
+		case directMsg := <-h.direct:
+			// Only deliver while the client is registered; its Send channel may be closed otherwise
+			h.mu.RLock()
+			_, ok := h.rooms[directMsg.Client.RoomID][directMsg.Client]
+			h.mu.RUnlock()
+			if !ok {
+				continue
+			}
+			select {
+			case directMsg.Client.Send <- directMsg.Message:
+			default:
+				h.disconnectSlowConsumer(directMsg.Client)
+			}
+
+		case env := <-h.remote:
+			if env.Control != nil {
+				h.applyControl(env.RoomID, env.Control)
+				continue
+			}
+			h.deliverLocal(env.RoomID, env.Message)
 		}
 	}
 }
 
-// broadcastToRoom sends message to all clients in a room
-func (h *Hub) broadcastToRoom(roomID string, message *models.WebSocketMessage) {
+// broadcastToRoom delivers a message to local clients and fans it out to other replicas
+func (h *Hub) broadcastToRoom(roomID string, message *models.WebSocketMessage, persisted bool) {
+	h.deliverLocal(roomID, message)
+
+	h.publish(&Envelope{
+		NodeID:    h.nodeID,
+		RoomID:    roomID,
+		Message:   message,
+		Persisted: persisted,
+	})
+}
+
+// publish queues an envelope for the other replicas
+func (h *Hub) publish(env *Envelope) {
+	select {
+	case h.outbound <- env:
+	default:
+		log.Printf("⚠️ Backplane outbound queue full, room %s frame not replicated", env.RoomID)
+	}
+}
+
+// applyControl applies a moderation action to clients on this replica (called from Run)
+func (h *Hub) applyControl(roomID string, control *RoomControl) {
+	h.mu.RLock()
+	var targets []*Client
+	for client := range h.rooms[roomID] {
+		if control.UserID == "" || client.UserID == control.UserID {
+			targets = append(targets, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range targets {
+		if control.Action == ControlMute {
+			client.SetMutedUntil(control.MutedUntil)
+			continue
+		}
+
+		code, reason := controlCloseReason(control.Action)
+
+		// ReadPump unregisters the client once the connection is closed
+		client.markClosing()
+		go CloseWithReason(client.Conn, code, reason)
+	}
+}
+
+// deliverLocal sends message to all clients in a room connected to this replica
+func (h *Hub) deliverLocal(roomID string, message *models.WebSocketMessage) {
 	h.mu.RLock()
 	clients, ok := h.rooms[roomID]
 	h.mu.RUnlock()
@@ -125,18 +372,79 @@ func (h *Hub) broadcastToRoom(roomID string, message *models.WebSocketMessage) {
 	}
 
 	for client := range clients {
+		if client.hides(message.UserID) {
+			continue
+		}
 		select {
 		case client.Send <- message:
 		default:
-			// Client's send channel is full, close and unregister
-			close(client.Send)
-			h.mu.Lock()
-			delete(clients, client)
-			h.mu.Unlock()
+			h.disconnectSlowConsumer(client)
 		}
 	}
 }
 
+// disconnectSlowConsumer closes the connection of a client whose send buffer is full
+// The client is unregistered by its ReadPump once the connection closes;
+// Send is only ever closed on that unregister path, never here
+func (h *Hub) disconnectSlowConsumer(client *Client) {
+	if !h.recordDrop(client, DropSlowConsumer) {
+		return
+	}
+	go CloseWithReason(client.Conn, CloseSlowConsumer, "slow consumer")
+}
+
+// recordDrop counts a dropped client once
+// Returns false if the client was already counted
+func (h *Hub) recordDrop(client *Client, reason string) bool {
+	recorded := false
+	client.dropOnce.Do(func() {
+		recorded = true
+
+		h.statsMu.Lock()
+		h.dropped[reason]++
+		h.statsMu.Unlock()
+
+		log.Printf("⚠️ Dropping client %s from room %s: %s", client.Username, client.RoomID, reason)
+	})
+	return recorded
+}
+
+// Stats returns hub statistics
+func (h *Hub) Stats() map[string]interface{} {
+	h.mu.RLock()
+	rooms := len(h.rooms)
+	clients := 0
+	for _, roomClients := range h.rooms {
+		clients += len(roomClients)
+	}
+	h.mu.RUnlock()
+
+	h.statsMu.Lock()
+	dropped := make(map[string]int64, len(h.dropped))
+	var totalDropped int64
+	for reason, count := range h.dropped {
+		dropped[reason] = count
+		totalDropped += count
+	}
+	h.statsMu.Unlock()
+
+	return map[string]interface{}{
+		"node_id":         h.nodeID,
+		"rooms":           rooms,
+		"clients":         clients,
+		"dropped_clients": dropped,
+		"total_dropped":   totalDropped,
+	}
+}
+
+// SendControl applies a moderation action to a room's live connections on every replica
+func (h *Hub) SendControl(roomID string, control *RoomControl) {
+	h.broadcast <- &BroadcastMessage{
+		RoomID:  roomID,
+		Control: control,
+	}
+}
+
 // BroadcastMessage broadcasts a message to a room
 func (h *Hub) BroadcastMessage(roomID string, message *models.WebSocketMessage) {
 	h.broadcast <- &BroadcastMessage{
@@ -145,8 +453,85 @@ func (h *Hub) BroadcastMessage(roomID string, message *models.WebSocketMessage)
 	}
 }
 
-// GetRoomClients gets number of clients in a room
+// sendTo queues a frame for a single client
+func (h *Hub) sendTo(client *Client, message *models.WebSocketMessage) {
+	select {
+	case h.direct <- &DirectMessage{Client: client, Message: message}:
+	default:
+		log.Printf("⚠️ Hub direct queue full, dropping %s frame for %s", message.Type, client.Username)
+	}
+}
+
+// GetRoomClients gets number of clients in a room across all replicas
+// Falls back to the local count if the backplane can't be reached
 func (h *Hub) GetRoomClients(roomID string) int {
+	ctx, cancel := context.WithTimeout(context.Background(), backplaneTimeout)
+	defer cancel()
+
+	members, err := h.backplane.ListPresence(ctx, roomID)
+	if err != nil {
+		log.Printf("⚠️ Failed to aggregate presence for room %s: %v", roomID, err)
+		return h.localRoomClients(roomID)
+	}
+
+	// Presence is reported periodically; never report fewer than we can see
+	if local := h.localRoomClients(roomID); local > len(members) {
+		return local
+	}
+	return len(members)
+}
+
+// RoomPresence lists distinct users connected to a room across all replicas
+// Local clients are always included, even before the next presence report
+func (h *Hub) RoomPresence(roomID string) *models.PresenceSnapshot {
+	ctx, cancel := context.WithTimeout(context.Background(), backplaneTimeout)
+	defer cancel()
+
+	members, err := h.backplane.ListPresence(ctx, roomID)
+	if err != nil {
+		log.Printf("⚠️ Failed to aggregate presence for room %s: %v", roomID, err)
+	}
+	members = append(members, h.localMembers(roomID)...)
+
+	// A user may be connected from several tabs or replicas
+	seen := make(map[string]bool, len(members))
+	snapshot := &models.PresenceSnapshot{Members: []models.PresenceMember{}}
+	for _, member := range members {
+		if seen[member.UserID] {
+			continue
+		}
+		seen[member.UserID] = true
+		snapshot.Members = append(snapshot.Members, member)
+	}
+	snapshot.Count = len(snapshot.Members)
+
+	return snapshot
+}
+
+// sendPresenceSnapshot sends the room's presence to a client that just joined
+func (h *Hub) sendPresenceSnapshot(client *Client) {
+	frame := models.NewFrame(models.FramePresenceSnapshot)
+	frame.Presence = h.RoomPresence(client.RoomID)
+	h.sendTo(client, frame)
+}
+
+// localMembers lists clients in a room on this replica (one entry per connection)
+func (h *Hub) localMembers(roomID string) []models.PresenceMember {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	members := make([]models.PresenceMember, 0, len(h.rooms[roomID]))
+	for client := range h.rooms[roomID] {
+		members = append(members, models.PresenceMember{
+			UserID:   client.UserID,
+			Username: client.Username,
+		})
+	}
+	return members
+}
+
+// localRoomClients gets number of clients in a room on this replica
+func (h *Hub) localRoomClients(roomID string) int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
@@ -156,54 +541,368 @@ func (h *Hub) GetRoomClients(roomID string) int {
 	return 0
 }
 
+// subscribe starts receiving backplane frames for a room (called from Run)
+func (h *Hub) subscribe(roomID string) {
+	if _, ok := h.subscriptions[roomID]; ok {
+		return
+	}
+
+	unsubscribe, err := h.backplane.Subscribe(roomID, func(env *Envelope) {
+		// Our own frames were already delivered locally
+		if env.NodeID == h.nodeID {
+			return
+		}
+		select {
+		case h.remote <- env:
+		default:
+			log.Printf("⚠️ Hub remote queue full, dropping frame for room %s", env.RoomID)
+		}
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to subscribe to backplane for room %s: %v", roomID, err)
+		return
+	}
+
+	h.subscriptions[roomID] = unsubscribe
+}
+
+// unsubscribe stops receiving backplane frames for a room (called from Run)
+func (h *Hub) unsubscribe(roomID string) {
+	if unsubscribe, ok := h.subscriptions[roomID]; ok {
+		unsubscribe()
+		delete(h.subscriptions, roomID)
+	}
+}
+
+// publishLoop publishes outbound frames without blocking the hub loop
+func (h *Hub) publishLoop() {
+	for env := range h.outbound {
+		ctx, cancel := context.WithTimeout(context.Background(), backplaneTimeout)
+		if err := h.backplane.Publish(ctx, env); err != nil {
+			log.Printf("⚠️ Failed to publish frame for room %s: %v", env.RoomID, err)
+		}
+		cancel()
+	}
+}
+
+// markPresenceDirty asks the presence loop to report counts now
+func (h *Hub) markPresenceDirty() {
+	select {
+	case h.presenceDirty <- struct{}{}:
+	default:
+	}
+}
+
+// presenceLoop reports local room members to the backplane
+// Entries are refreshed periodically so stale nodes age out of the aggregate
+func (h *Hub) presenceLoop() {
+	ticker := time.NewTicker(presenceInterval)
+	defer ticker.Stop()
+
+	reported := make(map[string]bool)
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-h.presenceDirty:
+		}
+
+		h.mu.RLock()
+		roomIDs := make([]string, 0, len(h.rooms))
+		for roomID := range h.rooms {
+			roomIDs = append(roomIDs, roomID)
+		}
+		h.mu.RUnlock()
+
+		presence := make(map[string][]models.PresenceMember, len(roomIDs))
+		for _, roomID := range roomIDs {
+			presence[roomID] = h.localMembers(roomID)
+		}
+
+		// Rooms we reported before but no longer hold are cleared
+		for roomID := range reported {
+			if _, ok := presence[roomID]; !ok {
+				presence[roomID] = nil
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), backplaneTimeout)
+		for roomID, members := range presence {
+			if err := h.backplane.SetPresence(ctx, h.nodeID, roomID, members); err != nil {
+				log.Printf("⚠️ Failed to report presence for room %s: %v", roomID, err)
+				continue
+			}
+			if len(members) > 0 {
+				reported[roomID] = true
+			} else {
+				delete(reported, roomID)
+			}
+		}
+		cancel()
+	}
+}
+
 // Register registers a client (exported method)
+// Recent history is queued on the client before it joins the room,
+// so replayed messages always arrive ahead of live ones
 func (h *Hub) Register(client *Client) {
+	if !IsUserChannel(client.RoomID) {
+		h.replayHistory(client)
+	}
 	h.register <- client
 }
 
+// SendToUser delivers a frame to every connection of a user's channel, on every replica
+func (h *Hub) SendToUser(userID string, message *models.WebSocketMessage) {
+	h.broadcast <- &BroadcastMessage{
+		RoomID:  UserChannel(userID),
+		Message: message,
+	}
+}
+
+// UserChannel returns the per-user channel a user's inbox connections join
+func UserChannel(userID string) string {
+	return userChannelPrefix + userID
+}
+
+// IsUserChannel reports whether a channel is a per-user channel rather than a room
+func IsUserChannel(roomID string) bool {
+	return strings.HasPrefix(roomID, userChannelPrefix)
+}
+
 // Unregister unregisters a client (exported method)
 func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
 }
 
 // ReadPump reads messages from WebSocket connection
+// Client frames are validated and rebuilt server-side: identity, IDs and
+// timestamps always come from the server, never from the client
 func (c *Client) ReadPump() {
 	defer func() {
+		// Errors from here on are caused by our own shutdown, not by the client
+		c.markClosing()
+
 		c.Hub.unregister <- c
 		c.Conn.Close()
+
+		// The connection is released when the handler returns; wait for the writer first
+		<-c.writeDone
 	}()
 
+	config := c.Hub.config
+	c.Conn.SetReadLimit(config.MaxMessageSize)
+	c.Conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	c.Conn.SetPongHandler(func(string) error {
+		return c.Conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	})
+
 	for {
-		var msg models.WebSocketMessage
-		if err := c.Conn.ReadJSON(&msg); err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+		_, data, err := c.Conn.ReadMessage()
+		if err != nil {
+			switch {
+			case errors.Is(err, fasthttpws.ErrReadLimit):
+				// The library already answered with close code 1009
+				c.Hub.recordDrop(c, DropMessageTooLarge)
+			case isTimeout(err):
+				c.Hub.recordDrop(c, DropPongTimeout)
+			case websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure):
 				log.Printf("WebSocket error: %v", err)
 			}
 			break
 		}
 
-		// Set metadata
+		// Any frame proves the client is alive
+		c.Conn.SetReadDeadline(time.Now().Add(config.PongWait))
+
+		var incoming models.WebSocketMessage
+		if err := json.Unmarshal(data, &incoming); err != nil {
+			c.Hub.sendTo(c, models.NewErrorFrame("", models.ErrCodeInvalidFrame, "frame must be a JSON object"))
+			continue
+		}
+
+		// Per-user channels are receive-only; direct messages are sent over REST
+		if IsUserChannel(c.RoomID) {
+			c.Hub.sendTo(c, models.NewErrorFrame(incoming.ClientMsgID, models.ErrCodeForbiddenType, "send direct messages via POST /conversations/:id/messages"))
+			continue
+		}
+
+		if frameErr := incoming.ValidateClientFrame(); frameErr != nil {
+			c.Hub.sendTo(c, models.NewErrorFrame(incoming.ClientMsgID, frameErr.Code, frameErr.Message))
+			continue
+		}
+
+		msg := models.NewFrame(incoming.Type)
 		msg.UserID = c.UserID
 		msg.Username = c.Username
-		msg.Timestamp = getCurrentTime()
+
+		switch incoming.Type {
+		case models.FrameMessage:
+			if c.isMuted() {
+				c.Hub.sendTo(c, models.NewErrorFrame(incoming.ClientMsgID, models.ErrCodeMuted, "you are muted in this room"))
+				continue
+			}
+
+			msg.ClientMsgID = incoming.ClientMsgID
+			msg.Content = strings.TrimSpace(incoming.Content)
+
+			// Persist chat messages before broadcasting so history matches what was seen live
+			if err := c.Hub.persistMessage(c, msg); err != nil {
+				log.Printf("Failed to persist message in room %s: %v", c.RoomID, err)
+				c.Hub.sendTo(c, models.NewErrorFrame(incoming.ClientMsgID, models.ErrCodePersistFailed, "failed to save message"))
+				continue
+			}
+
+			ack := models.NewFrame(models.FrameAck)
+			ack.ID = msg.ID
+			ack.ClientMsgID = incoming.ClientMsgID
+			ack.Timestamp = msg.Timestamp
+			c.Hub.sendTo(c, ack)
+
+		case models.FrameReadReceipt:
+			msg.MessageID = incoming.MessageID
+		}
 
 		// Broadcast to room
-		c.Hub.BroadcastMessage(c.RoomID, &msg)
+		c.Hub.broadcast <- &BroadcastMessage{
+			RoomID:    c.RoomID,
+			Message:   msg,
+			Persisted: msg.ID != "",
+		}
 	}
 }
 
-// WritePump writes messages to WebSocket connection
+// WritePump writes messages to WebSocket connection and pings idle clients
 func (c *Client) WritePump() {
+	config := c.Hub.config
+	ticker := time.NewTicker(config.PingInterval)
+
 	defer func() {
+		ticker.Stop()
 		c.Conn.Close()
+		close(c.writeDone)
 	}()
 
-	for message := range c.Send {
-		if err := c.Conn.WriteJSON(message); err != nil {
-			log.Printf("Write error: %v", err)
-			break
+	for {
+		select {
+		case message, ok := <-c.Send:
+			if !ok {
+				// Hub unregistered the client
+				c.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(config.WriteWait))
+				return
+			}
+
+			c.Conn.SetWriteDeadline(time.Now().Add(config.WriteWait))
+			if err := c.Conn.WriteJSON(message); err != nil {
+				c.Hub.recordDrop(c, DropWriteFailed)
+				return
+			}
+
+		case <-ticker.C:
+			if err := c.Conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(config.WriteWait)); err != nil {
+				c.Hub.recordDrop(c, DropWriteFailed)
+				return
+			}
+		}
+	}
+}
+
+// isTimeout reports whether a read failed because its deadline passed
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// persistMessage saves a chat message and updates the room's last message preview
+func (h *Hub) persistMessage(client *Client, msg *models.WebSocketMessage) error {
+	roomObjID, err := primitive.ObjectIDFromHex(client.RoomID)
+	if err != nil {
+		return err
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(client.UserID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+
+	roomMessage := &models.RoomMessage{
+		RoomID:   roomObjID,
+		UserID:   userObjID,
+		Username: client.Username,
+		Content:  msg.Content,
+		NodeID:   h.nodeID,
+	}
+
+	if err := h.roomRepo.SaveMessage(ctx, roomMessage); err != nil {
+		return err
+	}
+
+	msg.ID = roomMessage.ID.Hex()
+	msg.Timestamp = roomMessage.CreatedAt
+
+	// Preview update is best-effort; the message itself is already stored
+	preview := &models.RoomMessagePreview{
+		Content:   roomMessage.Content,
+		SenderID:  client.UserID,
+		Timestamp: roomMessage.CreatedAt,
+	}
+	if err := h.roomRepo.UpdateLastMessage(ctx, roomObjID, preview); err != nil {
+		log.Printf("Failed to update last message for room %s: %v", client.RoomID, err)
+	}
+
+	return nil
+}
+
+// replayHistory queues the most recent room messages on a joining client
+func (h *Hub) replayHistory(client *Client) {
+	roomObjID, err := primitive.ObjectIDFromHex(client.RoomID)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+
+	messages, err := h.roomRepo.GetRecentMessages(ctx, roomObjID, historyReplayLimit)
+	if err != nil {
+		log.Printf("Failed to load history for room %s: %v", client.RoomID, err)
+		return
+	}
+
+	for _, message := range messages {
+		if client.hides(message.UserID.Hex()) {
+			continue
 		}
+		select {
+		case client.Send <- roomMessageFrame(message):
+		default:
+			// Send buffer is full; the rest is available via GET /rooms/:id/messages
+			return
+		}
+	}
+}
+
+// roomMessageFrame converts a stored room message into a "message" frame
+func roomMessageFrame(message *models.RoomMessage) *models.WebSocketMessage {
+	frame := models.NewFrame(models.FrameMessage)
+	frame.ID = message.ID.Hex()
+	frame.UserID = message.UserID.Hex()
+	frame.Username = message.Username
+	frame.Content = message.Content
+	frame.Timestamp = message.CreatedAt
+	return frame
+}
+
+// newNodeID generates a unique replica identifier (hostname + random suffix)
+func newNodeID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "node"
 	}
+	return host + "-" + uuid.New().String()[:8]
 }
 
 // getCurrentTime returns current timestamp
@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"zodiac-ai-backend/services/chat-service/models"
+)
+
+// newTestHub starts a hub without a database or connections on the shared backplane
+func newTestHub(t *testing.T, backplane Backplane) *Hub {
+	t.Helper()
+
+	hub := NewHub(nil, backplane, DefaultConfig())
+	go hub.Run()
+	return hub
+}
+
+// joinRoom registers a connection-less client; frames are read from its Send channel
+func joinRoom(hub *Hub, roomID, userID string) *Client {
+	client := hub.NewClient(nil, roomID, userID, userID)
+	hub.register <- client // Skips Register's history replay, which needs the database
+	return client
+}
+
+// waitFor polls cond until it holds or the deadline passes
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// receive reads the client's frames until one matches, skipping join and presence frames
+func receive(t *testing.T, client *Client, match func(*models.WebSocketMessage) bool) *models.WebSocketMessage {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case frame := <-client.Send:
+			if match(frame) {
+				return frame
+			}
+		case <-timeout:
+			t.Fatalf("client %s received no matching frame", client.UserID)
+			return nil
+		}
+	}
+}
+
+func TestHubsShareRoomThroughMemoryBackplane(t *testing.T) {
+	backplane := NewMemoryBackplane()
+	defer backplane.Close()
+
+	hubA := newTestHub(t, backplane)
+	hubB := newTestHub(t, backplane)
+
+	const roomID = "room-1"
+	alice := joinRoom(hubA, roomID, "alice")
+	bob := joinRoom(hubB, roomID, "bob")
+
+	// Each hub reports its own client; the count is summed across both nodes.
+	// Hubs subscribe before they report presence, so both are subscribed afterwards.
+	waitFor(t, "presence from both hubs", func() bool {
+		return hubA.GetRoomClients(roomID) == 2 && hubB.GetRoomClients(roomID) == 2
+	})
+
+	message := models.NewFrame(models.FrameMessage)
+	message.UserID = alice.UserID
+	message.Username = alice.Username
+	message.Content = "hello from hub A"
+	hubA.BroadcastMessage(roomID, message)
+
+	isMessage := func(frame *models.WebSocketMessage) bool {
+		return frame.Type == models.FrameMessage && frame.Content == message.Content
+	}
+	receive(t, bob, isMessage)   // Remote client, through the backplane
+	receive(t, alice, isMessage) // Local client
+
+	presence := hubB.RoomPresence(roomID)
+	if presence.Count != 2 {
+		t.Fatalf("presence count = %d, want 2", presence.Count)
+	}
+}
+
+func TestHubPresenceDropsClientsThatLeft(t *testing.T) {
+	backplane := NewMemoryBackplane()
+	defer backplane.Close()
+
+	hubA := newTestHub(t, backplane)
+	hubB := newTestHub(t, backplane)
+
+	const roomID = "room-2"
+	joinRoom(hubA, roomID, "alice")
+	bob := joinRoom(hubB, roomID, "bob")
+
+	waitFor(t, "presence from both hubs", func() bool {
+		return hubA.GetRoomClients(roomID) == 2
+	})
+
+	hubB.Unregister(bob)
+
+	waitFor(t, "hub B to clear its presence", func() bool {
+		return hubA.GetRoomClients(roomID) == 1
+	})
+}
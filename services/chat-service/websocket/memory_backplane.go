@@ -0,0 +1,140 @@
+package websocket
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"zodiac-ai-backend/services/chat-service/models"
+)
+
+// subscriberBufferSize bounds envelopes queued per in-memory subscriber
+const subscriberBufferSize = 256
+
+// MemoryBackplane is an in-process Backplane
+// Used for single-node deployments and to wire several hubs together in tests
+type MemoryBackplane struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*memorySubscriber]bool
+	presence    map[string]map[string][]models.PresenceMember // roomID -> nodeID -> members
+	closed      bool
+}
+
+// memorySubscriber delivers envelopes in order on its own goroutine,
+// so a slow hub never blocks the publisher
+type memorySubscriber struct {
+	queue   chan *Envelope
+	handler EnvelopeHandler
+	once    sync.Once
+}
+
+// NewMemoryBackplane creates a new in-memory backplane
+func NewMemoryBackplane() *MemoryBackplane {
+	return &MemoryBackplane{
+		subscribers: make(map[string]map[*memorySubscriber]bool),
+		presence:    make(map[string]map[string][]models.PresenceMember),
+	}
+}
+
+// Publish delivers an envelope to all subscribers of its room
+func (b *MemoryBackplane) Publish(ctx context.Context, env *Envelope) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers[env.RoomID] {
+		select {
+		case sub.queue <- env:
+		default:
+			log.Printf("⚠️ Backplane subscriber for room %s is full, dropping frame", env.RoomID)
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a handler for a room
+func (b *MemoryBackplane) Subscribe(roomID string, handler EnvelopeHandler) (func(), error) {
+	sub := &memorySubscriber{
+		queue:   make(chan *Envelope, subscriberBufferSize),
+		handler: handler,
+	}
+
+	b.mu.Lock()
+	if _, ok := b.subscribers[roomID]; !ok {
+		b.subscribers[roomID] = make(map[*memorySubscriber]bool)
+	}
+	b.subscribers[roomID][sub] = true
+	b.mu.Unlock()
+
+	go func() {
+		for env := range sub.queue {
+			sub.handler(env)
+		}
+	}()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		if subs, ok := b.subscribers[roomID]; ok {
+			delete(subs, sub)
+			if len(subs) == 0 {
+				delete(b.subscribers, roomID)
+			}
+		}
+		b.mu.Unlock()
+		sub.once.Do(func() { close(sub.queue) })
+	}
+
+	return unsubscribe, nil
+}
+
+// SetPresence records a node's clients for a room
+func (b *MemoryBackplane) SetPresence(ctx context.Context, nodeID, roomID string, members []models.PresenceMember) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(members) == 0 {
+		if nodes, ok := b.presence[roomID]; ok {
+			delete(nodes, nodeID)
+			if len(nodes) == 0 {
+				delete(b.presence, roomID)
+			}
+		}
+		return nil
+	}
+
+	if _, ok := b.presence[roomID]; !ok {
+		b.presence[roomID] = make(map[string][]models.PresenceMember)
+	}
+	b.presence[roomID][nodeID] = members
+	return nil
+}
+
+// ListPresence returns clients for a room across nodes
+func (b *MemoryBackplane) ListPresence(ctx context.Context, roomID string) ([]models.PresenceMember, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var members []models.PresenceMember
+	for _, nodeMembers := range b.presence[roomID] {
+		members = append(members, nodeMembers...)
+	}
+	return members, nil
+}
+
+// Close removes all subscribers
+func (b *MemoryBackplane) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+
+	for _, subs := range b.subscribers {
+		for sub := range subs {
+			sub.once.Do(func() { close(sub.queue) })
+		}
+	}
+	b.subscribers = make(map[string]map[*memorySubscriber]bool)
+	return nil
+}
@@ -0,0 +1,290 @@
+package websocket
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"zodiac-ai-backend/services/chat-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// presenceStaleAfter ignores presence entries from nodes that stopped reporting
+	presenceStaleAfter = 30 * time.Second
+
+	// maxWatchBackoff caps the delay between change stream reconnects
+	maxWatchBackoff = 30 * time.Second
+)
+
+// MongoBackplane replicates room frames between hub replicas using change streams
+// Chat messages travel through inserts on room_messages (already written by the hub),
+// ephemeral frames (join/leave, ...) through the short-lived room_events collection.
+// NOTE: change streams require MongoDB to run as a replica set
+// Reference: DDIA Ch. 11 - Change data capture
+type MongoBackplane struct {
+	messages *mongo.Collection
+	events   *mongo.Collection
+	presence *mongo.Collection
+
+	mu       sync.RWMutex
+	handlers map[string]map[*EnvelopeHandler]bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// roomEvent represents an ephemeral frame stored in room_events
+// Indexes:
+//   - created_at: TTL index (60 seconds) - AUTO DELETE
+type roomEvent struct {
+	ID        primitive.ObjectID       `bson:"_id,omitempty"`
+	NodeID    string                   `bson:"node_id"`
+	RoomID    string                   `bson:"room_id"`
+	Message   *models.WebSocketMessage `bson:"message,omitempty"`
+	Control   *RoomControl             `bson:"control,omitempty"`
+	CreatedAt time.Time                `bson:"created_at"`
+}
+
+// roomPresence represents one node's clients in a room
+// Indexes:
+//   - room_id: index for aggregation
+//   - updated_at: TTL index (120 seconds) - AUTO DELETE
+type roomPresence struct {
+	ID        string                  `bson:"_id"`
+	NodeID    string                  `bson:"node_id"`
+	RoomID    string                  `bson:"room_id"`
+	Members   []models.PresenceMember `bson:"members"`
+	Count     int                     `bson:"count"`
+	UpdatedAt time.Time               `bson:"updated_at"`
+}
+
+// NewMongoBackplane creates a MongoDB backplane and starts watching for frames
+func NewMongoBackplane(db *mongo.Database) *MongoBackplane {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b := &MongoBackplane{
+		messages: db.Collection("room_messages"),
+		events:   db.Collection("room_events"),
+		presence: db.Collection("room_presence"),
+		handlers: make(map[string]map[*EnvelopeHandler]bool),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	b.wg.Add(2)
+	go b.watch(b.messages, decodeRoomMessage)
+	go b.watch(b.events, decodeRoomEvent)
+
+	log.Println("📡 MongoDB WebSocket backplane started")
+	return b
+}
+
+// Publish stores ephemeral frames in room_events
+// Persisted chat messages are skipped: their room_messages insert is already replicated
+func (b *MongoBackplane) Publish(ctx context.Context, env *Envelope) error {
+	if env.Persisted {
+		return nil
+	}
+
+	_, err := b.events.InsertOne(ctx, &roomEvent{
+		NodeID:    env.NodeID,
+		RoomID:    env.RoomID,
+		Message:   env.Message,
+		Control:   env.Control,
+		CreatedAt: time.Now(),
+	})
+	return err
+}
+
+// Subscribe registers a handler for a room
+func (b *MongoBackplane) Subscribe(roomID string, handler EnvelopeHandler) (func(), error) {
+	key := &handler
+
+	b.mu.Lock()
+	if _, ok := b.handlers[roomID]; !ok {
+		b.handlers[roomID] = make(map[*EnvelopeHandler]bool)
+	}
+	b.handlers[roomID][key] = true
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if handlers, ok := b.handlers[roomID]; ok {
+			delete(handlers, key)
+			if len(handlers) == 0 {
+				delete(b.handlers, roomID)
+			}
+		}
+	}
+
+	return unsubscribe, nil
+}
+
+// SetPresence upserts this node's clients for a room
+func (b *MongoBackplane) SetPresence(ctx context.Context, nodeID, roomID string, members []models.PresenceMember) error {
+	id := nodeID + "|" + roomID
+
+	if len(members) == 0 {
+		_, err := b.presence.DeleteOne(ctx, bson.M{"_id": id})
+		return err
+	}
+
+	_, err := b.presence.ReplaceOne(
+		ctx,
+		bson.M{"_id": id},
+		&roomPresence{
+			ID:        id,
+			NodeID:    nodeID,
+			RoomID:    roomID,
+			Members:   members,
+			Count:     len(members),
+			UpdatedAt: time.Now(),
+		},
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// ListPresence returns clients from fresh presence entries for a room
+func (b *MongoBackplane) ListPresence(ctx context.Context, roomID string) ([]models.PresenceMember, error) {
+	cursor, err := b.presence.Find(ctx, bson.M{
+		"room_id":    roomID,
+		"updated_at": bson.M{"$gte": time.Now().Add(-presenceStaleAfter)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*roomPresence
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	var members []models.PresenceMember
+	for _, entry := range entries {
+		members = append(members, entry.Members...)
+	}
+	return members, nil
+}
+
+// Close stops the change stream watchers
+func (b *MongoBackplane) Close() error {
+	b.cancel()
+	b.wg.Wait()
+	return nil
+}
+
+// watch follows inserts on a collection, reconnecting with exponential backoff
+func (b *MongoBackplane) watch(coll *mongo.Collection, decode func(bson.Raw) (*Envelope, error)) {
+	defer b.wg.Done()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": "insert"}}},
+	}
+
+	var resumeToken bson.Raw
+	backoff := time.Second
+
+	for b.ctx.Err() == nil {
+		opts := options.ChangeStream()
+		if resumeToken != nil {
+			opts.SetResumeAfter(resumeToken)
+		}
+
+		stream, err := coll.Watch(b.ctx, pipeline, opts)
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return
+			}
+			log.Printf("⚠️ Backplane watch on %s failed (retrying in %v): %v", coll.Name(), backoff, err)
+
+			// The resume point may have fallen off the oplog; start fresh next time
+			resumeToken = nil
+
+			select {
+			case <-time.After(backoff):
+			case <-b.ctx.Done():
+				return
+			}
+			if backoff < maxWatchBackoff {
+				backoff *= 2
+			}
+			continue
+		}
+		backoff = time.Second
+
+		for stream.Next(b.ctx) {
+			resumeToken = stream.ResumeToken()
+
+			var change struct {
+				FullDocument bson.Raw `bson:"fullDocument"`
+			}
+			if err := stream.Decode(&change); err != nil {
+				log.Printf("⚠️ Backplane failed to decode change on %s: %v", coll.Name(), err)
+				continue
+			}
+
+			env, err := decode(change.FullDocument)
+			if err != nil {
+				log.Printf("⚠️ Backplane failed to decode document on %s: %v", coll.Name(), err)
+				continue
+			}
+
+			b.dispatch(env)
+		}
+
+		if err := stream.Err(); err != nil && b.ctx.Err() == nil {
+			log.Printf("⚠️ Backplane stream on %s interrupted: %v", coll.Name(), err)
+		}
+		stream.Close(context.Background())
+	}
+}
+
+// dispatch hands an envelope to the room's handlers
+func (b *MongoBackplane) dispatch(env *Envelope) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for handler := range b.handlers[env.RoomID] {
+		(*handler)(env)
+	}
+}
+
+// decodeRoomMessage converts a room_messages insert into an envelope
+func decodeRoomMessage(raw bson.Raw) (*Envelope, error) {
+	var message models.RoomMessage
+	if err := bson.Unmarshal(raw, &message); err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		NodeID:    message.NodeID,
+		RoomID:    message.RoomID.Hex(),
+		Message:   roomMessageFrame(&message),
+		Persisted: true,
+	}, nil
+}
+
+// decodeRoomEvent converts a room_events insert into an envelope
+func decodeRoomEvent(raw bson.Raw) (*Envelope, error) {
+	var event roomEvent
+	if err := bson.Unmarshal(raw, &event); err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		NodeID:  event.NodeID,
+		RoomID:  event.RoomID,
+		Message: event.Message,
+		Control: event.Control,
+	}, nil
+}
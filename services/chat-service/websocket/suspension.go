@@ -0,0 +1,79 @@
+package websocket
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// SuspensionCheck returns the users among userIDs that are suspended
+type SuspensionCheck func(ctx context.Context, userIDs []string) ([]string, error)
+
+// WatchSuspensions disconnects suspended users every interval, in rooms and inbox channels alike
+// Each replica checks only its own connections, so no backplane traffic is involved.
+// Runs until the process exits, like the hub itself.
+func (h *Hub) WatchSuspensions(check SuspensionCheck, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		userIDs := h.connectedUsers()
+		if len(userIDs) == 0 {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		suspended, err := check(ctx, userIDs)
+		cancel()
+		if err != nil {
+			log.Printf("⚠️ Failed to check suspended users: %v", err)
+			continue
+		}
+
+		for _, userID := range suspended {
+			h.disconnectUser(userID, CloseForbidden, "account suspended")
+		}
+	}
+}
+
+// connectedUsers lists distinct users connected to this replica
+func (h *Hub) connectedUsers() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	userIDs := []string{}
+	for _, clients := range h.rooms {
+		for client := range clients {
+			if !seen[client.UserID] {
+				seen[client.UserID] = true
+				userIDs = append(userIDs, client.UserID)
+			}
+		}
+	}
+	return userIDs
+}
+
+// disconnectUser closes every connection of a user on this replica
+func (h *Hub) disconnectUser(userID string, code int, reason string) {
+	h.mu.RLock()
+	var targets []*Client
+	for _, clients := range h.rooms {
+		for client := range clients {
+			if client.UserID == userID {
+				targets = append(targets, client)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range targets {
+		// ReadPump unregisters the client once the connection is closed
+		client.markClosing()
+		go CloseWithReason(client.Conn, code, reason)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"zodiac-ai-backend/pkg/middleware"
+	"zodiac-ai-backend/pkg/response"
+	"zodiac-ai-backend/services/social-service/repositories"
+	"zodiac-ai-backend/services/social-service/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ModerationHandler handles post and comment removal by admins and moderators
+type ModerationHandler struct {
+	moderationService *services.ModerationService
+}
+
+// NewModerationHandler creates a new moderation handler
+func NewModerationHandler(moderationService *services.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+// DeletePost removes a post
+// DELETE /admin/posts/:id
+func (h *ModerationHandler) DeletePost(c *fiber.Ctx) error {
+	err := h.moderationService.DeletePost(c.Context(), middleware.GetUserID(c), c.Params("id"))
+	if err != nil {
+		if err == repositories.ErrPostNotFound {
+			return response.NotFound(c, "Post not found")
+		}
+		return response.InternalServerError(c, "Failed to delete post")
+	}
+
+	return response.Success(c, "Post deleted", nil)
+}
+
+// DeleteComment removes a comment and its replies
+// DELETE /admin/comments/:id
+func (h *ModerationHandler) DeleteComment(c *fiber.Ctx) error {
+	err := h.moderationService.DeleteComment(c.Context(), middleware.GetUserID(c), c.Params("id"))
+	if err != nil {
+		if err == repositories.ErrCommentNotFound {
+			return response.NotFound(c, "Comment not found")
+		}
+		return response.InternalServerError(c, "Failed to delete comment")
+	}
+
+	return response.Success(c, "Comment deleted", nil)
+}
@@ -54,7 +54,7 @@ func (h *SocialHandler) GetFeed(c *fiber.Ctx) error {
 		SortBy:     c.Query("sort", "latest"),
 	}
 
-	posts, nextCursor, err := h.socialService.GetFeed(c.Context(), query)
+	posts, nextCursor, err := h.socialService.GetFeed(c.Context(), middleware.GetUserID(c), query)
 	if err != nil {
 		return response.InternalServerError(c, "Failed to get feed")
 	}
@@ -169,7 +169,7 @@ func (h *SocialHandler) GetComments(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Post ID required", nil)
 	}
 
-	comments, err := h.socialService.GetComments(c.Context(), postID)
+	comments, err := h.socialService.GetComments(c.Context(), middleware.GetUserID(c), postID)
 	if err != nil {
 		return response.InternalServerError(c, "Failed to get comments")
 	}
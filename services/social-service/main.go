@@ -13,6 +13,7 @@ import (
 	"zodiac-ai-backend/pkg/database"
 	"zodiac-ai-backend/pkg/jwt"
 	"zodiac-ai-backend/pkg/middleware"
+	authRepos "zodiac-ai-backend/services/auth-service/repositories"
 	"zodiac-ai-backend/services/social-service/handlers"
 	"zodiac-ai-backend/services/social-service/repositories"
 	"zodiac-ai-backend/services/social-service/services"
@@ -23,6 +24,9 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Connect to MongoDB
 	_, err := database.Connect(database.MongoConfig{
@@ -37,21 +41,39 @@ func main() {
 	db := database.GetDatabase(cfg.MongoDatabase)
 
 	// Initialize JWT manager
-	jwtManager := jwt.NewManager(
-		cfg.JWTSecret,
-		cfg.JWTAccessExpiry,
-		cfg.JWTRefreshExpiry,
-	)
+	jwtManager, err := jwt.New(jwt.Config{
+		Secret:            cfg.JWTSecret,
+		Keys:              cfg.JWTKeys,
+		ActiveKeyID:       cfg.JWTActiveKeyID,
+		AcceptLegacyHS256: cfg.JWTAcceptHS256,
+		AccessExpiry:      cfg.JWTAccessExpiry,
+		RefreshExpiry:     cfg.JWTRefreshExpiry,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize JWT manager: %v", err)
+	}
 
 	// Initialize repositories
 	postRepo := repositories.NewPostRepository(db)
 	commentRepo := repositories.NewCommentRepository(db)
+	refreshTokenRepo := authRepos.NewRefreshTokenRepository(db) // Shared refresh_tokens collection (session revocation)
+	userRepo := authRepos.NewUserRepository(db)                 // Shared users collection (suspensions)
+	auditLogRepo := authRepos.NewAuditLogRepository(db)         // Shared audit_logs collection (moderation)
+	blockRepo := authRepos.NewBlockRepository(db)               // Shared blocks collection (hidden users)
+
+	// Access tokens of suspended users and logged out sessions are rejected (cached briefly)
+	suspensionCache := middleware.NewSuspensionCache(userRepo, cfg.SessionCacheTTL)
+	sessionCache := middleware.NewRevocationCache(refreshTokenRepo, cfg.SessionCacheTTL)
+	authMiddleware := middleware.AuthMiddleware(jwtManager, suspensionCache.Validate, sessionCache.Validate)
+	optionalAuth := middleware.OptionalAuthMiddleware(jwtManager, suspensionCache.Validate, sessionCache.Validate)
 
 	// Initialize services
-	socialService := services.NewSocialService(postRepo, commentRepo)
+	socialService := services.NewSocialService(postRepo, commentRepo, blockRepo)
+	moderationService := services.NewModerationService(postRepo, commentRepo, auditLogRepo)
 
 	// Initialize handlers
 	socialHandler := handlers.NewSocialHandler(socialService)
+	moderationHandler := handlers.NewModerationHandler(moderationService)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
@@ -89,18 +111,24 @@ func main() {
 	// Post routes
 	posts := api.Group("/posts")
 	
-	// Public routes
-	posts.Get("", socialHandler.GetFeed)
+	// Public routes (signed-in viewers don't see blocked users)
+	posts.Get("", optionalAuth, socialHandler.GetFeed)
 	posts.Get("/:id", socialHandler.GetPost)
-	posts.Get("/:id/comments", socialHandler.GetComments)
+	posts.Get("/:id/comments", optionalAuth, socialHandler.GetComments)
 
 	// Protected routes
-	posts.Use(middleware.AuthMiddleware(jwtManager))
+	posts.Use(authMiddleware)
 	posts.Post("", socialHandler.PublishPost)
 	posts.Post("/:id/like", socialHandler.LikePost)
 	posts.Delete("/:id/like", socialHandler.UnlikePost)
 	posts.Post("/:id/comments", socialHandler.AddComment)
 
+	// Moderation routes (admins and moderators)
+	admin := api.Group("/admin")
+	admin.Use(authMiddleware, middleware.RequireRole(jwt.RoleAdmin, jwt.RoleModerator))
+	admin.Delete("/posts/:id", moderationHandler.DeletePost)
+	admin.Delete("/comments/:id", moderationHandler.DeleteComment)
+
 	// Start server
 	port := cfg.SocialServicePort
 	log.Printf("🚀 Social Service starting on port %s", port)
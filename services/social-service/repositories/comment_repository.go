@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"zodiac-ai-backend/services/social-service/models"
@@ -12,6 +13,10 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+)
+
 // CommentRepository handles comment data access
 type CommentRepository struct {
 	collection *mongo.Collection
@@ -37,11 +42,16 @@ func (r *CommentRepository) Create(ctx context.Context, comment *models.Comment)
 	return nil
 }
 
-// FindByPostID finds comments by post ID
-func (r *CommentRepository) FindByPostID(ctx context.Context, postID primitive.ObjectID) ([]*models.Comment, error) {
+// FindByPostID finds comments by post ID, leaving out comments by excludeUserIDs (blocked users)
+func (r *CommentRepository) FindByPostID(ctx context.Context, postID primitive.ObjectID, excludeUserIDs []primitive.ObjectID) ([]*models.Comment, error) {
 	opts := options.Find().SetSort(bson.M{"created_at": -1})
 
-	cursor, err := r.collection.Find(ctx, bson.M{"post_id": postID}, opts)
+	filter := bson.M{"post_id": postID}
+	if len(excludeUserIDs) > 0 {
+		filter["user_id"] = bson.M{"$nin": excludeUserIDs}
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -59,3 +69,53 @@ func (r *CommentRepository) FindByPostID(ctx context.Context, postID primitive.O
 func (r *CommentRepository) CountByPostID(ctx context.Context, postID primitive.ObjectID) (int64, error) {
 	return r.collection.CountDocuments(ctx, bson.M{"post_id": postID})
 }
+
+// FindByID finds a comment by ID
+func (r *CommentRepository) FindByID(ctx context.Context, commentID primitive.ObjectID) (*models.Comment, error) {
+	var comment models.Comment
+	err := r.collection.FindOne(ctx, bson.M{"_id": commentID}).Decode(&comment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// DeleteThread deletes a comment together with all replies below it
+// Replies are collected level by level and deleted before the comment, so a retry finds them again
+func (r *CommentRepository) DeleteThread(ctx context.Context, commentID primitive.ObjectID) error {
+	thread := []primitive.ObjectID{}
+	level := []primitive.ObjectID{commentID}
+
+	for len(level) > 0 {
+		values, err := r.collection.Distinct(ctx, "_id", bson.M{"parent_id": bson.M{"$in": level}})
+		if err != nil {
+			return err
+		}
+
+		level = nil
+		for _, value := range values {
+			if id, ok := value.(primitive.ObjectID); ok {
+				level = append(level, id)
+			}
+		}
+		thread = append(thread, level...)
+	}
+
+	if len(thread) > 0 {
+		if _, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": thread}}); err != nil {
+			return err
+		}
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": commentID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
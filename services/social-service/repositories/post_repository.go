@@ -61,10 +61,15 @@ func (r *PostRepository) FindByID(ctx context.Context, postID primitive.ObjectID
 }
 
 // GetFeed gets posts with cursor-based pagination and filters
+// Posts by excludeUserIDs (blocked users) are left out
 // Reference: CLRS Ch. 12 - Cursor pagination with O(log n) complexity
-func (r *PostRepository) GetFeed(ctx context.Context, query *models.GetFeedQuery) ([]*models.Post, string, error) {
+func (r *PostRepository) GetFeed(ctx context.Context, query *models.GetFeedQuery, excludeUserIDs []primitive.ObjectID) ([]*models.Post, string, error) {
 	filter := bson.M{"status": models.StatusPublished}
 
+	if len(excludeUserIDs) > 0 {
+		filter["user_id"] = bson.M{"$nin": excludeUserIDs}
+	}
+
 	// Apply filters
 	if query.ZodiacSign != "" {
 		filter["author_zodiac"] = query.ZodiacSign
@@ -186,3 +191,33 @@ func (r *PostRepository) CheckUserLiked(ctx context.Context, postID, userID prim
 	}
 	return count > 0, nil
 }
+
+// Delete deletes a post with its likes and comments
+// The post goes last, so a failed delete can be retried without leaving orphans behind
+func (r *PostRepository) Delete(ctx context.Context, postID primitive.ObjectID) error {
+	if _, err := r.likeCollection.DeleteMany(ctx, bson.M{"post_id": postID}); err != nil {
+		return err
+	}
+	if _, err := r.collection.Database().Collection("comments").DeleteMany(ctx, bson.M{"post_id": postID}); err != nil {
+		return err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": postID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrPostNotFound
+	}
+	return nil
+}
+
+// SetCommentsCount sets post's comments count (after comments were deleted)
+func (r *PostRepository) SetCommentsCount(ctx context.Context, postID primitive.ObjectID, count int64) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": postID},
+		bson.M{"$set": bson.M{"comments_count": count}},
+	)
+	return err
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	authModels "zodiac-ai-backend/services/auth-service/models"
+	authRepos "zodiac-ai-backend/services/auth-service/repositories"
+	"zodiac-ai-backend/services/social-service/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ModerationService handles removal of posts and comments by admins and moderators
+// Removals are written to the shared audit log under the author's user ID
+type ModerationService struct {
+	postRepo    *repositories.PostRepository
+	commentRepo *repositories.CommentRepository
+	auditRepo   *authRepos.AuditLogRepository
+}
+
+// NewModerationService creates a new moderation service
+func NewModerationService(
+	postRepo *repositories.PostRepository,
+	commentRepo *repositories.CommentRepository,
+	auditRepo *authRepos.AuditLogRepository,
+) *ModerationService {
+	return &ModerationService{
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+		auditRepo:   auditRepo,
+	}
+}
+
+// DeletePost deletes a post with its likes and comments
+func (s *ModerationService) DeletePost(ctx context.Context, adminID, postID string) error {
+	postObjID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return repositories.ErrPostNotFound
+	}
+
+	post, err := s.postRepo.FindByID(ctx, postObjID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.postRepo.Delete(ctx, post.ID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, authModels.AuditPostDeleted, post.UserID, map[string]interface{}{
+		"admin_id": adminID,
+		"post_id":  post.ID.Hex(),
+		"title":    post.Title,
+	})
+	return nil
+}
+
+// DeleteComment deletes a comment with its replies
+func (s *ModerationService) DeleteComment(ctx context.Context, adminID, commentID string) error {
+	commentObjID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return repositories.ErrCommentNotFound
+	}
+
+	comment, err := s.commentRepo.FindByID(ctx, commentObjID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.commentRepo.DeleteThread(ctx, comment.ID); err != nil {
+		return err
+	}
+
+	// Recounted rather than decremented: the number of replies removed isn't known up front
+	count, err := s.commentRepo.CountByPostID(ctx, comment.PostID)
+	if err != nil {
+		return err
+	}
+	if err := s.postRepo.SetCommentsCount(ctx, comment.PostID, count); err != nil {
+		return err
+	}
+
+	s.audit(ctx, authModels.AuditCommentDeleted, comment.UserID, map[string]interface{}{
+		"admin_id":   adminID,
+		"comment_id": comment.ID.Hex(),
+		"post_id":    comment.PostID.Hex(),
+	})
+	return nil
+}
+
+// audit records a removal; the removal itself already applies, so a failed write is only logged
+func (s *ModerationService) audit(ctx context.Context, event string, authorID primitive.ObjectID, details map[string]interface{}) {
+	if err := s.auditRepo.Create(ctx, &authModels.AuditLog{
+		Event:   event,
+		UserID:  &authorID,
+		Details: details,
+	}); err != nil {
+		log.Printf("⚠️ Failed to write %s audit log: %v", event, err)
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 
+	authRepos "zodiac-ai-backend/services/auth-service/repositories"
 	"zodiac-ai-backend/services/social-service/models"
 	"zodiac-ai-backend/services/social-service/repositories"
 
@@ -13,16 +14,19 @@ import (
 type SocialService struct {
 	postRepo    *repositories.PostRepository
 	commentRepo *repositories.CommentRepository
+	blockRepo   *authRepos.BlockRepository
 }
 
 // NewSocialService creates a new social service
 func NewSocialService(
 	postRepo *repositories.PostRepository,
 	commentRepo *repositories.CommentRepository,
+	blockRepo *authRepos.BlockRepository,
 ) *SocialService {
 	return &SocialService{
 		postRepo:    postRepo,
 		commentRepo: commentRepo,
+		blockRepo:   blockRepo,
 	}
 }
 
@@ -50,13 +54,19 @@ func (s *SocialService) PublishPost(ctx context.Context, userID, zodiacSign stri
 }
 
 // GetFeed gets social feed with filters and pagination
-func (s *SocialService) GetFeed(ctx context.Context, query *models.GetFeedQuery) ([]*models.Post, string, error) {
+// viewerID is empty for anonymous requests; signed-in viewers don't see users blocked either way
+func (s *SocialService) GetFeed(ctx context.Context, viewerID string, query *models.GetFeedQuery) ([]*models.Post, string, error) {
 	// Set default limit
 	if query.Limit <= 0 || query.Limit > 50 {
 		query.Limit = 20
 	}
 
-	return s.postRepo.GetFeed(ctx, query)
+	hidden, err := s.hiddenUsers(ctx, viewerID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.postRepo.GetFeed(ctx, query, hidden)
 }
 
 // GetPost gets a single post by ID
@@ -145,13 +155,19 @@ func (s *SocialService) AddComment(ctx context.Context, postID, userID, username
 }
 
 // GetComments gets comments for a post
-func (s *SocialService) GetComments(ctx context.Context, postID string) ([]*models.Comment, error) {
+// viewerID is empty for anonymous requests; signed-in viewers don't see users blocked either way
+func (s *SocialService) GetComments(ctx context.Context, viewerID, postID string) ([]*models.Comment, error) {
 	postObjID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.commentRepo.FindByPostID(ctx, postObjID)
+	hidden, err := s.hiddenUsers(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.commentRepo.FindByPostID(ctx, postObjID, hidden)
 }
 
 // CheckUserLiked checks if user has liked a post
@@ -168,3 +184,13 @@ func (s *SocialService) CheckUserLiked(ctx context.Context, postID, userID strin
 
 	return s.postRepo.CheckUserLiked(ctx, postObjID, userObjID)
 }
+
+// hiddenUsers returns the users a viewer blocked or was blocked by (none for anonymous viewers)
+func (s *SocialService) hiddenUsers(ctx context.Context, viewerID string) ([]primitive.ObjectID, error) {
+	viewerObjID, err := primitive.ObjectIDFromHex(viewerID)
+	if err != nil {
+		return nil, nil
+	}
+
+	return s.blockRepo.HiddenFrom(ctx, viewerObjID)
+}